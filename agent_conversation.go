@@ -0,0 +1,128 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// unansweredToolCalls returns the tool calls of the last assistant message that
+// have no matching tool result after it.
+func unansweredToolCalls(items []llm.InputItem) []llm.ToolCall {
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].Role != llm.RoleAssistant {
+			continue
+		}
+		answered := make(map[ToolCallID]struct{})
+		for _, later := range items[i+1:] {
+			if later.Role == llm.RoleTool {
+				answered[later.ToolCallID] = struct{}{}
+			}
+		}
+		var pending []llm.ToolCall
+		for _, call := range items[i].ToolCalls {
+			if _, ok := answered[call.ID]; !ok {
+				pending = append(pending, call)
+			}
+		}
+		return pending
+	}
+	return nil
+}
+
+// agentConversation writes agent messages to a ConversationStore as the loop
+// progresses. A nil *agentConversation records nothing.
+type agentConversation struct {
+	store ConversationStore
+	id    string
+}
+
+func (c *agentConversation) record(ctx context.Context, items ...llm.InputItem) error {
+	if c == nil || len(items) == 0 {
+		return nil
+	}
+	if err := c.store.Append(ctx, c.id, items...); err != nil {
+		return fmt.Errorf("sdk: persist agent message: %w", err)
+	}
+	return nil
+}
+
+// agentResume is the starting state of an agent run.
+type agentResume struct {
+	conversation *agentConversation
+	input        []llm.InputItem
+	denied       []DeniedToolCall
+	toolCalls    int
+}
+
+// agentConversationStore resolves the store and conversation ID configured in
+// opts. SessionID is shorthand for the sessions-backed store.
+func (c *Client) agentConversationStore(opts AgentOptions) (ConversationStore, string) {
+	if opts.Conversation != nil {
+		return opts.Conversation, opts.ConversationID
+	}
+	if opts.SessionID != uuid.Nil {
+		return NewSessionsConversationStore(c.Sessions), opts.SessionID.String()
+	}
+	return nil, ""
+}
+
+// startAgentConversation builds the initial agent input. Without a
+// conversation it is the system and user prompt. Otherwise prior history is
+// loaded from the store, tool calls left unanswered by an interrupted run are
+// executed (subject to opts.Approval) and the new prompt, if any, is appended.
+// New messages are written back to the store.
+func (c *Client) startAgentConversation(ctx context.Context, opts AgentOptions, registry *ToolRegistry) (*agentResume, error) {
+	store, id := c.agentConversationStore(opts)
+	if store == nil {
+		return &agentResume{input: newAgentInput(opts)}, nil
+	}
+
+	history, err := store.Load(ctx, id)
+	if err != nil && !errors.Is(err, ErrConversationNotFound) {
+		return nil, err
+	}
+	history = conversationItems(history)
+
+	res := &agentResume{conversation: &agentConversation{store: store, id: id}}
+	if opts.System != "" {
+		res.input = append(res.input, llm.NewSystemText(opts.System))
+	}
+	res.input = append(res.input, history...)
+
+	if pending := unansweredToolCalls(history); len(pending) > 0 {
+		results, denied, err := executeAgentToolCalls(ctx, registry, opts.Approval, pending)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msgs, err := agentToolResultMessages(results)
+		if err != nil {
+			return nil, err
+		}
+		if err := res.conversation.record(ctx, msgs...); err != nil {
+			return nil, err
+		}
+		res.input = append(res.input, msgs...)
+		res.denied = denied
+		res.toolCalls = len(pending)
+	}
+
+	if strings.TrimSpace(opts.Prompt) != "" {
+		prompt := llm.NewUserText(opts.Prompt)
+		if err := res.conversation.record(ctx, prompt); err != nil {
+			return nil, err
+		}
+		res.input = append(res.input, prompt)
+	} else if len(history) == 0 {
+		return nil, ConfigError{Reason: "Prompt is required when the conversation has no history"}
+	}
+	return res, nil
+}
@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelrelay/modelrelay/sdk/go/generated"
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+func TestSessionMessageRoundTrip(t *testing.T) {
+	items := []llm.InputItem{
+		llm.NewUserText("list files"),
+		AssistantMessageWithToolCalls("checking", []llm.ToolCall{NewToolCall("call_1", "ls", `{"path":"."}`)}),
+		llm.NewToolResultText("call_1", "a.txt"),
+		llm.NewAssistantText("found a.txt"),
+	}
+
+	var msgs []SessionMessage
+	for i, item := range items {
+		req, err := SessionMessageFromInputItem(item)
+		if err != nil {
+			t.Fatalf("encode %d: %v", i, err)
+		}
+		// Simulate the JSON round trip through the API.
+		raw, _ := json.Marshal(req)
+		var msg SessionMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			t.Fatalf("decode %d: %v", i, err)
+		}
+		msg.Seq = int32(i + 1)
+		msgs = append(msgs, msg)
+	}
+
+	got, err := InputItemsFromSessionMessages(msgs)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if len(got) != len(items) {
+		t.Fatalf("expected %d items, got %d", len(items), len(got))
+	}
+	if got[1].Role != llm.RoleAssistant || len(got[1].ToolCalls) != 1 || historyItemText(got[1]) != "checking" {
+		t.Fatalf("unexpected assistant item %+v", got[1])
+	}
+	if GetToolName(got[1].ToolCalls[0]) != "ls" || GetToolArgsRaw(got[1].ToolCalls[0]) != `{"path":"."}` {
+		t.Fatalf("unexpected tool call %+v", got[1].ToolCalls[0])
+	}
+	if got[2].ToolCallID != "call_1" || historyItemText(got[2]) != "a.txt" {
+		t.Fatalf("unexpected tool result %+v", got[2])
+	}
+
+	if _, err := SessionMessageFromInputItem(llm.NewSystemText("sys")); err == nil {
+		t.Fatal("expected error for system message")
+	}
+}
+
+func TestAgentResumesSession(t *testing.T) {
+	stored := generated.SessionWithMessagesResponse{
+		Id:        testSessionID,
+		ProjectId: testProjectID,
+		Messages: []generated.SessionMessageResponse{
+			{Seq: 1, Role: "user", Content: []map[string]any{{"type": "text", "text": "list files"}}},
+			{Seq: 2, Role: "assistant", Content: []map[string]any{
+				{"type": "tool_call", "id": "call_1", "name": "ls", "arguments": "{}"},
+			}},
+		},
+	}
+
+	var mu sync.Mutex
+	var added []SessionMessageCreateRequest
+	var responseInput []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/sessions/"+testSessionID.String():
+			_ = json.NewEncoder(w).Encode(stored)
+		case r.Method == http.MethodPost && r.URL.Path == "/sessions/"+testSessionID.String()+"/messages":
+			var req SessionMessageCreateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			added = append(added, req)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(testSessionMessage())
+		case r.Method == http.MethodPost && r.URL.Path == "/responses":
+			var payload map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			responseInput, _ = payload["input"].([]any)
+			_, _ = w.Write([]byte(`{"id":"resp_1","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"a.txt"}]}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	executed := 0
+	tools := NewToolBuilder().Add("ls", "List files", nil, func(args map[string]any, call llm.ToolCall) (any, error) {
+		executed++
+		return "a.txt", nil
+	})
+
+	result, err := client.Agent(context.Background(), "demo", AgentOptions{Tools: tools, SessionID: testSessionID})
+	if err != nil {
+		t.Fatalf("agent: %v", err)
+	}
+	if executed != 1 {
+		t.Fatalf("expected pending tool call to run once, ran %d", executed)
+	}
+	if result.Output != "a.txt" || result.Usage.ToolCalls != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// The model sees the stored user message, the tool call and its result.
+	if len(responseInput) != 3 {
+		t.Fatalf("expected 3 input items, got %d", len(responseInput))
+	}
+	raw, _ := json.Marshal(responseInput[2])
+	if !strings.Contains(string(raw), `"tool_call_id":"call_1"`) {
+		t.Fatalf("expected tool result for call_1, got %s", raw)
+	}
+
+	// The tool result and the final answer are written back.
+	if len(added) != 2 || added[0].Role != "tool" || added[1].Role != "assistant" {
+		t.Fatalf("unexpected persisted messages %+v", added)
+	}
+	if added[0].Content[0]["type"] != "tool_result" || added[0].Content[0]["tool_call_id"] != "call_1" {
+		t.Fatalf("unexpected tool message content %+v", added[0].Content)
+	}
+}
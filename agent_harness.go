@@ -0,0 +1,481 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/modelrelay/modelrelay/sdk/go/headers"
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+)
+
+// HarnessT is the subset of testing.TB used by AgentHarness.
+type HarnessT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// TurnMatcher checks the request for an expected turn and returns an error
+// describing the mismatch, or nil when the request matches.
+type TurnMatcher func(ResponseRequest) error
+
+// AgentHarness scripts the model side of a tool loop such as Client.Agent or
+// Client.SQLToolLoop. Tests declare the turns they expect, each with matchers
+// for the incoming ResponseRequest and the reply the model should give:
+//
+//	h := sdk.NewAgentHarness(t)
+//	h.Expect(sdk.OffersTools("read_file")).CallsTool("read_file", map[string]any{"path": "go.mod"})
+//	h.Expect(sdk.LastToolResultContains("module")).Answers("It is a Go module.")
+//	result, err := h.Client().Agent(ctx, "demo", opts)
+//	h.AssertDone()
+//
+// When a request does not match the next expected turn, the harness reports
+// the failure together with the conversation transcript and fails the call
+// with a 400 APIError. AgentHarness is safe for concurrent use.
+type AgentHarness struct {
+	t HarnessT
+
+	mu         sync.Mutex
+	turns      []*ExpectedTurn
+	anyOrder   bool
+	fallback   http.RoundTripper
+	requests   []ResponseRequest
+	transcript []string
+	callSeq    int
+}
+
+// ExpectedTurn is one scripted model turn. Configure the reply with
+// CallsTool, Answers or Fails.
+type ExpectedTurn struct {
+	index     int
+	matchers  []TurnMatcher
+	text      string
+	toolCalls []llm.ToolCall
+	err       *APIError
+	used      bool
+}
+
+// NewAgentHarness creates an empty harness that reports failures to t.
+func NewAgentHarness(t HarnessT) *AgentHarness {
+	return &AgentHarness{t: t}
+}
+
+// Expect appends a turn that matches requests satisfying all matchers.
+func (h *AgentHarness) Expect(matchers ...TurnMatcher) *ExpectedTurn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	turn := &ExpectedTurn{index: len(h.turns) + 1, matchers: matchers}
+	h.turns = append(h.turns, turn)
+	return turn
+}
+
+// InAnyOrder lets each request match any unused turn instead of the next one,
+// for agents that run concurrently against the same harness.
+func (h *AgentHarness) InAnyOrder() *AgentHarness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.anyOrder = true
+	return h
+}
+
+// WithFallback routes requests other than /responses (for example
+// /sql/validate from SQLToolLoop) to rt. Without a fallback they fail with 404.
+func (h *AgentHarness) WithFallback(rt http.RoundTripper) *AgentHarness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = rt
+	return h
+}
+
+// CallsTool adds a tool call to the turn's reply. args is encoded as JSON
+// unless it is already a string.
+func (e *ExpectedTurn) CallsTool(name ToolName, args any) *ExpectedTurn {
+	raw, ok := args.(string)
+	if !ok {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			panic(fmt.Sprintf("agent harness: encode %s args: %v", name, err))
+		}
+		raw = string(encoded)
+	}
+	id := ToolCallID("call_" + strconv.Itoa(e.index) + "_" + strconv.Itoa(len(e.toolCalls)+1))
+	e.toolCalls = append(e.toolCalls, llm.NewToolCall(id, name, raw))
+	return e
+}
+
+// Answers sets the assistant text of the turn's reply.
+func (e *ExpectedTurn) Answers(text string) *ExpectedTurn {
+	e.text = text
+	return e
+}
+
+// Fails makes the turn return an API error instead of a response.
+func (e *ExpectedTurn) Fails(status int, code APIErrorCode, message string) *ExpectedTurn {
+	e.err = &APIError{Status: status, Code: code, Message: message}
+	return e
+}
+
+// Client returns a Client whose /responses calls are answered by the harness.
+// Retries are disabled; opts are applied after the harness defaults.
+func (h *AgentHarness) Client(opts ...Option) *Client {
+	h.t.Helper()
+	key, err := ParseSecretKey("mr_sk_agent_harness")
+	if err != nil {
+		h.t.Errorf("agent harness: %v", err)
+		return nil
+	}
+	defaults := []Option{
+		WithBaseURL("http://agent-harness.invalid"),
+		WithHTTPClient(&http.Client{Transport: h}),
+		WithRetryConfig(RetryConfig{MaxAttempts: 1}),
+	}
+	client, err := NewClientWithKey(key, append(defaults, opts...)...)
+	if err != nil {
+		h.t.Errorf("agent harness: new client: %v", err)
+		return nil
+	}
+	return client
+}
+
+// Requests returns every request the harness received, in order.
+func (h *AgentHarness) Requests() []ResponseRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]ResponseRequest(nil), h.requests...)
+}
+
+// Transcript renders the conversation seen so far.
+func (h *AgentHarness) Transcript() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return strings.Join(h.transcript, "\n")
+}
+
+// AssertDone reports every expected turn that was never requested.
+func (h *AgentHarness) AssertDone() {
+	h.t.Helper()
+	h.mu.Lock()
+	var missing []string
+	for _, turn := range h.turns {
+		if !turn.used {
+			missing = append(missing, "turn "+strconv.Itoa(turn.index)+" ("+turn.describeReply()+")")
+		}
+	}
+	transcript := strings.Join(h.transcript, "\n")
+	h.mu.Unlock()
+	if len(missing) > 0 {
+		h.t.Errorf("agent harness: expected turns not reached: %s\n\ntranscript:\n%s", strings.Join(missing, ", "), transcript)
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (h *AgentHarness) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || req.URL.Path != routes.Responses {
+		h.mu.Lock()
+		fallback := h.fallback
+		h.mu.Unlock()
+		if fallback != nil {
+			return fallback.RoundTrip(req)
+		}
+		return harnessError(req, &APIError{Status: http.StatusNotFound, Code: ErrCodeNotFound, Message: "agent harness: no handler for " + req.Method + " " + req.URL.Path}), nil
+	}
+
+	var payload responseRequestPayload
+	if req.Body != nil {
+		defer func() { _ = req.Body.Close() }()
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, fmt.Errorf("agent harness: decode request: %w", err)
+		}
+	}
+	rr := payload.request()
+
+	h.mu.Lock()
+	h.callSeq++
+	call := h.callSeq
+	var prev []llm.InputItem
+	if !h.anyOrder && len(h.requests) > 0 {
+		prev = h.requests[len(h.requests)-1].input
+	}
+	h.requests = append(h.requests, rr)
+	h.transcript = append(h.transcript, describeHarnessRequest(call, rr, prev))
+	turn, mismatch := h.matchLocked(rr)
+	if turn != nil {
+		turn.used = true
+		h.transcript = append(h.transcript, "  -> "+turn.describeReply())
+	} else {
+		h.transcript = append(h.transcript, "  -> no match: "+mismatch)
+	}
+	transcript := strings.Join(h.transcript, "\n")
+	h.mu.Unlock()
+
+	if turn == nil {
+		h.t.Errorf("agent harness: request %d did not match: %s\n\ntranscript:\n%s", call, mismatch, transcript)
+		return harnessError(req, &APIError{Status: http.StatusBadRequest, Code: "AGENT_HARNESS_MISMATCH", Message: mismatch}), nil
+	}
+	if turn.err != nil {
+		return harnessError(req, turn.err), nil
+	}
+	return turn.response(req, rr, call)
+}
+
+// matchLocked finds the turn for rr. Callers hold h.mu.
+func (h *AgentHarness) matchLocked(rr ResponseRequest) (*ExpectedTurn, string) {
+	var reasons []string
+	for _, turn := range h.turns {
+		if turn.used {
+			continue
+		}
+		err := turn.match(rr)
+		if err == nil {
+			return turn, ""
+		}
+		reasons = append(reasons, "turn "+strconv.Itoa(turn.index)+": "+err.Error())
+		if !h.anyOrder {
+			break
+		}
+	}
+	if len(reasons) == 0 {
+		return nil, "no expected turns left"
+	}
+	return nil, strings.Join(reasons, "; ")
+}
+
+func (e *ExpectedTurn) match(rr ResponseRequest) error {
+	for _, m := range e.matchers {
+		if m == nil {
+			continue
+		}
+		if err := m(rr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ExpectedTurn) response(req *http.Request, rr ResponseRequest, call int) (*http.Response, error) {
+	item := llm.OutputItem{Type: llm.OutputItemTypeMessage, Role: llm.RoleAssistant, ToolCalls: e.toolCalls}
+	if e.text != "" {
+		item.Content = []llm.ContentPart{llm.TextPart(e.text)}
+	}
+	stop := StopReasonEndTurn
+	if len(e.toolCalls) > 0 {
+		stop = StopReasonToolCalls
+	}
+	model := rr.model
+	if model.IsEmpty() {
+		model = NewModelID("agent-harness")
+	}
+	body, err := json.Marshal(Response{
+		ID:         "resp_harness_" + strconv.Itoa(call),
+		Output:     []llm.OutputItem{item},
+		StopReason: stop,
+		Model:      model,
+		Usage:      Usage{InputTokens: 1, OutputTokens: 1, TotalTokens: 2},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return harnessResponse(req, http.StatusOK, body), nil
+}
+
+func (e *ExpectedTurn) describeReply() string {
+	var parts []string
+	for _, tc := range e.toolCalls {
+		parts = append(parts, "tool_call "+describeToolCall(tc))
+	}
+	if e.text != "" {
+		parts = append(parts, "answer "+strconv.Quote(truncateHarnessText(e.text)))
+	}
+	if e.err != nil {
+		parts = append(parts, "error "+e.err.Error())
+	}
+	if len(parts) == 0 {
+		return "empty reply"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p responseRequestPayload) request() ResponseRequest {
+	return ResponseRequest{
+		provider:        NewProviderID(p.Provider),
+		model:           NewModelID(p.Model),
+		stateID:         p.StateID,
+		input:           p.Input,
+		outputFormat:    p.OutputFormat,
+		maxOutputTokens: p.MaxOutputTokens,
+		temperature:     p.Temperature,
+		stop:            p.Stop,
+		tools:           p.Tools,
+		toolChoice:      p.ToolChoice,
+	}
+}
+
+func harnessResponse(req *http.Request, status int, body []byte) *http.Response {
+	hdr := make(http.Header)
+	hdr.Set("Content-Type", "application/json")
+	if id := req.Header.Get(headers.RequestID); id != "" {
+		hdr.Set(headers.RequestID, id)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        hdr,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func harnessError(req *http.Request, apiErr *APIError) *http.Response {
+	body, _ := json.Marshal(map[string]string{
+		"error":   http.StatusText(apiErr.Status),
+		"code":    string(apiErr.Code),
+		"message": apiErr.Message,
+	})
+	return harnessResponse(req, apiErr.Status, body)
+}
+
+// Matchers.
+
+// SystemPromptContains matches requests whose system message contains substr.
+func SystemPromptContains(substr string) TurnMatcher {
+	return func(rr ResponseRequest) error {
+		for _, item := range rr.input {
+			if item.Role == llm.RoleSystem && strings.Contains(harnessItemText(item), substr) {
+				return nil
+			}
+		}
+		return fmt.Errorf("system prompt does not contain %q", substr)
+	}
+}
+
+// LastUserMessageContains matches requests whose last user message contains substr.
+func LastUserMessageContains(substr string) TurnMatcher {
+	return func(rr ResponseRequest) error {
+		for i := len(rr.input) - 1; i >= 0; i-- {
+			if rr.input[i].Role != llm.RoleUser {
+				continue
+			}
+			if text := harnessItemText(rr.input[i]); !strings.Contains(text, substr) {
+				return fmt.Errorf("last user message %q does not contain %q", truncateHarnessText(text), substr)
+			}
+			return nil
+		}
+		return fmt.Errorf("no user message (want one containing %q)", substr)
+	}
+}
+
+// LastToolResultContains matches requests whose most recent tool result
+// contains substr.
+func LastToolResultContains(substr string) TurnMatcher {
+	return func(rr ResponseRequest) error {
+		for i := len(rr.input) - 1; i >= 0; i-- {
+			if rr.input[i].Role != llm.RoleTool {
+				continue
+			}
+			if text := harnessItemText(rr.input[i]); !strings.Contains(text, substr) {
+				return fmt.Errorf("last tool result %q does not contain %q", truncateHarnessText(text), substr)
+			}
+			return nil
+		}
+		return fmt.Errorf("no tool result (want one containing %q)", substr)
+	}
+}
+
+// OffersTools matches requests that offer every named tool.
+func OffersTools(names ...ToolName) TurnMatcher {
+	return func(rr ResponseRequest) error {
+		offered := make(map[ToolName]bool, len(rr.tools))
+		for _, tool := range rr.tools {
+			if tool.Function != nil {
+				offered[tool.Function.Name] = true
+			}
+		}
+		for _, name := range names {
+			if !offered[name] {
+				return fmt.Errorf("tool %s not offered (offered: %s)", name, strings.Join(harnessToolNames(rr.tools), ", "))
+			}
+		}
+		return nil
+	}
+}
+
+// Transcript rendering.
+
+// describeHarnessRequest renders the input items added since prev, which is
+// the previous request's input when the conversation is sequential.
+func describeHarnessRequest(call int, rr ResponseRequest, prev []llm.InputItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "request %d", call)
+	if names := harnessToolNames(rr.tools); len(names) > 0 {
+		fmt.Fprintf(&b, " (tools: %s)", strings.Join(names, ", "))
+	}
+	b.WriteString(":")
+	start := 0
+	if len(prev) > 0 && len(prev) < len(rr.input) {
+		start = len(prev)
+		fmt.Fprintf(&b, "\n  ... %d earlier items", start)
+	}
+	for _, item := range rr.input[start:] {
+		b.WriteString("\n  ")
+		switch {
+		case item.Role == llm.RoleTool:
+			fmt.Fprintf(&b, "tool[%s]: %s", item.ToolCallID, truncateHarnessText(harnessItemText(item)))
+		case len(item.ToolCalls) > 0:
+			calls := make([]string, 0, len(item.ToolCalls))
+			for _, tc := range item.ToolCalls {
+				calls = append(calls, describeToolCall(tc))
+			}
+			fmt.Fprintf(&b, "%s: %s", item.Role, strings.Join(calls, ", "))
+		default:
+			fmt.Fprintf(&b, "%s: %s", item.Role, truncateHarnessText(harnessItemText(item)))
+		}
+	}
+	return b.String()
+}
+
+func describeToolCall(tc llm.ToolCall) string {
+	if tc.Function == nil {
+		return string(tc.ID)
+	}
+	return string(tc.Function.Name) + "(" + truncateHarnessText(tc.Function.Arguments) + ")"
+}
+
+func harnessToolNames(tools []llm.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Function != nil {
+			names = append(names, string(tool.Function.Name))
+		} else {
+			names = append(names, string(tool.Type))
+		}
+	}
+	return names
+}
+
+func harnessItemText(item llm.InputItem) string {
+	var b strings.Builder
+	for _, part := range item.Content {
+		if part.Type == llm.ContentPartTypeText {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
+
+func truncateHarnessText(s string) string {
+	const max = 200
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "…"
+}
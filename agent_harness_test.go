@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingHarnessT struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recordingHarnessT) Helper() {}
+
+func (r *recordingHarnessT) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func harnessReadFileTools() *ToolBuilder {
+	type readArgs struct {
+		Path string `json:"path"`
+	}
+	tools := NewToolBuilder()
+	AddFunc(tools, "read_file", "Read a file", func(args readArgs) (any, error) {
+		return "contents of " + args.Path, nil
+	})
+	return tools
+}
+
+func TestAgentHarnessScriptedTurns(t *testing.T) {
+	h := NewAgentHarness(t)
+	h.Expect(SystemPromptContains("careful"), LastUserMessageContains("summarize"), OffersTools("read_file")).
+		CallsTool("read_file", map[string]any{"path": "a.txt"}).
+		CallsTool("read_file", map[string]any{"path": "b.txt"})
+	h.Expect(LastToolResultContains("b.txt")).Answers("both files read")
+
+	result, err := h.Client().Agent(context.Background(), "demo", AgentOptions{
+		Tools:              harnessReadFileTools(),
+		System:             "Be careful.",
+		Prompt:             "Read a.txt and b.txt and summarize",
+		MaxToolConcurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("agent: %v", err)
+	}
+	if result.Output != "both files read" || result.Usage.ToolCalls != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	h.AssertDone()
+	if transcript := h.Transcript(); !strings.Contains(transcript, `read_file({"path":"a.txt"})`) {
+		t.Fatalf("transcript missing tool call:\n%s", transcript)
+	}
+}
+
+func TestAgentHarnessReportsDivergence(t *testing.T) {
+	rec := &recordingHarnessT{}
+	h := NewAgentHarness(rec)
+	h.Expect().CallsTool("read_file", map[string]any{"path": "a.txt"})
+	h.Expect(LastToolResultContains("never")).Answers("unreachable")
+	h.Expect().Answers("also unreachable")
+
+	_, err := h.Client().Agent(context.Background(), "demo", AgentOptions{
+		Tools:  harnessReadFileTools(),
+		Prompt: "go",
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not contain") {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	h.AssertDone()
+	if len(rec.errors) != 2 {
+		t.Fatalf("expected mismatch and AssertDone failures, got %d: %v", len(rec.errors), rec.errors)
+	}
+	if !strings.Contains(rec.errors[0], "request 2 did not match") || !strings.Contains(rec.errors[0], "tool[call_1_1]: contents of a.txt") {
+		t.Fatalf("expected transcript in failure:\n%s", rec.errors[0])
+	}
+	if !strings.Contains(rec.errors[1], "turn 2") || !strings.Contains(rec.errors[1], "turn 3") {
+		t.Fatalf("expected unreached turns to be listed:\n%s", rec.errors[1])
+	}
+}
+
+func TestAgentHarnessConcurrentAgents(t *testing.T) {
+	h := NewAgentHarness(t).InAnyOrder()
+	for i := 0; i < 4; i++ {
+		h.Expect(LastUserMessageContains(fmt.Sprintf("task %d", i))).Answers(fmt.Sprintf("done %d", i))
+	}
+	client := h.Client()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := client.Agent(context.Background(), "demo", AgentOptions{
+				Tools:  harnessReadFileTools(),
+				Prompt: fmt.Sprintf("task %d", i),
+			})
+			if err == nil && result.Output != fmt.Sprintf("done %d", i) {
+				err = fmt.Errorf("agent %d got %q", i, result.Output)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.AssertDone()
+	if len(h.Requests()) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(h.Requests()))
+	}
+}
@@ -0,0 +1,314 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// AgentHistoryState describes the conversation when an AgentHistoryStrategy runs.
+type AgentHistoryState struct {
+	// Turn is the zero-based index of the LLM call about to be made.
+	Turn int
+	// Usage is the accumulated usage so far.
+	Usage AgentUsage
+	// LastUsage is the usage reported for the previous LLM call.
+	LastUsage Usage
+	// LastInputChars is the number of text characters sent in the previous call's input.
+	// Together with LastUsage.InputTokens it gives a tokens-per-character estimate.
+	LastInputChars int
+}
+
+// AgentHistoryStrategy compacts the conversation history between agent turns.
+//
+// Strategies receive the full input (system prompt, initial user prompt and all
+// later assistant/tool items) and return the input to send on the next turn.
+// They must keep assistant tool calls paired with their tool results; the
+// built-in strategies operate on whole turns to guarantee that.
+type AgentHistoryStrategy interface {
+	Compact(ctx context.Context, input []llm.InputItem, state AgentHistoryState) ([]llm.InputItem, error)
+}
+
+// AgentHistoryFunc adapts a function to AgentHistoryStrategy.
+type AgentHistoryFunc func(ctx context.Context, input []llm.InputItem, state AgentHistoryState) ([]llm.InputItem, error)
+
+// Compact implements AgentHistoryStrategy.
+func (f AgentHistoryFunc) Compact(ctx context.Context, input []llm.InputItem, state AgentHistoryState) ([]llm.InputItem, error) {
+	return f(ctx, input, state)
+}
+
+// ChainHistory applies strategies in order.
+func ChainHistory(strategies ...AgentHistoryStrategy) AgentHistoryStrategy {
+	return AgentHistoryFunc(func(ctx context.Context, input []llm.InputItem, state AgentHistoryState) ([]llm.InputItem, error) {
+		var err error
+		for _, s := range strategies {
+			if s == nil {
+				continue
+			}
+			input, err = s.Compact(ctx, input, state)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return input, nil
+	})
+}
+
+// SlidingWindowHistory keeps the system prompt, the initial user prompt and the
+// most recent maxTurns turns. A turn is a message together with any tool
+// results that answer it. The latest turn is always kept; values below 1 are
+// raised to 1.
+func SlidingWindowHistory(maxTurns int) AgentHistoryStrategy {
+	if maxTurns < 1 {
+		maxTurns = 1
+	}
+	return AgentHistoryFunc(func(_ context.Context, input []llm.InputItem, _ AgentHistoryState) ([]llm.InputItem, error) {
+		pinned, turns := splitHistoryTurns(input)
+		if len(turns) <= maxTurns {
+			return input, nil
+		}
+		return joinHistoryTurns(pinned, turns[len(turns)-maxTurns:]), nil
+	})
+}
+
+// TrimToolResultsHistory shortens tool results outside the most recent
+// keepRecent turns to at most maxChars characters. With maxChars <= 0 the old
+// results are replaced by a short placeholder.
+func TrimToolResultsHistory(keepRecent int, maxChars int) AgentHistoryStrategy {
+	if keepRecent < 0 {
+		keepRecent = 0
+	}
+	return AgentHistoryFunc(func(_ context.Context, input []llm.InputItem, _ AgentHistoryState) ([]llm.InputItem, error) {
+		pinned, turns := splitHistoryTurns(input)
+		if len(turns) <= keepRecent {
+			return input, nil
+		}
+		old := turns[:len(turns)-keepRecent]
+		for i := range old {
+			old[i] = trimHistoryToolResults(old[i], maxChars)
+		}
+		return joinHistoryTurns(pinned, turns), nil
+	})
+}
+
+// SummarizeHistoryOptions configures SummarizeHistory.
+type SummarizeHistoryOptions struct {
+	// Model used to write the summary. Required.
+	Model ModelID
+	// KeepRecent is the number of most recent turns kept verbatim. Default 4.
+	KeepRecent int
+	// Trigger summarizes only once more than this many turns exist. Default
+	// 2*KeepRecent; values below KeepRecent are raised to it.
+	Trigger int
+	// Instructions overrides the default summarization system prompt.
+	Instructions string
+}
+
+const defaultSummarizeHistoryInstructions = "Summarize the earlier part of this agent conversation. " +
+	"Preserve facts, decisions, file names, identifiers and results of tool calls that later steps may rely on. " +
+	"Be concise."
+
+// SummarizeHistory replaces older turns with an LLM-written summary.
+//
+// The summary is inserted as a user message after the initial prompt, so later
+// compactions fold it into the next summary. Summarization calls are made with
+// the provided client and are not counted in AgentResult.Usage.
+func SummarizeHistory(client *Client, opts SummarizeHistoryOptions) AgentHistoryStrategy {
+	keep := opts.KeepRecent
+	if keep <= 0 {
+		keep = 4
+	}
+	trigger := opts.Trigger
+	if trigger <= 0 {
+		trigger = 2 * keep
+	}
+	trigger = max(trigger, keep)
+	instructions := strings.TrimSpace(opts.Instructions)
+	if instructions == "" {
+		instructions = defaultSummarizeHistoryInstructions
+	}
+	return AgentHistoryFunc(func(ctx context.Context, input []llm.InputItem, _ AgentHistoryState) ([]llm.InputItem, error) {
+		if client == nil {
+			return nil, ConfigError{Reason: "client is required for SummarizeHistory"}
+		}
+		if opts.Model.IsEmpty() {
+			return nil, ConfigError{Reason: "model is required for SummarizeHistory"}
+		}
+		pinned, turns := splitHistoryTurns(input)
+		if len(turns) <= trigger {
+			return input, nil
+		}
+		old := turns[:len(turns)-keep]
+
+		var transcript strings.Builder
+		for _, turn := range old {
+			for _, item := range turn {
+				writeHistoryTranscript(&transcript, item)
+			}
+		}
+
+		req, callOpts, err := client.Responses.New().
+			Model(opts.Model).
+			System(instructions).
+			User(transcript.String()).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Responses.Create(ctx, req, callOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("summarize history: %w", err)
+		}
+		summary := strings.TrimSpace(resp.AssistantText())
+		if summary == "" {
+			return input, nil
+		}
+
+		recent := append([][]llm.InputItem{{llm.NewUserText("Summary of the earlier conversation:\n" + summary)}}, turns[len(turns)-keep:]...)
+		return joinHistoryTurns(pinned, recent), nil
+	})
+}
+
+// TokenBudgetHistory keeps the estimated input size under maxInputTokens.
+//
+// The estimate uses the previous call's reported input tokens and input size.
+// When over budget, fallback is applied if set; otherwise (or if still over
+// budget) the oldest turns are dropped.
+func TokenBudgetHistory(maxInputTokens int64, fallback AgentHistoryStrategy) AgentHistoryStrategy {
+	return AgentHistoryFunc(func(ctx context.Context, input []llm.InputItem, state AgentHistoryState) ([]llm.InputItem, error) {
+		if maxInputTokens <= 0 || state.LastUsage.InputTokens <= 0 || state.LastInputChars <= 0 {
+			return input, nil
+		}
+		tokensPerChar := float64(state.LastUsage.InputTokens) / float64(state.LastInputChars)
+		estimate := func(items []llm.InputItem) int64 {
+			return int64(float64(historyChars(items)) * tokensPerChar)
+		}
+		if estimate(input) <= maxInputTokens {
+			return input, nil
+		}
+
+		if fallback != nil {
+			var err error
+			input, err = fallback.Compact(ctx, input, state)
+			if err != nil {
+				return nil, err
+			}
+			if estimate(input) <= maxInputTokens {
+				return input, nil
+			}
+		}
+
+		pinned, turns := splitHistoryTurns(input)
+		// Always keep the latest turn so the model sees the most recent tool results.
+		for len(turns) > 1 && estimate(joinHistoryTurns(pinned, turns)) > maxInputTokens {
+			turns = turns[1:]
+		}
+		return joinHistoryTurns(pinned, turns), nil
+	})
+}
+
+// applyAgentHistory runs strategy (if any) and returns the compacted input.
+func applyAgentHistory(ctx context.Context, strategy AgentHistoryStrategy, input []llm.InputItem, state AgentHistoryState) ([]llm.InputItem, error) {
+	if strategy == nil || state.Turn == 0 {
+		return input, nil
+	}
+	out, err := strategy.Compact(ctx, input, state)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// splitHistoryTurns separates the pinned prefix (leading system messages and
+// the first user message) from the remaining turns. Tool results are grouped
+// with the assistant message that requested them.
+func splitHistoryTurns(input []llm.InputItem) (pinned []llm.InputItem, turns [][]llm.InputItem) {
+	i := 0
+	for i < len(input) && input[i].Role == llm.RoleSystem {
+		i++
+	}
+	if i < len(input) && input[i].Role == llm.RoleUser {
+		i++
+	}
+	pinned = append([]llm.InputItem(nil), input[:i]...)
+
+	for _, item := range input[i:] {
+		if item.Role == llm.RoleTool && len(turns) > 0 {
+			turns[len(turns)-1] = append(turns[len(turns)-1], item)
+			continue
+		}
+		turns = append(turns, []llm.InputItem{item})
+	}
+	return pinned, turns
+}
+
+func joinHistoryTurns(pinned []llm.InputItem, turns [][]llm.InputItem) []llm.InputItem {
+	out := append([]llm.InputItem(nil), pinned...)
+	for _, turn := range turns {
+		out = append(out, turn...)
+	}
+	return out
+}
+
+func trimHistoryToolResults(turn []llm.InputItem, maxChars int) []llm.InputItem {
+	out := make([]llm.InputItem, len(turn))
+	copy(out, turn)
+	for i, item := range out {
+		if item.Role != llm.RoleTool {
+			continue
+		}
+		text := historyItemText(item)
+		if maxChars > 0 && len(text) <= maxChars {
+			continue
+		}
+		var replacement string
+		if maxChars > 0 {
+			cut := maxChars
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			replacement = text[:cut] + fmt.Sprintf("\n[truncated %d characters]", len(text)-cut)
+		} else {
+			replacement = fmt.Sprintf("[tool result omitted: %d characters]", len(text))
+		}
+		out[i] = llm.NewToolResultText(item.ToolCallID, replacement)
+	}
+	return out
+}
+
+func historyItemText(item llm.InputItem) string {
+	var b strings.Builder
+	for _, part := range item.Content {
+		b.WriteString(part.Text)
+	}
+	return b.String()
+}
+
+// historyChars counts the characters of text content and tool call arguments.
+func historyChars(items []llm.InputItem) int {
+	n := 0
+	for _, item := range items {
+		n += len(historyItemText(item))
+		for _, call := range item.ToolCalls {
+			n += len(GetToolName(call)) + len(GetToolArgsRaw(call))
+		}
+	}
+	return n
+}
+
+func writeHistoryTranscript(b *strings.Builder, item llm.InputItem) {
+	role := string(item.Role)
+	if text := historyItemText(item); text != "" {
+		if item.Role == llm.RoleTool {
+			fmt.Fprintf(b, "[tool result %s]: %s\n", item.ToolCallID, text)
+		} else {
+			fmt.Fprintf(b, "[%s]: %s\n", role, text)
+		}
+	}
+	for _, call := range item.ToolCalls {
+		fmt.Fprintf(b, "[%s tool call %s]: %s(%s)\n", role, call.ID, GetToolName(call), GetToolArgsRaw(call))
+	}
+}
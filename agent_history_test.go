@@ -0,0 +1,181 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// historyFixture builds a system prompt, user prompt and n tool-calling turns.
+func historyFixture(n int, result string) []llm.InputItem {
+	input := []llm.InputItem{llm.NewSystemText("sys"), llm.NewUserText("task")}
+	for i := 0; i < n; i++ {
+		id := ToolCallID("call_" + string(rune('a'+i)))
+		input = append(input,
+			AssistantMessageWithToolCalls("", []llm.ToolCall{NewToolCall(id, "read", `{}`)}),
+			llm.NewToolResultText(id, result),
+		)
+	}
+	return input
+}
+
+func TestSlidingWindowHistory(t *testing.T) {
+	input := historyFixture(5, "ok")
+	out, err := SlidingWindowHistory(2).Compact(context.Background(), input, AgentHistoryState{Turn: 5})
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if len(out) != 6 {
+		t.Fatalf("expected pinned + 2 turns (6 items), got %d", len(out))
+	}
+	if out[0].Role != llm.RoleSystem || out[1].Role != llm.RoleUser {
+		t.Fatalf("expected system and user prompt to be kept, got %+v", out[:2])
+	}
+	if out[2].ToolCalls[0].ID != "call_d" || out[3].ToolCallID != "call_d" {
+		t.Fatalf("expected tool call and result to stay paired, got %+v", out[2:4])
+	}
+
+	for _, maxTurns := range []int{0, -1} {
+		out, err := SlidingWindowHistory(maxTurns).Compact(context.Background(), input, AgentHistoryState{Turn: 5})
+		if err != nil {
+			t.Fatalf("compact: %v", err)
+		}
+		if len(out) != 4 || out[3].ToolCallID != "call_e" {
+			t.Fatalf("maxTurns=%d: expected pinned + latest turn, got %+v", maxTurns, out)
+		}
+	}
+}
+
+func TestTrimToolResultsHistory(t *testing.T) {
+	input := historyFixture(3, strings.Repeat("x", 100))
+	out, err := TrimToolResultsHistory(1, 10).Compact(context.Background(), input, AgentHistoryState{Turn: 3})
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if len(out) != len(input) {
+		t.Fatalf("expected same number of items, got %d", len(out))
+	}
+	if got := historyItemText(out[3]); !strings.HasPrefix(got, strings.Repeat("x", 10)+"\n[truncated 90") {
+		t.Fatalf("expected old result truncated, got %q", got)
+	}
+	if got := historyItemText(out[7]); len(got) != 100 {
+		t.Fatalf("expected recent result untouched, got %d chars", len(got))
+	}
+	if historyItemText(input[3]) != strings.Repeat("x", 100) {
+		t.Fatal("expected input not to be modified")
+	}
+
+	out, err = TrimToolResultsHistory(1, 11).Compact(context.Background(), historyFixture(2, strings.Repeat("é", 20)), AgentHistoryState{Turn: 2})
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if got := historyItemText(out[3]); !utf8.ValidString(got) || !strings.HasPrefix(got, strings.Repeat("é", 5)+"\n[truncated 30") {
+		t.Fatalf("expected truncation on a rune boundary, got %q", got)
+	}
+}
+
+func TestTokenBudgetHistory(t *testing.T) {
+	input := historyFixture(4, strings.Repeat("x", 100))
+	state := AgentHistoryState{Turn: 4, LastUsage: Usage{InputTokens: 100}, LastInputChars: 400}
+	out, err := TokenBudgetHistory(60, nil).Compact(context.Background(), input, state)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if got := int64(float64(historyChars(out)) * 0.25); got > 60 {
+		t.Fatalf("expected estimate within budget, got %d", got)
+	}
+	if out[len(out)-1].ToolCallID != "call_d" {
+		t.Fatalf("expected latest turn kept, got %+v", out[len(out)-1])
+	}
+
+	under, err := TokenBudgetHistory(1000, nil).Compact(context.Background(), input, state)
+	if err != nil || len(under) != len(input) {
+		t.Fatalf("expected input unchanged under budget, got %d items err=%v", len(under), err)
+	}
+}
+
+func TestSummarizeHistory(t *testing.T) {
+	srv, requests := newAgentJSONServer(t, []string{
+		`{"id":"resp_sum","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"read three files"}]}]}`,
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	strategy := SummarizeHistory(client, SummarizeHistoryOptions{Model: NewModelID("demo"), KeepRecent: 1, Trigger: 2})
+	input := historyFixture(3, "contents")
+	out, err := strategy.Compact(context.Background(), input, AgentHistoryState{Turn: 3})
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("expected 1 summarize request, got %d", len(*requests))
+	}
+	if len(out) != 5 {
+		t.Fatalf("expected pinned + summary + 1 turn (5 items), got %d", len(out))
+	}
+	if got := historyItemText(out[2]); !strings.Contains(got, "read three files") {
+		t.Fatalf("expected summary message, got %q", got)
+	}
+	if out[4].ToolCallID != "call_c" {
+		t.Fatalf("expected most recent turn kept, got %+v", out[4])
+	}
+}
+
+func TestSummarizeHistoryTriggerBelowKeepRecent(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	client := newTestClient(t, srv, "mr_sk_test")
+	strategy := SummarizeHistory(client, SummarizeHistoryOptions{Model: NewModelID("demo"), KeepRecent: 4, Trigger: 2})
+	input := historyFixture(3, "contents")
+	out, err := strategy.Compact(context.Background(), input, AgentHistoryState{Turn: 3})
+	if err != nil || len(out) != len(input) {
+		t.Fatalf("expected input unchanged with fewer turns than KeepRecent, got %d items err=%v", len(out), err)
+	}
+}
+
+func TestAgentAppliesHistory(t *testing.T) {
+	toolTurn := `{"id":"resp","model":"demo","usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2},
+	  "output":[{"type":"message","role":"assistant","content":[],"tool_calls":[{"id":"call_1","type":"function","function":{"name":"noop","arguments":"{}"}}]}]}`
+	srv, requests := newAgentJSONServer(t, []string{
+		toolTurn,
+		toolTurn,
+		toolTurn,
+		`{"id":"resp_done","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"done"}]}]}`,
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	tools := NewToolBuilder().Add("noop", "No-op", nil, func(args map[string]any, call llm.ToolCall) (any, error) {
+		return "ok", nil
+	})
+	result, err := client.Agent(context.Background(), "demo", AgentOptions{
+		Tools:   tools,
+		Prompt:  "loop",
+		History: SlidingWindowHistory(1),
+	})
+	if err != nil {
+		t.Fatalf("agent: %v", err)
+	}
+	if result.Output != "done" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	if len(*requests) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(*requests))
+	}
+	for i, req := range *requests {
+		var items []json.RawMessage
+		raw, _ := json.Marshal(req["input"])
+		_ = json.Unmarshal(raw, &items)
+		want := 1
+		if i > 0 {
+			want = 3 // prompt + one assistant/tool turn
+		}
+		if len(items) != want {
+			t.Fatalf("request %d: expected %d input items, got %d", i, want, len(items))
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package sdk
+
+import (
+	"context"
+	"strings"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// AgentEventKind identifies the type of an AgentEvent.
+type AgentEventKind string
+
+const (
+	// AgentEventTextDelta carries a fragment of assistant text.
+	AgentEventTextDelta AgentEventKind = "text_delta"
+	// AgentEventReasoningDelta carries a fragment of reasoning/thinking output.
+	AgentEventReasoningDelta AgentEventKind = "reasoning_delta"
+	// AgentEventToolCallStarted is emitted right before a tool call is executed.
+	AgentEventToolCallStarted AgentEventKind = "tool_call_started"
+	// AgentEventToolCallFinished is emitted after a tool call has been executed.
+	AgentEventToolCallFinished AgentEventKind = "tool_call_finished"
+	// AgentEventTurnCompleted is emitted when the model finishes a turn.
+	AgentEventTurnCompleted AgentEventKind = "turn_completed"
+)
+
+// AgentEvent is a typed event emitted by AgentStream.
+type AgentEvent struct {
+	Kind AgentEventKind
+	// Turn is the zero-based index of the LLM call that produced this event.
+	Turn int
+
+	// TextDelta is set for AgentEventTextDelta.
+	TextDelta string
+	// ReasoningDelta is set for AgentEventReasoningDelta.
+	ReasoningDelta string
+
+	// ToolCall is set for AgentEventToolCallStarted and AgentEventToolCallFinished.
+	ToolCall *llm.ToolCall
+	// ToolResult is set for AgentEventToolCallFinished.
+	ToolResult *ToolExecutionResult
+
+	// Response is the aggregated model response for AgentEventTurnCompleted.
+	Response *Response
+	// Usage is the token usage of the completed turn (AgentEventTurnCompleted).
+	Usage *Usage
+}
+
+type agentStreamState uint8
+
+const (
+	agentStreamStartTurn agentStreamState = iota
+	agentStreamReading
+	agentStreamApproveTools
+	agentStreamExecuteTools
+	agentStreamDone
+)
+
+// AgentStream drives a streaming agent loop. It is pull-based: call Next until
+// it returns ok=false, then read the final result with Result.
+//
+// Tools are executed inside Next after their AgentEventToolCallStarted events
+// have been delivered. When the turn limit is reached, Next returns an
+// AgentMaxTurnsError, matching Client.Agent.
+type AgentStream struct {
+	client   *Client
+	ctx      context.Context
+	model    ModelID
+	tools    []llm.Tool
+	registry *ToolRegistry
+	approval ToolApprovalPolicy
+	history  AgentHistoryStrategy
+	maxTurns int
+
+	input        []llm.InputItem
+	usage        AgentUsage
+	turn         int
+	denied       []DeniedToolCall
+	hstate       AgentHistoryState
+	conversation *agentConversation
+
+	state   agentStreamState
+	handle  *StreamHandle
+	pending []AgentEvent
+	err     error
+
+	collector    *responseCollector
+	lastResponse *Response
+	toolCalls    []llm.ToolCall
+	plan         agentToolPlan
+	result       *AgentResult
+}
+
+// AgentStream starts a streaming agent loop built on Responses.Stream.
+//
+// It accepts the same options as Agent and emits text/reasoning deltas, tool
+// call start/finish events and per-turn completion events as they happen.
+//
+// Example:
+//
+//	stream, err := client.AgentStream(ctx, "claude-sonnet-4-5", sdk.AgentOptions{
+//		Tools:  tools,
+//		Prompt: "Read config.json and summarize it",
+//	})
+//	if err != nil { /* handle */ }
+//	defer stream.Close()
+//	for {
+//		ev, ok, err := stream.Next()
+//		if err != nil { /* handle */ }
+//		if !ok {
+//			break
+//		}
+//		if ev.Kind == sdk.AgentEventTextDelta {
+//			fmt.Print(ev.TextDelta)
+//		}
+//	}
+//	result := stream.Result()
+func (c *Client) AgentStream(ctx context.Context, model string, opts AgentOptions) (*AgentStream, error) {
+	if err := validateAgentOptions(opts); err != nil {
+		return nil, err
+	}
+	toolDefinitions, toolRegistry := buildAgentTools(opts)
+	start, err := c.startAgentConversation(ctx, opts, toolRegistry)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentStream{
+		client:       c,
+		ctx:          ctx,
+		model:        NewModelID(model),
+		tools:        toolDefinitions,
+		registry:     toolRegistry,
+		approval:     opts.Approval,
+		history:      opts.History,
+		maxTurns:     resolveAgentMaxTurns(opts.MaxTurns),
+		input:        start.input,
+		usage:        AgentUsage{ToolCalls: start.toolCalls},
+		denied:       start.denied,
+		conversation: start.conversation,
+	}, nil
+}
+
+// Next returns the next agent event, or ok=false once the agent has finished.
+func (s *AgentStream) Next() (AgentEvent, bool, error) {
+	if s == nil {
+		return AgentEvent{}, false, nil
+	}
+	for {
+		if len(s.pending) > 0 {
+			ev := s.pending[0]
+			s.pending = s.pending[1:]
+			return ev, true, nil
+		}
+		if s.err != nil {
+			return AgentEvent{}, false, s.err
+		}
+		if err := s.ctx.Err(); err != nil && s.state != agentStreamDone {
+			s.fail(err)
+			continue
+		}
+
+		switch s.state {
+		case agentStreamStartTurn:
+			s.startTurn()
+		case agentStreamReading:
+			s.readEvent()
+		case agentStreamApproveTools:
+			s.approveTools()
+		case agentStreamExecuteTools:
+			s.executeTools()
+		default:
+			return AgentEvent{}, false, nil
+		}
+	}
+}
+
+// Result returns the final agent result once Next has returned ok=false
+// without error. It returns nil while the agent is still running.
+func (s *AgentStream) Result() *AgentResult {
+	if s == nil {
+		return nil
+	}
+	return s.result
+}
+
+// Close releases the in-flight model stream, if any.
+func (s *AgentStream) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.state = agentStreamDone
+	return s.closeHandle()
+}
+
+func (s *AgentStream) closeHandle() error {
+	if s.handle == nil {
+		return nil
+	}
+	err := s.handle.Close()
+	s.handle = nil
+	return err
+}
+
+func (s *AgentStream) fail(err error) {
+	_ = s.closeHandle()
+	s.err = err
+	s.state = agentStreamDone
+}
+
+func (s *AgentStream) startTurn() {
+	if s.turn >= s.maxTurns {
+		s.fail(AgentMaxTurnsError{
+			MaxTurns:     s.maxTurns,
+			LastResponse: s.lastResponse,
+			Usage:        s.usage,
+		})
+		return
+	}
+	s.hstate.Turn = s.turn
+	s.hstate.Usage = s.usage
+	input, err := applyAgentHistory(s.ctx, s.history, s.input, s.hstate)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	s.input = input
+	s.hstate.LastInputChars = historyChars(input)
+	req, callOpts, err := s.client.buildAgentRequest(s.model, s.input, s.tools)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	handle, err := s.client.Responses.Stream(s.ctx, req, callOpts...)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	s.handle = handle
+	s.collector = newResponseCollector(handle.RequestID)
+	s.state = agentStreamReading
+}
+
+func (s *AgentStream) readEvent() {
+	ev, ok, err := s.handle.Next()
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	if !ok {
+		_ = s.closeHandle()
+		s.finishTurn()
+		return
+	}
+	if ev.ErrorStatus > 0 {
+		msg := strings.TrimSpace(ev.ErrorMessage)
+		if msg == "" {
+			msg = "stream error"
+		}
+		s.fail(APIError{
+			Status:    ev.ErrorStatus,
+			Code:      APIErrorCode(strings.TrimSpace(ev.ErrorCode)),
+			Message:   msg,
+			RequestID: s.collector.requestID,
+		})
+		return
+	}
+
+	if text, reasoning := s.collector.add(ev); text != "" || reasoning != "" {
+		if reasoning != "" {
+			s.pending = append(s.pending, AgentEvent{Kind: AgentEventReasoningDelta, Turn: s.turn, ReasoningDelta: reasoning})
+		}
+		if text != "" {
+			s.pending = append(s.pending, AgentEvent{Kind: AgentEventTextDelta, Turn: s.turn, TextDelta: text})
+		}
+	}
+}
+
+func (s *AgentStream) finishTurn() {
+	resp := s.collector.response()
+	s.lastResponse = resp
+	s.usage.addResponse(resp)
+	s.hstate.LastUsage = resp.Usage
+	usage := resp.Usage
+	s.pending = append(s.pending, AgentEvent{
+		Kind:     AgentEventTurnCompleted,
+		Turn:     s.turn,
+		Response: resp,
+		Usage:    &usage,
+	})
+	s.turn++
+
+	toolCalls := resp.ToolCalls()
+	if len(toolCalls) == 0 {
+		if text := resp.AssistantText(); text != "" {
+			if err := s.conversation.record(s.ctx, llm.NewAssistantText(text)); err != nil {
+				s.fail(err)
+				return
+			}
+		}
+		s.result = &AgentResult{
+			Output:          resp.AssistantText(),
+			Usage:           s.usage,
+			Response:        resp,
+			DeniedToolCalls: s.denied,
+		}
+		s.state = agentStreamDone
+		return
+	}
+
+	s.usage.ToolCalls += len(toolCalls)
+	s.toolCalls = toolCalls
+	s.state = agentStreamApproveTools
+}
+
+// approveTools runs the approval policy before the assistant message is
+// recorded, so history and AgentEventToolCallStarted carry rewritten calls.
+func (s *AgentStream) approveTools() {
+	plan, err := approveAgentToolCalls(s.ctx, s.approval, s.toolCalls)
+	s.toolCalls = nil
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	assistant := AssistantMessageWithToolCalls(s.lastResponse.AssistantText(), plan.calls)
+	if err := s.conversation.record(s.ctx, assistant); err != nil {
+		s.fail(err)
+		return
+	}
+	s.input = append(s.input, assistant)
+	s.plan = plan
+	for i := range plan.calls {
+		s.pending = append(s.pending, AgentEvent{Kind: AgentEventToolCallStarted, Turn: s.turn - 1, ToolCall: &plan.calls[i]})
+	}
+	s.state = agentStreamExecuteTools
+}
+
+func (s *AgentStream) executeTools() {
+	plan := s.plan
+	s.plan = agentToolPlan{}
+	calls := plan.calls
+	results := plan.execute(s.ctx, s.registry)
+	if err := s.ctx.Err(); err != nil {
+		s.fail(err)
+		return
+	}
+	s.denied = append(s.denied, plan.denied...)
+	msgs, err := agentToolResultMessages(results)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	if err := s.conversation.record(s.ctx, msgs...); err != nil {
+		s.fail(err)
+		return
+	}
+	s.input = append(s.input, msgs...)
+	for i := range results {
+		s.pending = append(s.pending, AgentEvent{
+			Kind:       AgentEventToolCallFinished,
+			Turn:       s.turn - 1,
+			ToolCall:   &calls[i],
+			ToolResult: &results[i],
+		})
+	}
+	s.state = agentStreamStartTurn
+}
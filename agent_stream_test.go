@@ -0,0 +1,168 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+func newAgentStreamServer(t *testing.T, turns [][]string) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idx := int(atomic.AddInt32(&calls, 1)) - 1
+		if idx >= len(turns) {
+			idx = len(turns) - 1
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher, _ := w.(http.Flusher)
+		for _, line := range turns[idx] {
+			_, _ = w.Write([]byte(line + "\n"))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestAgentStream(t *testing.T) {
+	srv, calls := newAgentStreamServer(t, [][]string{
+		{
+			`{"type":"start","request_id":"resp_1","model":"demo"}`,
+			`{"type":"reasoning_delta","reasoning_delta":"thinking"}`,
+			`{"type":"tool_use_start","tool_call_delta":{"index":0,"id":"call_1","type":"function","function":{"name":"echo"}}}`,
+			`{"type":"tool_use_delta","tool_call_delta":{"index":0,"function":{"arguments":"{\"message\":\"hello\"}"}}}`,
+			`{"type":"completion","stop_reason":"tool_calls","usage":{"input_tokens":3,"output_tokens":2,"total_tokens":5}}`,
+		},
+		{
+			`{"type":"start","request_id":"resp_2","model":"demo"}`,
+			`{"type":"update","delta":"Hello "}`,
+			`{"type":"update","delta":"world"}`,
+			`{"type":"completion","usage":{"input_tokens":4,"output_tokens":2,"total_tokens":6}}`,
+		},
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	type echoArgs struct {
+		Message string `json:"message"`
+	}
+	tools := NewToolBuilder()
+	AddFunc(tools, "echo", "Echo", func(args echoArgs) (any, error) {
+		return args.Message, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rewrite := ToolApprovalFunc(func(context.Context, llm.ToolCall) (ToolApproval, error) {
+		return RewriteToolCall(`{"message":"hi"}`), nil
+	})
+	stream, err := client.AgentStream(ctx, "demo", AgentOptions{Tools: tools, Prompt: "say hi", Approval: rewrite})
+	if err != nil {
+		t.Fatalf("agent stream: %v", err)
+	}
+	t.Cleanup(func() { _ = stream.Close() })
+
+	var kinds []AgentEventKind
+	var text string
+	for {
+		ev, ok, err := stream.Next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if !ok {
+			break
+		}
+		kinds = append(kinds, ev.Kind)
+		switch ev.Kind {
+		case AgentEventTextDelta:
+			text += ev.TextDelta
+		case AgentEventToolCallStarted:
+			if ev.ToolCall.Function.Arguments != `{"message":"hi"}` {
+				t.Fatalf("expected rewritten call, got %+v", ev.ToolCall.Function)
+			}
+		case AgentEventToolCallFinished:
+			if ev.ToolResult == nil || ev.ToolResult.Result != "hi" {
+				t.Fatalf("unexpected tool result %+v", ev.ToolResult)
+			}
+		}
+	}
+
+	want := []AgentEventKind{
+		AgentEventReasoningDelta,
+		AgentEventTurnCompleted,
+		AgentEventToolCallStarted,
+		AgentEventToolCallFinished,
+		AgentEventTextDelta,
+		AgentEventTextDelta,
+		AgentEventTurnCompleted,
+	}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, kinds)
+		}
+	}
+	if text != "Hello world" {
+		t.Fatalf("unexpected text %q", text)
+	}
+
+	result := stream.Result()
+	if result == nil {
+		t.Fatal("expected result")
+	}
+	if result.Output != "Hello world" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	if result.Usage.LLMCalls != 2 || result.Usage.ToolCalls != 1 || result.Usage.TotalTokens != 11 {
+		t.Fatalf("unexpected usage %+v", result.Usage)
+	}
+	if atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("expected 2 requests, got %d", *calls)
+	}
+}
+
+func TestAgentStreamMaxTurns(t *testing.T) {
+	srv, _ := newAgentStreamServer(t, [][]string{{
+		`{"type":"start","request_id":"resp_1","model":"demo"}`,
+		`{"type":"completion","tool_calls":[{"id":"call_1","type":"function","function":{"name":"noop","arguments":"{}"}}]}`,
+	}})
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	tools := NewToolBuilder().Add("noop", "No-op", nil, func(args map[string]any, call llm.ToolCall) (any, error) {
+		return "ok", nil
+	})
+
+	stream, err := client.AgentStream(context.Background(), "demo", AgentOptions{Tools: tools, Prompt: "loop", MaxTurns: 2})
+	if err != nil {
+		t.Fatalf("agent stream: %v", err)
+	}
+	t.Cleanup(func() { _ = stream.Close() })
+
+	for {
+		_, ok, err := stream.Next()
+		if err != nil {
+			var maxErr AgentMaxTurnsError
+			if !errors.As(err, &maxErr) {
+				t.Fatalf("expected AgentMaxTurnsError, got %v", err)
+			}
+			if maxErr.MaxTurns != 2 || maxErr.Usage.LLMCalls != 2 || maxErr.LastResponse == nil {
+				t.Fatalf("unexpected max turns error %+v", maxErr)
+			}
+			return
+		}
+		if !ok {
+			t.Fatal("expected max turns error before completion")
+		}
+	}
+}
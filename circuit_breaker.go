@@ -0,0 +1,130 @@
+package sdk
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreakerConfig controls when a circuit breaker opens.
+//
+// After FailureThreshold consecutive failures the breaker opens and rejects
+// calls for Cooldown. It then lets a single trial call through (half-open): a
+// success closes it again, a failure re-opens it. A zero FailureThreshold
+// disables the breaker.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+func (c CircuitBreakerConfig) enabled() bool { return c.FailureThreshold > 0 }
+
+func (c CircuitBreakerConfig) normalized() CircuitBreakerConfig {
+	if c.Cooldown <= 0 {
+		c.Cooldown = 30 * time.Second
+	}
+	return c
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{cfg: cfg.normalized(), now: time.Now, state: CircuitClosed}
+}
+
+// allow reports whether a call may proceed. In the half-open state only one
+// trial call is allowed until it reports its outcome.
+func (b *circuitBreaker) allow() bool {
+	if b == nil || !b.cfg.enabled() {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.trial = true
+		return true
+	case CircuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) success() {
+	if b == nil || !b.cfg.enabled() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *circuitBreaker) failure() {
+	if b == nil || !b.cfg.enabled() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if b.state == CircuitHalfOpen {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release ends a half-open trial without recording an outcome, e.g. when the
+// caller's context was cancelled.
+func (b *circuitBreaker) release() {
+	if b == nil || !b.cfg.enabled() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
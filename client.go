@@ -39,6 +39,13 @@ type clientOptions struct {
 	requestTimeout *time.Duration
 	retry          *RetryConfig
 	tokenProvider  TokenProvider
+
+	breaker         *CircuitBreakerConfig
+	breakerPerRoute bool
+	concurrency     *AdaptiveConcurrencyConfig
+
+	customerRateLimit *CustomerRateLimitConfig
+	middleware        []Middleware
 }
 
 // WithBaseURL sets a custom API base URL.
@@ -104,6 +111,9 @@ type Client struct {
 	connectTimeout time.Duration
 	requestTimeout time.Duration
 	retryCfg       RetryConfig
+	guard          *transportGuard
+	rateLimit      *customerRateLimiter
+	middleware     *middlewareChain
 
 	// Grouped service clients.
 	Responses    *ResponsesClient
@@ -118,6 +128,7 @@ type Client struct {
 	Tiers        *TiersClient
 	Billing      *BillingClient
 	SQL          *SQLClient
+	Generations  *GenerationsClient
 
 	pluginsOnce sync.Once
 	plugins     *PluginsClient
@@ -245,6 +256,9 @@ func newClientFromOptions(apiKey APIKeyAuth, accessToken string, opts clientOpti
 		connectTimeout: resolveConnectTimeout(opts.connectTimeout),
 		requestTimeout: resolveRequestTimeout(opts.requestTimeout),
 		retryCfg:       retryCfg,
+		guard:          newTransportGuard(normalized, opts),
+		rateLimit:      newCustomerRateLimiter(normalized, opts),
+		middleware:     newMiddlewareChain(normalized, opts),
 	}
 	client.Responses = &ResponsesClient{client: client}
 	client.Workflows = &WorkflowsClient{client: client}
@@ -258,6 +272,7 @@ func newClientFromOptions(apiKey APIKeyAuth, accessToken string, opts clientOpti
 	client.Tiers = &TiersClient{client: client}
 	client.Billing = &BillingClient{client: client}
 	client.SQL = &SQLClient{client: client}
+	client.Generations = &GenerationsClient{client: client}
 	return client, nil
 }
 
@@ -423,6 +438,12 @@ func (c *Client) send(req *http.Request, timeout *time.Duration, retry *RetryCon
 	if retry != nil {
 		cfg = retry.normalized()
 	}
+	if err := c.rateLimit.admit(req); err != nil {
+		if cancel != nil {
+			cancel()
+		}
+		return nil, nil, err
+	}
 	resp, meta, err := c.sendWithRetry(req, cfg)
 	if err != nil {
 		if cancel != nil {
@@ -453,6 +474,9 @@ func (c *Client) sendStreaming(req *http.Request, retry *RetryConfig) (*http.Res
 	if retry != nil {
 		cfg = retry.normalized()
 	}
+	if err := c.rateLimit.admit(req); err != nil {
+		return nil, nil, err
+	}
 	resp, meta, err := c.sendWithRetry(req, cfg)
 	if err != nil {
 		return nil, meta, err
@@ -526,9 +550,14 @@ func (c *Client) sendWithRetry(req *http.Request, cfg RetryConfig) (*http.Respon
 		if c.telemetry.OnHTTPRequest != nil {
 			c.telemetry.OnHTTPRequest(cloned.Context(), cloned)
 		}
+		done, guardErr := c.guard.acquire(cloned.Context(), cloned.URL.Path)
+		if guardErr != nil {
+			return nil, &meta, guardErr
+		}
 		start := time.Now()
-		resp, err := c.httpClient.Do(cloned)
+		resp, err := c.middleware.roundTrip(c.httpClient, cloned, attempt)
 		latency := time.Since(start)
+		done(resp, err)
 		if c.telemetry.OnHTTPResponse != nil {
 			c.telemetry.OnHTTPResponse(cloned.Context(), cloned, resp, err, latency)
 		}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 
+	"github.com/google/uuid"
+
 	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
 )
 
@@ -102,6 +104,27 @@ type AgentOptions struct {
 	// MaxTurns limits the number of LLM calls.
 	// Default is 100. Set to NoTurnLimit (-1) for unlimited turns.
 	MaxTurns int
+	// MaxToolConcurrency runs up to this many tool calls from a single turn in
+	// parallel. Default (0 or 1) executes them sequentially. Tools marked with
+	// ToolBuilder.Serial always run alone.
+	MaxToolConcurrency int
+	// Approval, if set, is consulted before every tool call. Denied calls are
+	// not executed; the denial message is returned to the model as the tool
+	// result and recorded in AgentResult.DeniedToolCalls.
+	Approval ToolApprovalPolicy
+	// History, if set, compacts the conversation before each follow-up turn
+	// (see SlidingWindowHistory, TrimToolResultsHistory, SummarizeHistory and
+	// TokenBudgetHistory). Default sends the full history every turn.
+	History AgentHistoryStrategy
+	// Conversation, if set, persists the conversation under ConversationID and
+	// resumes from its stored history. Prompt is appended as a new user message
+	// and may be empty when resuming. Tool calls left without results by an
+	// interrupted run are executed before the next turn.
+	Conversation   ConversationStore
+	ConversationID string
+	// SessionID is shorthand for a Conversation backed by Client.Sessions.
+	// Create the session with Sessions.Create.
+	SessionID uuid.UUID
 }
 
 // AgentResult contains the result of an agent run.
@@ -112,6 +135,8 @@ type AgentResult struct {
 	Usage AgentUsage
 	// Response is the final response from the model.
 	Response *Response
+	// DeniedToolCalls lists tool calls rejected by AgentOptions.Approval.
+	DeniedToolCalls []DeniedToolCall
 }
 
 // AgentUsage tracks usage across an agent run.
@@ -144,54 +169,39 @@ type AgentUsage struct {
 //		Prompt: "Read config.json and summarize it",
 //	})
 func (c *Client) Agent(ctx context.Context, model string, opts AgentOptions) (*AgentResult, error) {
-	if opts.Tools == nil {
-		return nil, ConfigError{Reason: "Tools (ToolBuilder) is required for Agent"}
-	}
-	if opts.Prompt == "" {
-		return nil, ConfigError{Reason: "Prompt is required for Agent"}
+	if err := validateAgentOptions(opts); err != nil {
+		return nil, err
 	}
 
 	// Extract definitions and registry from ToolBuilder
-	toolDefinitions, toolRegistry := opts.Tools.Build()
+	toolDefinitions, toolRegistry := buildAgentTools(opts)
 
-	var usage AgentUsage
-	var input []llm.InputItem
-
-	// Build initial input
-	if opts.System != "" {
-		input = append(input, llm.InputItem{
-			Type:    llm.InputItemTypeMessage,
-			Role:    llm.RoleSystem,
-			Content: []llm.ContentPart{llm.TextPart(opts.System)},
-		})
+	start, err := c.startAgentConversation(ctx, opts, toolRegistry)
+	if err != nil {
+		return nil, err
 	}
-	input = append(input, llm.InputItem{
-		Type:    llm.InputItemTypeMessage,
-		Role:    llm.RoleUser,
-		Content: []llm.ContentPart{llm.TextPart(opts.Prompt)},
-	})
+	conversation := start.conversation
+	input := start.input
+	usage := AgentUsage{ToolCalls: start.toolCalls}
 
 	modelID := NewModelID(model)
-	maxTurns := opts.MaxTurns
-	if maxTurns == 0 {
-		maxTurns = DefaultMaxTurns
-	} else if maxTurns < 0 {
-		maxTurns = int(^uint(0) >> 1) // max int - effectively no limit
-	}
+	maxTurns := resolveAgentMaxTurns(opts.MaxTurns)
 
 	var lastResponse *Response
+	denied := start.denied
+	var history AgentHistoryState
 
 	for turn := 0; turn < maxTurns; turn++ {
-		// Build request
-		builder := c.Responses.New().
-			Model(modelID).
-			Input(input)
-
-		if len(toolDefinitions) > 0 {
-			builder = builder.Tools(toolDefinitions)
+		// Compact history before follow-up turns
+		history.Turn = turn
+		history.Usage = usage
+		input, err = applyAgentHistory(ctx, opts.History, input, history)
+		if err != nil {
+			return nil, err
 		}
 
-		req, callOpts, err := builder.Build()
+		// Build request
+		req, callOpts, err := c.buildAgentRequest(modelID, input, toolDefinitions)
 		if err != nil {
 			return nil, err
 		}
@@ -203,43 +213,57 @@ func (c *Client) Agent(ctx context.Context, model string, opts AgentOptions) (*A
 		}
 
 		lastResponse = resp
-		usage.LLMCalls++
-
-		// Accumulate usage
-		usage.InputTokens += resp.Usage.InputTokens
-		usage.OutputTokens += resp.Usage.OutputTokens
-		usage.TotalTokens += resp.Usage.TotalTokens
+		usage.addResponse(resp)
+		history.LastUsage = resp.Usage
+		history.LastInputChars = historyChars(input)
 
 		// Check for tool calls
 		toolCalls := resp.ToolCalls()
 		if len(toolCalls) == 0 {
 			// No tool calls, we're done
+			if text := resp.AssistantText(); text != "" {
+				if err := conversation.record(ctx, llm.NewAssistantText(text)); err != nil {
+					return nil, err
+				}
+			}
 			return &AgentResult{
-				Output:   resp.AssistantText(),
-				Usage:    usage,
-				Response: resp,
+				Output:          resp.AssistantText(),
+				Usage:           usage,
+				Response:        resp,
+				DeniedToolCalls: denied,
 			}, nil
 		}
 
 		// Execute tool calls
 		usage.ToolCalls += len(toolCalls)
 
+		// Approve before recording so rewritten arguments reach the history
+		plan, err := approveAgentToolCalls(ctx, opts.Approval, toolCalls)
+		if err != nil {
+			return nil, err
+		}
+
 		// Add assistant message with tool calls to history
-		input = append(input, AssistantMessageWithToolCalls(resp.AssistantText(), toolCalls))
+		assistant := AssistantMessageWithToolCalls(resp.AssistantText(), plan.calls)
+		if err := conversation.record(ctx, assistant); err != nil {
+			return nil, err
+		}
+		input = append(input, assistant)
 
 		// Execute tools and add results
-		results := toolRegistry.ExecuteAll(toolCalls)
-		for _, result := range results {
-			resultValue := result.Result
-			if result.Error != nil {
-				resultValue = "Error: " + result.Error.Error()
-			}
-			msg, err := ToolResultMessage(result.ToolCallID, resultValue)
-			if err != nil {
-				return nil, err
-			}
-			input = append(input, msg)
+		results := plan.execute(ctx, toolRegistry)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		denied = append(denied, plan.denied...)
+		msgs, err := agentToolResultMessages(results)
+		if err != nil {
+			return nil, err
+		}
+		if err := conversation.record(ctx, msgs...); err != nil {
+			return nil, err
 		}
+		input = append(input, msgs...)
 	}
 
 	// Hit max turns without completion - this is an error
@@ -249,3 +273,86 @@ func (c *Client) Agent(ctx context.Context, model string, opts AgentOptions) (*A
 		Usage:        usage,
 	}
 }
+
+func validateAgentOptions(opts AgentOptions) error {
+	if opts.Tools == nil {
+		return ConfigError{Reason: "Tools (ToolBuilder) is required for Agent"}
+	}
+	if opts.Conversation != nil && opts.ConversationID == "" {
+		return ConfigError{Reason: "ConversationID is required when Conversation is set"}
+	}
+	if opts.Prompt == "" && opts.Conversation == nil && opts.SessionID == uuid.Nil {
+		return ConfigError{Reason: "Prompt is required for Agent"}
+	}
+	return nil
+}
+
+func buildAgentTools(opts AgentOptions) ([]llm.Tool, *ToolRegistry) {
+	toolDefinitions, toolRegistry := opts.Tools.Build()
+	if opts.MaxToolConcurrency > 1 {
+		toolRegistry.SetMaxConcurrency(opts.MaxToolConcurrency)
+	}
+	return toolDefinitions, toolRegistry
+}
+
+// newAgentInput builds the initial system/user input for an agent run.
+func newAgentInput(opts AgentOptions) []llm.InputItem {
+	var input []llm.InputItem
+	if opts.System != "" {
+		input = append(input, llm.InputItem{
+			Type:    llm.InputItemTypeMessage,
+			Role:    llm.RoleSystem,
+			Content: []llm.ContentPart{llm.TextPart(opts.System)},
+		})
+	}
+	input = append(input, llm.InputItem{
+		Type:    llm.InputItemTypeMessage,
+		Role:    llm.RoleUser,
+		Content: []llm.ContentPart{llm.TextPart(opts.Prompt)},
+	})
+	return input
+}
+
+func resolveAgentMaxTurns(maxTurns int) int {
+	if maxTurns == 0 {
+		return DefaultMaxTurns
+	}
+	if maxTurns < 0 {
+		return int(^uint(0) >> 1) // max int - effectively no limit
+	}
+	return maxTurns
+}
+
+func (c *Client) buildAgentRequest(model ModelID, input []llm.InputItem, tools []llm.Tool) (ResponseRequest, []ResponseOption, error) {
+	builder := c.Responses.New().
+		Model(model).
+		Input(input)
+	if len(tools) > 0 {
+		builder = builder.Tools(tools)
+	}
+	return builder.Build()
+}
+
+// agentToolResultMessages converts tool execution results into tool result input items.
+func agentToolResultMessages(results []ToolExecutionResult) ([]llm.InputItem, error) {
+	msgs := make([]llm.InputItem, 0, len(results))
+	for _, result := range results {
+		resultValue := result.Result
+		if result.Error != nil {
+			resultValue = "Error: " + result.Error.Error()
+		}
+		msg, err := ToolResultMessage(result.ToolCallID, resultValue)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (u *AgentUsage) addResponse(resp *Response) {
+	u.LLMCalls++
+	u.InputTokens += resp.Usage.InputTokens
+	u.OutputTokens += resp.Usage.OutputTokens
+	u.TotalTokens += resp.Usage.TotalTokens
+}
@@ -0,0 +1,187 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// ErrConversationNotFound is returned by ConversationStore implementations when
+// the requested conversation does not exist.
+var ErrConversationNotFound = errors.New("sdk: conversation not found")
+
+// ConversationInfo summarizes a stored conversation.
+type ConversationInfo struct {
+	ID           string
+	MessageCount int
+	UpdatedAt    time.Time
+}
+
+// ConversationStore persists conversation history as a sequence of input items.
+//
+// The SDK ships an in-memory store (NewMemoryConversationStore), a JSONL file
+// store (NewJSONLConversationStore), a database/sql store
+// (NewSQLConversationStore) and an adapter over the hosted sessions API
+// (NewSessionsConversationStore). Stores are used by Agent via
+// AgentOptions.Conversation and by ResponsesClient.Create via WithConversation.
+type ConversationStore interface {
+	// Append adds items to the end of a conversation, creating it if needed.
+	Append(ctx context.Context, id string, items ...llm.InputItem) error
+	// Load returns all items of a conversation in order.
+	Load(ctx context.Context, id string) ([]llm.InputItem, error)
+	// List returns the stored conversations.
+	List(ctx context.Context) ([]ConversationInfo, error)
+	// Delete removes a conversation.
+	Delete(ctx context.Context, id string) error
+	// Fork copies a conversation into a new one and returns its ID. When upTo
+	// is positive only the first upTo items are copied.
+	Fork(ctx context.Context, id string, upTo int) (string, error)
+}
+
+// MemoryConversationStore keeps conversations in memory. It is safe for
+// concurrent use and mainly intended for tests and short-lived processes.
+type MemoryConversationStore struct {
+	mu            sync.Mutex
+	conversations map[string]*memoryConversation
+}
+
+type memoryConversation struct {
+	items     []llm.InputItem
+	updatedAt time.Time
+}
+
+// NewMemoryConversationStore returns an empty in-memory store.
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{conversations: make(map[string]*memoryConversation)}
+}
+
+// Append implements ConversationStore.
+func (s *MemoryConversationStore) Append(_ context.Context, id string, items ...llm.InputItem) error {
+	if err := validateConversationID(id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conv, ok := s.conversations[id]
+	if !ok {
+		conv = &memoryConversation{}
+		s.conversations[id] = conv
+	}
+	conv.items = append(conv.items, items...)
+	conv.updatedAt = time.Now()
+	return nil
+}
+
+// Load implements ConversationStore.
+func (s *MemoryConversationStore) Load(_ context.Context, id string) ([]llm.InputItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conv, ok := s.conversations[id]
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+	return append([]llm.InputItem(nil), conv.items...), nil
+}
+
+// List implements ConversationStore. Conversations are sorted by ID.
+func (s *MemoryConversationStore) List(_ context.Context) ([]ConversationInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]ConversationInfo, 0, len(s.conversations))
+	for id, conv := range s.conversations {
+		out = append(out, ConversationInfo{ID: id, MessageCount: len(conv.items), UpdatedAt: conv.updatedAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Delete implements ConversationStore.
+func (s *MemoryConversationStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.conversations[id]; !ok {
+		return ErrConversationNotFound
+	}
+	delete(s.conversations, id)
+	return nil
+}
+
+// Fork implements ConversationStore.
+func (s *MemoryConversationStore) Fork(_ context.Context, id string, upTo int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conv, ok := s.conversations[id]
+	if !ok {
+		return "", ErrConversationNotFound
+	}
+	newID := uuid.NewString()
+	s.conversations[newID] = &memoryConversation{
+		items:     append([]llm.InputItem(nil), forkItems(conv.items, upTo)...),
+		updatedAt: time.Now(),
+	}
+	return newID, nil
+}
+
+func forkItems(items []llm.InputItem, upTo int) []llm.InputItem {
+	if upTo > 0 && upTo < len(items) {
+		return items[:upTo]
+	}
+	return items
+}
+
+func validateConversationID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("sdk: conversation id is required")
+	}
+	return nil
+}
+
+// conversationInput merges stored history with a request's input. Leading
+// system messages of the request stay first so that instructions precede the
+// history.
+func conversationInput(history, input []llm.InputItem) []llm.InputItem {
+	i := 0
+	for i < len(input) && input[i].Role == llm.RoleSystem {
+		i++
+	}
+	out := make([]llm.InputItem, 0, len(history)+len(input))
+	out = append(out, input[:i]...)
+	out = append(out, history...)
+	return append(out, input[i:]...)
+}
+
+// conversationItems drops system messages, which are request configuration
+// rather than conversation history.
+func conversationItems(items []llm.InputItem) []llm.InputItem {
+	out := make([]llm.InputItem, 0, len(items))
+	for _, item := range items {
+		if item.Role != llm.RoleSystem {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// responseAssistantItem converts a response's assistant output into an input
+// item for the conversation history, or nil when there is nothing to record.
+func responseAssistantItem(resp *Response) *llm.InputItem {
+	text := resp.AssistantText()
+	calls := resp.ToolCalls()
+	switch {
+	case len(calls) > 0:
+		item := AssistantMessageWithToolCalls(text, calls)
+		return &item
+	case text != "":
+		item := llm.NewAssistantText(text)
+		return &item
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+const jsonlConversationExt = ".jsonl"
+
+var jsonlConversationIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// JSONLConversationStore stores each conversation as a JSONL file (one input
+// item per line) in a directory. IDs are used as file names and may only
+// contain letters, digits, '.', '_' and '-'.
+type JSONLConversationStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJSONLConversationStore returns a store rooted at dir, creating the
+// directory if it does not exist.
+func NewJSONLConversationStore(dir string) (*JSONLConversationStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, ConfigError{Reason: "directory is required for JSONLConversationStore"}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("sdk: create conversation directory: %w", err)
+	}
+	return &JSONLConversationStore{dir: dir}, nil
+}
+
+func (s *JSONLConversationStore) path(id string) (string, error) {
+	if !jsonlConversationIDPattern.MatchString(id) {
+		return "", fmt.Errorf("sdk: invalid conversation id %q", id)
+	}
+	return filepath.Join(s.dir, id+jsonlConversationExt), nil
+}
+
+// Append implements ConversationStore.
+func (s *JSONLConversationStore) Append(_ context.Context, id string, items ...llm.InputItem) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load implements ConversationStore.
+func (s *JSONLConversationStore) Load(_ context.Context, id string) ([]llm.InputItem, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readJSONLConversation(path)
+}
+
+func readJSONLConversation(path string) ([]llm.InputItem, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck // read-only file
+	defer f.Close()
+
+	items := []llm.InputItem{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var item llm.InputItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, fmt.Errorf("sdk: %s:%d: %w", filepath.Base(path), line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// List implements ConversationStore. Conversations are sorted by ID.
+func (s *JSONLConversationStore) List(_ context.Context) ([]ConversationInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []ConversationInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, jsonlConversationExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		items, err := readJSONLConversation(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ConversationInfo{
+			ID:           strings.TrimSuffix(name, jsonlConversationExt),
+			MessageCount: len(items),
+			UpdatedAt:    info.ModTime(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Delete implements ConversationStore.
+func (s *JSONLConversationStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrConversationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Fork implements ConversationStore.
+func (s *JSONLConversationStore) Fork(ctx context.Context, id string, upTo int) (string, error) {
+	items, err := s.Load(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	newID := uuid.NewString()
+	if err := s.Append(ctx, newID, forkItems(items, upTo)...); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
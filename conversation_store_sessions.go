@@ -0,0 +1,233 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// Session content part types used to persist agent tool traffic. Text and file
+// parts are stored in their llm.ContentPart JSON form.
+const (
+	sessionPartToolCall   = "tool_call"
+	sessionPartToolResult = "tool_result"
+)
+
+// SessionMessageFromInputItem converts a user, assistant or tool input item into
+// a session message. Assistant tool calls and the tool_call_id of tool results
+// are stored as "tool_call" and "tool_result" content parts so the pairing
+// survives a round trip through the session.
+func SessionMessageFromInputItem(item llm.InputItem) (SessionMessageCreateRequest, error) {
+	switch item.Role {
+	case llm.RoleUser, llm.RoleAssistant, llm.RoleTool:
+	default:
+		return SessionMessageCreateRequest{}, fmt.Errorf("sdk: cannot store %q message in a session", item.Role)
+	}
+
+	content := make([]map[string]any, 0, len(item.Content)+len(item.ToolCalls)+1)
+	if item.Role == llm.RoleTool {
+		if item.ToolCallID == "" {
+			return SessionMessageCreateRequest{}, fmt.Errorf("sdk: tool message requires tool_call_id")
+		}
+		content = append(content, map[string]any{"type": sessionPartToolResult, "tool_call_id": item.ToolCallID.String()})
+	}
+	for _, part := range item.Content {
+		m, err := contentPartToMap(part)
+		if err != nil {
+			return SessionMessageCreateRequest{}, err
+		}
+		content = append(content, m)
+	}
+	for _, call := range item.ToolCalls {
+		content = append(content, map[string]any{
+			"type":      sessionPartToolCall,
+			"id":        call.ID.String(),
+			"name":      GetToolName(call).String(),
+			"arguments": GetToolArgsRaw(call),
+		})
+	}
+	return SessionMessageCreateRequest{Role: string(item.Role), Content: content}, nil
+}
+
+// InputItemsFromSessionMessages converts stored session messages back into
+// input items, restoring assistant tool calls and tool result IDs.
+func InputItemsFromSessionMessages(msgs []SessionMessage) ([]llm.InputItem, error) {
+	items := make([]llm.InputItem, 0, len(msgs))
+	for _, msg := range msgs {
+		item := llm.InputItem{Type: llm.InputItemTypeMessage, Role: llm.MessageRole(msg.Role)}
+		for _, part := range msg.Content {
+			typ, _ := part["type"].(string)
+			switch typ {
+			case sessionPartToolCall:
+				id, _ := part["id"].(string)
+				name, _ := part["name"].(string)
+				args, _ := part["arguments"].(string)
+				item.ToolCalls = append(item.ToolCalls, NewToolCall(ToolCallID(id), ToolName(name), args))
+			case sessionPartToolResult:
+				id, _ := part["tool_call_id"].(string)
+				item.ToolCallID = ToolCallID(id)
+			default:
+				cp, err := contentPartFromMap(part)
+				if err != nil {
+					return nil, fmt.Errorf("sdk: session message %d: %w", msg.Seq, err)
+				}
+				item.Content = append(item.Content, cp)
+			}
+		}
+		if item.Role == llm.RoleTool && item.ToolCallID == "" {
+			return nil, fmt.Errorf("sdk: session message %d: tool message without tool_call_id", msg.Seq)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func contentPartToMap(part llm.ContentPart) (map[string]any, error) {
+	raw, err := json.Marshal(part)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func contentPartFromMap(m map[string]any) (llm.ContentPart, error) {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return llm.ContentPart{}, err
+	}
+	var part llm.ContentPart
+	if err := json.Unmarshal(raw, &part); err != nil {
+		return llm.ContentPart{}, err
+	}
+	return part, nil
+}
+
+// SessionsConversationStore implements ConversationStore on top of the hosted
+// sessions API. Conversation IDs are session UUIDs. Append requires an existing
+// session (create one with Sessions.Create) and skips system messages, which
+// sessions do not store.
+type SessionsConversationStore struct {
+	sessions *SessionsClient
+}
+
+// NewSessionsConversationStore returns a ConversationStore backed by sessions.
+func NewSessionsConversationStore(sessions *SessionsClient) *SessionsConversationStore {
+	return &SessionsConversationStore{sessions: sessions}
+}
+
+func parseSessionConversationID(id string) (uuid.UUID, error) {
+	sessionID, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("sdk: invalid session id %q: %w", id, err)
+	}
+	return sessionID, nil
+}
+
+// Append implements ConversationStore.
+func (s *SessionsConversationStore) Append(ctx context.Context, id string, items ...llm.InputItem) error {
+	sessionID, err := parseSessionConversationID(id)
+	if err != nil {
+		return err
+	}
+	for _, item := range conversationItems(items) {
+		req, err := SessionMessageFromInputItem(item)
+		if err != nil {
+			return err
+		}
+		if _, err := s.sessions.AddMessage(ctx, sessionID, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load implements ConversationStore.
+func (s *SessionsConversationStore) Load(ctx context.Context, id string) ([]llm.InputItem, error) {
+	sessionID, err := parseSessionConversationID(id)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.sessions.Get(ctx, sessionID)
+	if err != nil {
+		var apiErr APIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	return InputItemsFromSessionMessages(stored.Messages)
+}
+
+// List implements ConversationStore, following pagination cursors.
+func (s *SessionsConversationStore) List(ctx context.Context) ([]ConversationInfo, error) {
+	var out []ConversationInfo
+	opts := ListOptions{Limit: 100}
+	for {
+		page, err := s.sessions.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range page.Sessions {
+			out = append(out, ConversationInfo{
+				ID:           session.Id.String(),
+				MessageCount: int(session.MessageCount),
+				UpdatedAt:    session.UpdatedAt,
+			})
+		}
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			return out, nil
+		}
+		opts.Cursor = *page.NextCursor
+	}
+}
+
+// Delete implements ConversationStore.
+func (s *SessionsConversationStore) Delete(ctx context.Context, id string) error {
+	sessionID, err := parseSessionConversationID(id)
+	if err != nil {
+		return err
+	}
+	return s.sessions.Delete(ctx, sessionID)
+}
+
+// Fork implements ConversationStore by creating a new session with the same
+// customer and metadata and copying the messages into it.
+func (s *SessionsConversationStore) Fork(ctx context.Context, id string, upTo int) (string, error) {
+	sessionID, err := parseSessionConversationID(id)
+	if err != nil {
+		return "", err
+	}
+	stored, err := s.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	items, err := InputItemsFromSessionMessages(stored.Messages)
+	if err != nil {
+		return "", err
+	}
+	req := SessionCreateRequest{CustomerId: stored.CustomerId}
+	if stored.Metadata != nil {
+		metadata := stored.Metadata
+		req.Metadata = &metadata
+	}
+	created, err := s.sessions.Create(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	newID := created.Id.String()
+	if err := s.Append(ctx, newID, forkItems(items, upTo)...); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
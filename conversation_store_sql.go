@@ -0,0 +1,217 @@
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// SQLPlaceholderStyle selects how SQLConversationStore writes bind parameters.
+type SQLPlaceholderStyle int
+
+const (
+	// SQLPlaceholderQuestion uses "?" (SQLite, MySQL).
+	SQLPlaceholderQuestion SQLPlaceholderStyle = iota
+	// SQLPlaceholderDollar uses "$1", "$2", ... (PostgreSQL).
+	SQLPlaceholderDollar
+)
+
+// SQLConversationStoreOptions configures NewSQLConversationStore.
+type SQLConversationStoreOptions struct {
+	// Table is the table name. Default "modelrelay_conversation_items".
+	Table string
+	// Placeholder is the bind parameter style. Default SQLPlaceholderQuestion.
+	Placeholder SQLPlaceholderStyle
+}
+
+const defaultSQLConversationTable = "modelrelay_conversation_items"
+
+var sqlConversationTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLConversationStore stores conversations in a database/sql database, one
+// row per input item. It uses only portable SQL and works with any driver.
+//
+// The SDK does not bundle a database driver. For an embedded store that needs
+// no external services, open a file-backed SQLite database with a pure-Go
+// driver such as modernc.org/sqlite and keep the default placeholder style:
+//
+//	import _ "modernc.org/sqlite"
+//
+//	db, err := sql.Open("sqlite", "conversations.db")
+//	if err != nil { /* handle */ }
+//	store, err := sdk.NewSQLConversationStore(db, sdk.SQLConversationStoreOptions{})
+//	if err != nil { /* handle */ }
+//	err = store.EnsureSchema(ctx)
+//
+// For PostgreSQL use SQLPlaceholderDollar. Call EnsureSchema once to create
+// the table.
+type SQLConversationStore struct {
+	db    *sql.DB
+	table string
+	style SQLPlaceholderStyle
+}
+
+// NewSQLConversationStore returns a store backed by db.
+func NewSQLConversationStore(db *sql.DB, opts SQLConversationStoreOptions) (*SQLConversationStore, error) {
+	if db == nil {
+		return nil, ConfigError{Reason: "db is required for SQLConversationStore"}
+	}
+	table := opts.Table
+	if table == "" {
+		table = defaultSQLConversationTable
+	}
+	if !sqlConversationTablePattern.MatchString(table) {
+		return nil, ConfigError{Reason: fmt.Sprintf("invalid table name %q", table)}
+	}
+	return &SQLConversationStore{db: db, table: table, style: opts.Placeholder}, nil
+}
+
+// EnsureSchema creates the conversation table if it does not exist.
+func (s *SQLConversationStore) EnsureSchema(ctx context.Context) error {
+	stmt := "CREATE TABLE IF NOT EXISTS " + s.table + " (" +
+		"conversation_id VARCHAR(255) NOT NULL, " +
+		"seq BIGINT NOT NULL, " +
+		"item TEXT NOT NULL, " +
+		"created_at BIGINT NOT NULL, " +
+		"PRIMARY KEY (conversation_id, seq))"
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("sdk: create conversation table: %w", err)
+	}
+	return nil
+}
+
+// query rewrites "?" placeholders for the configured style.
+func (s *SQLConversationStore) query(q string) string {
+	q = strings.ReplaceAll(q, "{table}", s.table)
+	if s.style != SQLPlaceholderDollar {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Append implements ConversationStore.
+func (s *SQLConversationStore) Append(ctx context.Context, id string, items ...llm.InputItem) error {
+	if err := validateConversationID(id); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck // rollback after commit is a no-op
+	defer tx.Rollback()
+
+	var next int64
+	if err := tx.QueryRowContext(ctx, s.query("SELECT COALESCE(MAX(seq), 0) FROM {table} WHERE conversation_id = ?"), id).Scan(&next); err != nil {
+		return err
+	}
+	now := time.Now().UnixNano()
+	insert := s.query("INSERT INTO {table} (conversation_id, seq, item, created_at) VALUES (?, ?, ?, ?)")
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		next++
+		if _, err := tx.ExecContext(ctx, insert, id, next, string(raw), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Load implements ConversationStore.
+func (s *SQLConversationStore) Load(ctx context.Context, id string) ([]llm.InputItem, error) {
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT item FROM {table} WHERE conversation_id = ? ORDER BY seq"), id)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck // best-effort cleanup
+	defer rows.Close()
+
+	var items []llm.InputItem
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var item llm.InputItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			return nil, fmt.Errorf("sdk: decode conversation item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		return nil, ErrConversationNotFound
+	}
+	return items, nil
+}
+
+// List implements ConversationStore. Conversations are sorted by ID.
+func (s *SQLConversationStore) List(ctx context.Context) ([]ConversationInfo, error) {
+	rows, err := s.db.QueryContext(ctx, s.query("SELECT conversation_id, COUNT(*), MAX(created_at) FROM {table} GROUP BY conversation_id ORDER BY conversation_id"))
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck // best-effort cleanup
+	defer rows.Close()
+
+	var out []ConversationInfo
+	for rows.Next() {
+		var info ConversationInfo
+		var updated int64
+		if err := rows.Scan(&info.ID, &info.MessageCount, &updated); err != nil {
+			return nil, err
+		}
+		info.UpdatedAt = time.Unix(0, updated)
+		out = append(out, info)
+	}
+	return out, rows.Err()
+}
+
+// Delete implements ConversationStore.
+func (s *SQLConversationStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, s.query("DELETE FROM {table} WHERE conversation_id = ?"), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+// Fork implements ConversationStore.
+func (s *SQLConversationStore) Fork(ctx context.Context, id string, upTo int) (string, error) {
+	items, err := s.Load(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	newID := uuid.NewString()
+	if err := s.Append(ctx, newID, forkItems(items, upTo)...); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
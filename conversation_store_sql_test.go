@@ -0,0 +1,228 @@
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sqlConversationRow is one row of the fake conversation table.
+type sqlConversationRow struct {
+	id      string
+	seq     int64
+	item    string
+	created int64
+}
+
+// sqlConversationDB is an in-memory database/sql driver that understands the
+// statements SQLConversationStore issues, so the store can be tested without
+// a real database.
+type sqlConversationDB struct {
+	t      *testing.T
+	style  SQLPlaceholderStyle
+	mu     sync.Mutex
+	schema bool
+	rows   []sqlConversationRow
+}
+
+func (db *sqlConversationDB) Connect(context.Context) (driver.Conn, error) {
+	return &sqlConversationConn{db: db}, nil
+}
+
+func (db *sqlConversationDB) Driver() driver.Driver { return db }
+
+func (db *sqlConversationDB) Open(string) (driver.Conn, error) {
+	return &sqlConversationConn{db: db}, nil
+}
+
+type sqlConversationConn struct {
+	db       *sqlConversationDB
+	snapshot []sqlConversationRow
+	inTx     bool
+}
+
+func (c *sqlConversationConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported: %s", query)
+}
+
+func (c *sqlConversationConn) Close() error { return nil }
+
+func (c *sqlConversationConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.snapshot = append([]sqlConversationRow(nil), c.db.rows...)
+	c.inTx = true
+	return c, nil
+}
+
+func (c *sqlConversationConn) Commit() error {
+	c.inTx = false
+	c.snapshot = nil
+	return nil
+}
+
+func (c *sqlConversationConn) Rollback() error {
+	if !c.inTx {
+		return nil
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.rows = c.snapshot
+	c.inTx = false
+	c.snapshot = nil
+	return nil
+}
+
+// statement checks the placeholder style and the schema, and returns the
+// query with the table name and placeholders normalized.
+func (c *sqlConversationConn) statement(query string, args []driver.NamedValue) (string, error) {
+	for i := range args {
+		want := "?"
+		if c.db.style == SQLPlaceholderDollar {
+			want = fmt.Sprintf("$%d", i+1)
+		}
+		if !strings.Contains(query, want) {
+			c.db.t.Errorf("query %q missing placeholder %s", query, want)
+		}
+		query = strings.Replace(query, want, "?", 1)
+	}
+	query = strings.ReplaceAll(query, defaultSQLConversationTable, "t")
+	if !strings.HasPrefix(query, "CREATE TABLE") && !c.db.schema {
+		return "", fmt.Errorf("no such table: %s", defaultSQLConversationTable)
+	}
+	return query, nil
+}
+
+func (c *sqlConversationConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	query, err := c.statement(query, args)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS t "):
+		c.db.schema = true
+		return driver.RowsAffected(0), nil
+	case query == "INSERT INTO t (conversation_id, seq, item, created_at) VALUES (?, ?, ?, ?)":
+		row := sqlConversationRow{id: args[0].Value.(string), seq: args[1].Value.(int64), item: args[2].Value.(string), created: args[3].Value.(int64)}
+		for _, existing := range c.db.rows {
+			if existing.id == row.id && existing.seq == row.seq {
+				return nil, fmt.Errorf("duplicate key (%s, %d)", row.id, row.seq)
+			}
+		}
+		c.db.rows = append(c.db.rows, row)
+		return driver.RowsAffected(1), nil
+	case query == "DELETE FROM t WHERE conversation_id = ?":
+		kept := c.db.rows[:0]
+		for _, row := range c.db.rows {
+			if row.id != args[0].Value {
+				kept = append(kept, row)
+			}
+		}
+		n := len(c.db.rows) - len(kept)
+		c.db.rows = kept
+		return driver.RowsAffected(n), nil
+	}
+	return nil, fmt.Errorf("unsupported exec: %s", query)
+}
+
+func (c *sqlConversationConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	query, err := c.statement(query, args)
+	if err != nil {
+		return nil, err
+	}
+	rows := &sqlConversationRows{}
+	switch query {
+	case "SELECT COALESCE(MAX(seq), 0) FROM t WHERE conversation_id = ?":
+		var maxSeq int64
+		for _, row := range c.db.rows {
+			if row.id == args[0].Value && row.seq > maxSeq {
+				maxSeq = row.seq
+			}
+		}
+		rows.cols = []string{"max"}
+		rows.values = [][]driver.Value{{maxSeq}}
+	case "SELECT item FROM t WHERE conversation_id = ? ORDER BY seq":
+		var matched []sqlConversationRow
+		for _, row := range c.db.rows {
+			if row.id == args[0].Value {
+				matched = append(matched, row)
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool { return matched[i].seq < matched[j].seq })
+		rows.cols = []string{"item"}
+		for _, row := range matched {
+			rows.values = append(rows.values, []driver.Value{row.item})
+		}
+	case "SELECT conversation_id, COUNT(*), MAX(created_at) FROM t GROUP BY conversation_id ORDER BY conversation_id":
+		counts := map[string]int64{}
+		latest := map[string]int64{}
+		for _, row := range c.db.rows {
+			counts[row.id]++
+			latest[row.id] = max(latest[row.id], row.created)
+		}
+		ids := make([]string, 0, len(counts))
+		for id := range counts {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		rows.cols = []string{"conversation_id", "count", "max"}
+		for _, id := range ids {
+			rows.values = append(rows.values, []driver.Value{id, counts[id], latest[id]})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported query: %s", query)
+	}
+	return rows, nil
+}
+
+type sqlConversationRows struct {
+	cols   []string
+	values [][]driver.Value
+}
+
+func (r *sqlConversationRows) Columns() []string { return r.cols }
+
+func (r *sqlConversationRows) Close() error { return nil }
+
+func (r *sqlConversationRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestSQLConversationStore(t *testing.T) {
+	for name, style := range map[string]SQLPlaceholderStyle{"question": SQLPlaceholderQuestion, "dollar": SQLPlaceholderDollar} {
+		t.Run(name, func(t *testing.T) {
+			db := sql.OpenDB(&sqlConversationDB{t: t, style: style})
+			t.Cleanup(func() { _ = db.Close() })
+			store, err := NewSQLConversationStore(db, SQLConversationStoreOptions{Placeholder: style})
+			if err != nil {
+				t.Fatalf("new store: %v", err)
+			}
+			if err := store.EnsureSchema(context.Background()); err != nil {
+				t.Fatalf("ensure schema: %v", err)
+			}
+			testConversationStore(t, store)
+		})
+	}
+
+	if _, err := NewSQLConversationStore(nil, SQLConversationStoreOptions{}); err == nil {
+		t.Fatal("expected error for nil db")
+	}
+	if _, err := NewSQLConversationStore(&sql.DB{}, SQLConversationStoreOptions{Table: "items; DROP TABLE x"}); err == nil {
+		t.Fatal("expected error for invalid table name")
+	}
+}
@@ -0,0 +1,169 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+func testConversationStore(t *testing.T, store ConversationStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "missing"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected ErrConversationNotFound, got %v", err)
+	}
+
+	items := []llm.InputItem{
+		llm.NewUserText("hi"),
+		AssistantMessageWithToolCalls("", []llm.ToolCall{NewToolCall("call_1", "ls", `{}`)}),
+		llm.NewToolResultText("call_1", "a.txt"),
+	}
+	if err := store.Append(ctx, "conv-1", items[:2]...); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := store.Append(ctx, "conv-1", items[2]); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	got, err := store.Load(ctx, "conv-1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != 3 || got[1].ToolCalls[0].ID != "call_1" || got[2].ToolCallID != "call_1" {
+		t.Fatalf("unexpected items %+v", got)
+	}
+
+	forkID, err := store.Fork(ctx, "conv-1", 1)
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	forked, err := store.Load(ctx, forkID)
+	if err != nil || len(forked) != 1 || historyItemText(forked[0]) != "hi" {
+		t.Fatalf("unexpected fork %+v err=%v", forked, err)
+	}
+
+	list, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	counts := map[string]int{}
+	for _, info := range list {
+		counts[info.ID] = info.MessageCount
+	}
+	if len(counts) != 2 || counts["conv-1"] != 3 || counts[forkID] != 1 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	if err := store.Delete(ctx, "conv-1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Load(ctx, "conv-1"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected deleted conversation to be gone, got %v", err)
+	}
+	if err := store.Delete(ctx, "conv-1"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected ErrConversationNotFound on second delete, got %v", err)
+	}
+}
+
+func TestMemoryConversationStore(t *testing.T) {
+	testConversationStore(t, NewMemoryConversationStore())
+}
+
+func TestJSONLConversationStore(t *testing.T) {
+	store, err := NewJSONLConversationStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	testConversationStore(t, store)
+
+	if err := store.Append(context.Background(), "../escape", llm.NewUserText("x")); err == nil {
+		t.Fatal("expected invalid id error")
+	}
+}
+
+func TestResponsesCreateWithConversation(t *testing.T) {
+	srv, requests := newAgentJSONServer(t, []string{
+		`{"id":"resp_1","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"hello"}]}]}`,
+		`{"id":"resp_2","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"you said hi"}]}]}`,
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+	store := NewMemoryConversationStore()
+	ctx := context.Background()
+
+	for _, prompt := range []string{"hi", "what did I say?"} {
+		req, opts, err := client.Responses.New().
+			Model(NewModelID("demo")).
+			System("be brief").
+			User(prompt).
+			Conversation(store, "chat").
+			Build()
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		if _, err := client.Responses.Create(ctx, req, opts...); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	var second []llm.InputItem
+	raw, _ := json.Marshal((*requests)[1]["input"])
+	if err := json.Unmarshal(raw, &second); err != nil {
+		t.Fatalf("decode input: %v", err)
+	}
+	wantRoles := []llm.MessageRole{llm.RoleSystem, llm.RoleUser, llm.RoleAssistant, llm.RoleUser}
+	if len(second) != len(wantRoles) {
+		t.Fatalf("expected %d input items, got %s", len(wantRoles), raw)
+	}
+	for i, role := range wantRoles {
+		if second[i].Role != role {
+			t.Fatalf("item %d: expected role %s, got %s", i, role, second[i].Role)
+		}
+	}
+
+	stored, err := store.Load(ctx, "chat")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(stored) != 4 || historyItemText(stored[3]) != "you said hi" {
+		t.Fatalf("unexpected stored history %+v", stored)
+	}
+}
+
+func TestAgentWithConversationStore(t *testing.T) {
+	srv, _ := newAgentJSONServer(t, []string{
+		`{"id":"resp_1","model":"demo","output":[{"type":"message","role":"assistant","content":[],"tool_calls":[{"id":"call_1","type":"function","function":{"name":"noop","arguments":"{}"}}]}]}`,
+		`{"id":"resp_2","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"done"}]}]}`,
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+	store := NewMemoryConversationStore()
+
+	tools := NewToolBuilder().Add("noop", "No-op", nil, func(args map[string]any, call llm.ToolCall) (any, error) {
+		return "ok", nil
+	})
+	if _, err := client.Agent(context.Background(), "demo", AgentOptions{
+		Tools:          tools,
+		Prompt:         "go",
+		Conversation:   store,
+		ConversationID: "agent-1",
+	}); err != nil {
+		t.Fatalf("agent: %v", err)
+	}
+
+	stored, err := store.Load(context.Background(), "agent-1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	wantRoles := []llm.MessageRole{llm.RoleUser, llm.RoleAssistant, llm.RoleTool, llm.RoleAssistant}
+	if len(stored) != len(wantRoles) {
+		t.Fatalf("expected %d stored items, got %d", len(wantRoles), len(stored))
+	}
+	for i, role := range wantRoles {
+		if stored[i].Role != role {
+			t.Fatalf("item %d: expected role %s, got %s", i, role, stored[i].Role)
+		}
+	}
+}
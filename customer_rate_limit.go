@@ -0,0 +1,419 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/headers"
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+)
+
+// RateLimitMode selects how the customer rate limiter handles a request that
+// exceeds the customer's current budget.
+type RateLimitMode string
+
+const (
+	// RateLimitBlock waits until the budget allows the request or the context
+	// is done. This is the default.
+	RateLimitBlock RateLimitMode = "block"
+	// RateLimitFailFast rejects the request immediately with CustomerRateLimitError.
+	RateLimitFailFast RateLimitMode = "fail_fast"
+	// RateLimitQueue waits like RateLimitBlock but admits each customer's
+	// requests in arrival order, rejecting new requests once MaxQueue are waiting.
+	RateLimitQueue RateLimitMode = "queue"
+)
+
+const (
+	defaultRateLimitTokenEstimate = 1024
+	defaultRateLimitMaxQueue      = 64
+	rateLimitPruneThreshold       = 1024
+)
+
+// CustomerRateLimitConfig configures per-customer token buckets.
+//
+// Each customer gets its own budget of requests and tokens per minute. A
+// request's token cost is estimated up front from MaxOutputTokens (or
+// DefaultTokenEstimate when unset) and reconciled with the Usage returned by
+// ResponsesClient.Create, or with the last usage reported by ResponsesClient.Stream
+// and StreamJSON once the stream ends or is closed. Other requests made for a
+// customer, such as workflow runs and images, count against RequestsPerMinute
+// only.
+type CustomerRateLimitConfig struct {
+	// RequestsPerMinute caps requests per customer. Zero disables the request budget.
+	RequestsPerMinute int
+	// TokensPerMinute caps estimated tokens per customer. Zero disables the token budget.
+	TokensPerMinute int64
+	// DefaultTokenEstimate is charged for /responses requests without
+	// MaxOutputTokens. Default 1024.
+	DefaultTokenEstimate int64
+	// Mode selects blocking, fail-fast or queued admission. Default RateLimitBlock.
+	Mode RateLimitMode
+	// MaxQueue bounds waiting requests per customer in RateLimitQueue mode. Default 64.
+	MaxQueue int
+}
+
+func (c CustomerRateLimitConfig) enabled() bool {
+	return c.RequestsPerMinute > 0 || c.TokensPerMinute > 0
+}
+
+func (c CustomerRateLimitConfig) normalized() CustomerRateLimitConfig {
+	if c.DefaultTokenEstimate <= 0 {
+		c.DefaultTokenEstimate = defaultRateLimitTokenEstimate
+	}
+	if c.Mode == "" {
+		c.Mode = RateLimitBlock
+	}
+	if c.MaxQueue <= 0 {
+		c.MaxQueue = defaultRateLimitMaxQueue
+	}
+	return c
+}
+
+// WithCustomerRateLimit limits POST requests to /responses, /runs and /images
+// per customer. The customer is taken from the X-ModelRelay-Customer-Id header
+// (see WithCustomerID, ResponseBuilder.CustomerID and WithBatchCustomerID) or,
+// for clients built on a CustomerTokenProvider, from the provider's customer.
+// Requests without a customer are not limited.
+func WithCustomerRateLimit(cfg CustomerRateLimitConfig) Option {
+	return func(o *clientOptions) { o.customerRateLimit = &cfg }
+}
+
+// CustomerRateLimitError is returned when the customer rate limiter rejects a request.
+type CustomerRateLimitError struct {
+	CustomerID string
+	// RetryAfter estimates when the customer's budget allows the request again.
+	// It is zero when the request was rejected because the queue was full.
+	RetryAfter time.Duration
+	// QueueFull reports that RateLimitQueue mode already had MaxQueue requests waiting.
+	QueueFull bool
+}
+
+func (e CustomerRateLimitError) Error() string {
+	if e.QueueFull {
+		return fmt.Sprintf("sdk: rate limit queue full for customer %s", e.CustomerID)
+	}
+	return fmt.Sprintf("sdk: rate limit exceeded for customer %s (retry after %s)", e.CustomerID, e.RetryAfter)
+}
+
+// customerKeyer is implemented by token providers that are bound to a single customer.
+type customerKeyer interface {
+	rateLimitCustomer() string
+}
+
+// customerRateLimiter holds one token bucket per customer. A nil
+// *customerRateLimiter does nothing.
+type customerRateLimiter struct {
+	cfg        CustomerRateLimitConfig
+	telemetry  TelemetryHooks
+	basePath   string
+	defaultKey string
+	now        func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*customerBucket
+}
+
+type customerBucket struct {
+	requests float64
+	tokens   float64
+	updated  time.Time
+	queue    []*struct{}
+	changed  chan struct{}
+}
+
+func newCustomerRateLimiter(baseURL string, opts clientOptions) *customerRateLimiter {
+	if opts.customerRateLimit == nil || !opts.customerRateLimit.enabled() {
+		return nil
+	}
+	l := &customerRateLimiter{
+		cfg:       opts.customerRateLimit.normalized(),
+		telemetry: opts.telemetry,
+		now:       time.Now,
+		buckets:   make(map[string]*customerBucket),
+	}
+	if u, err := url.Parse(baseURL); err == nil {
+		l.basePath = strings.TrimSuffix(u.Path, "/")
+	}
+	if k, ok := opts.tokenProvider.(customerKeyer); ok {
+		l.defaultKey = k.rateLimitCustomer()
+	}
+	return l
+}
+
+// limited reports whether a request counts against the customer's budget.
+func (l *customerRateLimiter) limited(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return false
+	}
+	rel := strings.TrimPrefix(req.URL.Path, l.basePath)
+	for _, prefix := range []string{routes.Responses, routes.Runs, "/images"} {
+		if rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *customerRateLimiter) customer(req *http.Request) string {
+	if id := strings.TrimSpace(req.Header.Get(headers.CustomerID)); id != "" {
+		return id
+	}
+	return l.defaultKey
+}
+
+// admit blocks, queues or rejects req according to the configured mode. When
+// the request's context carries a rateLimitTicket, the ticket records the
+// reservation so the caller can reconcile it with actual usage.
+func (l *customerRateLimiter) admit(req *http.Request) error {
+	if l == nil || !l.limited(req) {
+		return nil
+	}
+	key := l.customer(req)
+	if key == "" {
+		return nil
+	}
+	ctx := req.Context()
+	ticket, _ := ctx.Value(rateLimitTicketKey{}).(*rateLimitTicket)
+	var tokens float64
+	if l.cfg.TokensPerMinute > 0 && ticket != nil {
+		estimate := ticket.estimate
+		if estimate <= 0 {
+			estimate = l.cfg.DefaultTokenEstimate
+		}
+		tokens = math.Min(float64(estimate), float64(l.cfg.TokensPerMinute))
+	}
+
+	start := time.Now()
+	if err := l.acquire(ctx, key, tokens); err != nil {
+		l.telemetry.metric(ctx, "sdk_customer_rate_limit_rejected", 1, map[string]string{"mode": string(l.cfg.Mode)})
+		return err
+	}
+	if waited := time.Since(start); waited > time.Millisecond {
+		l.telemetry.metric(ctx, "sdk_customer_rate_limit_wait_ms", float64(waited.Milliseconds()), map[string]string{"mode": string(l.cfg.Mode)})
+	}
+	if ticket != nil {
+		ticket.limiter = l
+		ticket.customer = key
+		ticket.reserved = tokens
+	}
+	return nil
+}
+
+func (l *customerRateLimiter) acquire(ctx context.Context, key string, tokens float64) error {
+	var waiter *struct{}
+	for {
+		l.mu.Lock()
+		b := l.bucket(key)
+		b.refill(l.now(), l.cfg)
+		if l.cfg.Mode == RateLimitQueue && waiter == nil {
+			if len(b.queue) >= l.cfg.MaxQueue {
+				l.mu.Unlock()
+				return CustomerRateLimitError{CustomerID: key, QueueFull: true}
+			}
+			waiter = new(struct{})
+			b.queue = append(b.queue, waiter)
+		}
+		head := waiter == nil || b.queue[0] == waiter
+		wait := b.wait(l.cfg, tokens)
+		if head && wait <= 0 {
+			if l.cfg.RequestsPerMinute > 0 {
+				b.requests--
+			}
+			b.tokens -= tokens
+			if waiter != nil {
+				b.queue = b.queue[1:]
+				b.notify()
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		if l.cfg.Mode == RateLimitFailFast {
+			l.mu.Unlock()
+			return CustomerRateLimitError{CustomerID: key, RetryAfter: wait}
+		}
+		changed := b.changed
+		l.mu.Unlock()
+
+		var timer *time.Timer
+		var fired <-chan time.Time
+		if head {
+			timer = time.NewTimer(wait)
+			fired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			if waiter != nil {
+				l.dequeue(key, waiter)
+			}
+			return ctx.Err()
+		case <-changed:
+		case <-fired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (l *customerRateLimiter) dequeue(key string, waiter *struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	for i, w := range b.queue {
+		if w == waiter {
+			b.queue = append(b.queue[:i], b.queue[i+1:]...)
+			break
+		}
+	}
+	b.notify()
+}
+
+// bucket returns key's bucket, creating a full one if needed. Callers hold l.mu.
+func (l *customerRateLimiter) bucket(key string) *customerBucket {
+	if b, ok := l.buckets[key]; ok {
+		return b
+	}
+	now := l.now()
+	if len(l.buckets) >= rateLimitPruneThreshold {
+		for k, b := range l.buckets {
+			b.refill(now, l.cfg)
+			if len(b.queue) == 0 && b.full(l.cfg) {
+				delete(l.buckets, k)
+			}
+		}
+	}
+	b := &customerBucket{
+		requests: float64(l.cfg.RequestsPerMinute),
+		tokens:   float64(l.cfg.TokensPerMinute),
+		updated:  now,
+		changed:  make(chan struct{}),
+	}
+	l.buckets[key] = b
+	return b
+}
+
+// refund returns reserved-minus-actual tokens to key's bucket. A negative
+// refund leaves the bucket in debt until it refills.
+func (l *customerRateLimiter) refund(key string, tokens float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		return
+	}
+	b.refill(l.now(), l.cfg)
+	b.tokens = math.Min(float64(l.cfg.TokensPerMinute), b.tokens+tokens)
+	b.notify()
+}
+
+func (b *customerBucket) refill(now time.Time, cfg CustomerRateLimitConfig) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+	b.updated = now
+	minutes := elapsed.Minutes()
+	b.requests = math.Min(float64(cfg.RequestsPerMinute), b.requests+minutes*float64(cfg.RequestsPerMinute))
+	b.tokens = math.Min(float64(cfg.TokensPerMinute), b.tokens+minutes*float64(cfg.TokensPerMinute))
+}
+
+// wait returns how long until the bucket can cover one request and tokens.
+func (b *customerBucket) wait(cfg CustomerRateLimitConfig, tokens float64) time.Duration {
+	var wait time.Duration
+	if cfg.RequestsPerMinute > 0 && b.requests < 1 {
+		wait = time.Duration((1 - b.requests) / float64(cfg.RequestsPerMinute) * float64(time.Minute))
+	}
+	if cfg.TokensPerMinute > 0 && b.tokens < tokens {
+		wait = max(wait, time.Duration((tokens-b.tokens)/float64(cfg.TokensPerMinute)*float64(time.Minute)))
+	}
+	if wait > 0 && wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	return wait
+}
+
+func (b *customerBucket) full(cfg CustomerRateLimitConfig) bool {
+	return b.requests >= float64(cfg.RequestsPerMinute) && b.tokens >= float64(cfg.TokensPerMinute)
+}
+
+func (b *customerBucket) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+type rateLimitTicketKey struct{}
+
+// rateLimitTicket carries a request's token estimate to the limiter and the
+// resulting reservation back to the caller for reconciliation.
+type rateLimitTicket struct {
+	estimate int64
+
+	limiter  *customerRateLimiter
+	customer string
+	reserved float64
+}
+
+// withRateLimitTicket attaches a token estimate to ctx when customer rate
+// limiting is enabled. The returned ticket is nil otherwise.
+func (c *Client) withRateLimitTicket(ctx context.Context, estimate int64) (context.Context, *rateLimitTicket) {
+	if c == nil || c.rateLimit == nil || c.rateLimit.cfg.TokensPerMinute <= 0 {
+		return ctx, nil
+	}
+	t := &rateLimitTicket{estimate: estimate}
+	return context.WithValue(ctx, rateLimitTicketKey{}, t), t
+}
+
+// reconcile replaces the reserved estimate with the actual token usage.
+func (t *rateLimitTicket) reconcile(actual int64) {
+	if t == nil || t.limiter == nil {
+		return
+	}
+	t.limiter.refund(t.customer, t.reserved-float64(actual))
+	t.limiter = nil
+}
+
+// rateLimitStream reconciles ticket against the last usage reported by
+// stream once it ends or is closed, whichever comes first.
+func rateLimitStream(ticket *rateLimitTicket, stream streamReader) streamReader {
+	if ticket == nil {
+		return stream
+	}
+	return &rateLimitStreamReader{streamReader: stream, ticket: ticket}
+}
+
+type rateLimitStreamReader struct {
+	streamReader
+
+	mu     sync.Mutex
+	ticket *rateLimitTicket
+	usage  int64
+}
+
+func (r *rateLimitStreamReader) Next() (StreamEvent, bool, error) {
+	ev, ok, err := r.streamReader.Next()
+	r.mu.Lock()
+	if ok && ev.Usage != nil {
+		r.usage = ev.Usage.Total()
+	}
+	if err != nil || !ok {
+		r.ticket.reconcile(r.usage)
+	}
+	r.mu.Unlock()
+	return ev, ok, err
+}
+
+func (r *rateLimitStreamReader) Close() error {
+	r.mu.Lock()
+	r.ticket.reconcile(r.usage)
+	r.mu.Unlock()
+	return r.streamReader.Close()
+}
@@ -0,0 +1,209 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRateLimitedClient(t *testing.T, cfg CustomerRateLimitConfig, totalTokens int) (*Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","output":[],"model":"demo","usage":{"input_tokens":1,"output_tokens":1,"total_tokens":` + strconv.Itoa(totalTokens) + `}}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL+"/api/v1"),
+		WithRetryConfig(RetryConfig{MaxAttempts: 1}),
+		WithCustomerRateLimit(cfg),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	return client, &calls
+}
+
+func TestCustomerRateLimitFailFastIsPerCustomer(t *testing.T) {
+	client, calls := newRateLimitedClient(t, CustomerRateLimitConfig{RequestsPerMinute: 1, Mode: RateLimitFailFast}, 10)
+	ctx := context.Background()
+
+	reqA, optsA, _ := client.Responses.New().CustomerID("cust_a").User("hi").Build()
+	if _, err := client.Responses.Create(ctx, reqA, optsA...); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	_, err := client.Responses.Create(ctx, reqA, optsA...)
+	var rlErr CustomerRateLimitError
+	if !errors.As(err, &rlErr) || rlErr.CustomerID != "cust_a" || rlErr.RetryAfter <= 0 {
+		t.Fatalf("expected rate limit error for cust_a, got %v", err)
+	}
+
+	reqB, optsB, _ := client.Responses.New().CustomerID("cust_b").User("hi").Build()
+	if _, err := client.Responses.Create(ctx, reqB, optsB...); err != nil {
+		t.Fatalf("cust_b should have its own budget: %v", err)
+	}
+
+	// Requests without a customer are not limited.
+	plain, plainOpts, _ := client.Responses.New().Model(NewModelID("demo")).User("hi").Build()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Responses.Create(ctx, plain, plainOpts...); err != nil {
+			t.Fatalf("unattributed request %d: %v", i, err)
+		}
+	}
+	if calls.Load() != 5 {
+		t.Fatalf("expected rejected request not to reach the server, got %d calls", calls.Load())
+	}
+}
+
+func TestCustomerRateLimitReconcilesTokens(t *testing.T) {
+	client, _ := newRateLimitedClient(t, CustomerRateLimitConfig{TokensPerMinute: 1000, Mode: RateLimitFailFast}, 10)
+	ctx := context.Background()
+
+	req, opts, _ := client.Responses.New().CustomerID("cust_a").User("hi").MaxOutputTokens(900).Build()
+	// Each call reserves 900 tokens but only 10 are used, so the refund keeps
+	// the bucket from running dry.
+	for i := 0; i < 5; i++ {
+		if _, err := client.Responses.Create(ctx, req, opts...); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+}
+
+func TestCustomerRateLimitReconcilesStreams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"type":"start","request_id":"resp_1","model":"demo"}` + "\n"))
+		_, _ = w.Write([]byte(`{"type":"completion","content":"Hi","usage":{"input_tokens":1,"output_tokens":9,"total_tokens":10}}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL),
+		WithRetryConfig(RetryConfig{MaxAttempts: 1}),
+		WithCustomerRateLimit(CustomerRateLimitConfig{TokensPerMinute: 1000, Mode: RateLimitFailFast}),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	ctx := context.Background()
+
+	req, opts, _ := client.Responses.New().CustomerID("cust_a").User("hi").MaxOutputTokens(900).Build()
+	// Collected and abandoned streams both give back the unused reservation.
+	for i := 0; i < 5; i++ {
+		stream, err := client.Responses.Stream(ctx, req, opts...)
+		if err != nil {
+			t.Fatalf("stream %d: %v", i, err)
+		}
+		if i%2 == 0 {
+			_, err = stream.Collect(ctx)
+		} else {
+			err = stream.Close()
+		}
+		if err != nil {
+			t.Fatalf("stream %d: %v", i, err)
+		}
+	}
+}
+
+func TestCustomerRateLimitReconcilesStructuredStreams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"type":"start","request_id":"resp_1","model":"demo"}` + "\n"))
+		_, _ = w.Write([]byte(`{"type":"completion","payload":{"name":"Ada"},"usage":{"input_tokens":100,"output_tokens":500,"total_tokens":600}}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL),
+		WithRetryConfig(RetryConfig{MaxAttempts: 1}),
+		WithCustomerRateLimit(CustomerRateLimitConfig{TokensPerMinute: 1000, Mode: RateLimitFailFast}),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	ctx := context.Background()
+
+	type person struct {
+		Name string `json:"name"`
+	}
+	format, err := OutputFormatFromType[person]("person")
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	req, opts, _ := client.Responses.New().CustomerID("cust_a").User("hi").OutputFormat(*format).MaxOutputTokens(500).Build()
+	stream, err := StreamJSON[person](ctx, client.Responses, req, opts...)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if _, err := stream.Collect(ctx); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	// The 500-token reservation is reconciled to the 600 tokens reported, so
+	// the remaining 400 cannot cover a second reservation.
+	var rlErr CustomerRateLimitError
+	if _, err := StreamJSON[person](ctx, client.Responses, req, opts...); !errors.As(err, &rlErr) {
+		t.Fatalf("expected structured stream to be charged, got %v", err)
+	}
+}
+
+func TestCustomerRateLimitTokenBudget(t *testing.T) {
+	client, _ := newRateLimitedClient(t, CustomerRateLimitConfig{TokensPerMinute: 1000, Mode: RateLimitFailFast}, 900)
+	ctx := context.Background()
+
+	req, opts, _ := client.Responses.New().CustomerID("cust_a").User("hi").MaxOutputTokens(500).Build()
+	if _, err := client.Responses.Create(ctx, req, opts...); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	var rlErr CustomerRateLimitError
+	if _, err := client.Responses.Create(ctx, req, opts...); !errors.As(err, &rlErr) {
+		t.Fatalf("expected token budget to be exhausted, got %v", err)
+	}
+}
+
+func TestCustomerRateLimiterQueue(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := &customerRateLimiter{
+		cfg:     CustomerRateLimitConfig{RequestsPerMinute: 60, Mode: RateLimitQueue, MaxQueue: 1}.normalized(),
+		now:     func() time.Time { return now },
+		buckets: make(map[string]*customerBucket),
+	}
+	for i := 0; i < 60; i++ {
+		if err := l.acquire(context.Background(), "cust", 0); err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error, 1)
+	go func() { waiting <- l.acquire(ctx, "cust", 0) }()
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.mu.Lock()
+		queued := len(l.buckets["cust"].queue)
+		l.mu.Unlock()
+		if queued == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("waiter never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var rlErr CustomerRateLimitError
+	if err := l.acquire(context.Background(), "cust", 0); !errors.As(err, &rlErr) || !rlErr.QueueFull {
+		t.Fatalf("expected queue full error, got %v", err)
+	}
+	cancel()
+	if err := <-waiting; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled waiter, got %v", err)
+	}
+	if n := len(l.buckets["cust"].queue); n != 0 {
+		t.Fatalf("expected canceled waiter to leave the queue, got %d", n)
+	}
+}
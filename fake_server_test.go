@@ -0,0 +1,155 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/testutil"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+func TestFakeServerResponsesAndSessions(t *testing.T) {
+	fake := testutil.NewFakeServer()
+	t.Cleanup(fake.Close)
+	client := newTestClient(t, fake.Server, "mr_sk_test")
+	ctx := context.Background()
+
+	text, err := client.Responses.Text(ctx, NewModelID("demo"), "sys", "hello there")
+	if err != nil {
+		t.Fatalf("text: %v", err)
+	}
+	if text != "echo: hello there" {
+		t.Fatalf("unexpected text %q", text)
+	}
+
+	req, opts, _ := client.Responses.New().Model(NewModelID("demo")).User("streamed reply").Build()
+	stream, err := client.Responses.Stream(ctx, req, opts...)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	resp, err := stream.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if resp.AssistantText() != "echo: streamed reply" {
+		t.Fatalf("unexpected streamed text %q", resp.AssistantText())
+	}
+
+	fake.SetModel(testutil.ScriptedModel(testutil.FakeModelResponse{Err: &testutil.FakeError{Status: 429, Code: "RATE_LIMIT", Message: "slow down"}}))
+	_, err = client.Responses.Create(ctx, req, append(opts, WithRetry(RetryConfig{MaxAttempts: 1}))...)
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != 429 {
+		t.Fatalf("expected scripted 429, got %v", err)
+	}
+
+	session, err := client.Sessions.Create(ctx, SessionCreateRequest{})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if _, err := client.Sessions.AddMessage(ctx, session.Id, SessionMessageCreateRequest{
+		Role:    "user",
+		Content: []map[string]any{{"type": "text", "text": "hi"}},
+	}); err != nil {
+		t.Fatalf("add message: %v", err)
+	}
+	got, err := client.Sessions.Get(ctx, session.Id)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if len(got.Messages) != 1 || got.MessageCount != 1 {
+		t.Fatalf("unexpected session: %+v", got)
+	}
+	if err := client.Sessions.Delete(ctx, session.Id); err != nil {
+		t.Fatalf("delete session: %v", err)
+	}
+	if _, err := client.Sessions.Get(ctx, session.Id); !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestFakeServerRunWithClientTools(t *testing.T) {
+	fake := testutil.NewFakeServer(testutil.WithFakeModel(testutil.ScriptedModel(
+		testutil.FakeModelResponse{ToolCalls: []llm.ToolCall{llm.NewToolCall("call_1", "lookup", `{"q":"weather"}`)}},
+		testutil.FakeModelResponse{Text: "sunny"},
+	)))
+	t.Cleanup(fake.Close)
+	client := newTestClient(t, fake.Server, "mr_sk_test")
+	ctx := context.Background()
+
+	spec := WorkflowSpec{
+		Kind:  workflowintent.KindWorkflow,
+		Model: "demo",
+		Nodes: []workflowintent.Node{{
+			ID:            "ask",
+			Type:          workflowintent.NodeTypeLLM,
+			User:          "What is the weather in {{city}}?",
+			Tools:         []workflowintent.ToolRef{{Tool: llm.Tool{Type: llm.ToolTypeFunction, Function: &llm.FunctionTool{Name: "lookup"}}}},
+			ToolExecution: &workflowintent.ToolExecution{Mode: workflowintent.ToolExecutionModeClient},
+		}},
+		Outputs: []workflowintent.OutputRef{{Name: "answer", From: "ask", Pointer: "/output/0/content/0/text"}},
+	}
+	created, err := client.Runs.Create(ctx, spec, WithRunInputs(map[string]any{"city": "Oslo"}))
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if created.Status != RunStatusWaiting {
+		t.Fatalf("expected waiting run, got %s", created.Status)
+	}
+
+	pending, err := client.Runs.PendingTools(ctx, created.RunID)
+	if err != nil {
+		t.Fatalf("pending tools: %v", err)
+	}
+	if len(pending.Pending) != 1 || len(pending.Pending[0].ToolCalls) != 1 {
+		t.Fatalf("unexpected pending tools: %+v", pending)
+	}
+	node := pending.Pending[0]
+	call := node.ToolCalls[0].ToolCall
+	if _, err := client.Runs.SubmitToolResults(ctx, created.RunID, RunsToolResultsRequest{
+		NodeID:    node.NodeID,
+		Step:      node.Step,
+		RequestID: node.RequestID,
+		Results:   []RunsToolResultItemV0{{ToolCall: ToolCall{ID: call.ID, Name: call.Name}, Output: `{"forecast":"sunny"}`}},
+	}); err != nil {
+		t.Fatalf("submit tool results: %v", err)
+	}
+
+	run, err := client.Runs.Get(ctx, created.RunID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	var answer string
+	if err := json.Unmarshal(run.Outputs["answer"], &answer); err != nil || answer != "sunny" {
+		t.Fatalf("unexpected outputs %s (%v)", run.Outputs["answer"], err)
+	}
+	if run.Status != RunStatusSucceeded {
+		t.Fatalf("expected succeeded run, got %s", run.Status)
+	}
+
+	events, err := client.Runs.ListEvents(ctx, created.RunID)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if _, ok := events[len(events)-1].(RunEventRunCompletedV0); !ok {
+		t.Fatalf("expected run_completed as last event, got %T", events[len(events)-1])
+	}
+}
+
+func TestFakeServerCompileValidation(t *testing.T) {
+	fake := testutil.NewFakeServer()
+	t.Cleanup(fake.Close)
+	client := newTestClient(t, fake.Server, "mr_sk_test")
+
+	_, err := client.Workflows.Compile(context.Background(), WorkflowSpec{
+		Kind:    workflowintent.KindWorkflow,
+		Nodes:   []workflowintent.Node{{ID: "a", Type: workflowintent.NodeTypeLLM, User: "hi", DependsOn: []string{"missing"}}},
+		Outputs: []workflowintent.OutputRef{{Name: "out", From: "a"}},
+	})
+	var verr WorkflowValidationError
+	if !errors.As(err, &verr) || verr.Issues[0].Code != "unknown_dependency" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
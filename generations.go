@@ -0,0 +1,378 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+)
+
+// ModelGeneration is the latest clock generation published for a model.
+type ModelGeneration struct {
+	Model      ModelID   `json:"model"`
+	Generation int64     `json:"generation"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+// generationsLatestResponse wraps the latest generations response.
+type generationsLatestResponse struct {
+	Generations []ModelGeneration `json:"generations"`
+}
+
+// GenerationEvent is a single server-sent event from the generations feed.
+type GenerationEvent struct {
+	// ID is the SSE event id, replayed as Last-Event-ID on reconnect.
+	ID string
+	// Event is the SSE event name ("message" when the server omits it).
+	Event      string
+	Generation ModelGeneration
+}
+
+// GenerationsClient reads model clock generations.
+type GenerationsClient struct {
+	client *Client
+}
+
+// Latest returns the latest clock generation for each model.
+func (c *GenerationsClient) Latest(ctx context.Context) ([]ModelGeneration, error) {
+	var resp generationsLatestResponse
+	if err := c.client.sendAndDecode(ctx, http.MethodGet, routes.GenerationsLatest, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Generations, nil
+}
+
+type generationsSubscribeOptions struct {
+	retry       *RetryConfig
+	lastEventID string
+}
+
+// GenerationsSubscribeOption configures Subscribe.
+type GenerationsSubscribeOption func(*generationsSubscribeOptions)
+
+// WithGenerationsRetry overrides the client RetryConfig for reconnects.
+// MaxAttempts bounds consecutive failed connection attempts; the count resets
+// whenever an event is received.
+func WithGenerationsRetry(cfg RetryConfig) GenerationsSubscribeOption {
+	return func(o *generationsSubscribeOptions) { o.retry = &cfg }
+}
+
+// WithGenerationsLastEventID resumes the feed after a previously seen event id.
+func WithGenerationsLastEventID(id string) GenerationsSubscribeOption {
+	return func(o *generationsSubscribeOptions) { o.lastEventID = strings.TrimSpace(id) }
+}
+
+// Subscribe opens the generations SSE feed. The returned subscription
+// reconnects transparently when the connection drops, sending Last-Event-ID
+// and backing off per RetryConfig. Connection state is reported through
+// TelemetryHooks as sdk_generations_sse_* metrics and log entries.
+//
+// Example:
+//
+//	sub, err := client.Generations.Subscribe(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer sub.Close()
+//	for {
+//		ev, ok, err := sub.Next()
+//		if err != nil || !ok {
+//			return err
+//		}
+//		fmt.Println(ev.Generation.Model, ev.Generation.Generation)
+//	}
+func (c *GenerationsClient) Subscribe(ctx context.Context, opts ...GenerationsSubscribeOption) (*GenerationsSubscription, error) {
+	var options generationsSubscribeOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	cfg := c.client.retryCfg
+	if options.retry != nil {
+		cfg = options.retry.normalized()
+	}
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := &GenerationsSubscription{
+		client:      c.client,
+		ctx:         subCtx,
+		cancel:      cancel,
+		cfg:         cfg,
+		lastEventID: options.lastEventID,
+	}
+	if err := sub.ensureConnected(); err != nil {
+		cancel()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// GenerationsSubscription is a live, auto-reconnecting generations feed.
+// Next must not be called concurrently; Close may be called from any goroutine.
+type GenerationsSubscription struct {
+	client *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    RetryConfig
+
+	mu     sync.Mutex
+	body   io.ReadCloser
+	closed bool
+
+	reader      *bufio.Reader
+	lastEventID string
+	retryHint   time.Duration
+	failures    int
+	lastErr     error
+	connects    int
+}
+
+// LastEventID returns the id of the most recent event received.
+func (s *GenerationsSubscription) LastEventID() string {
+	return s.lastEventID
+}
+
+// Close stops the subscription and releases the connection.
+func (s *GenerationsSubscription) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	if s.body != nil {
+		err := s.body.Close()
+		s.body = nil
+		return err
+	}
+	return nil
+}
+
+// Next blocks until the next generation event. It returns ok=false with a nil
+// error after Close, and an error when the context ends or reconnect attempts
+// are exhausted.
+func (s *GenerationsSubscription) Next() (GenerationEvent, bool, error) {
+	for {
+		if s.isClosed() {
+			return GenerationEvent{}, false, nil
+		}
+		if err := s.ensureConnected(); err != nil {
+			if s.isClosed() {
+				return GenerationEvent{}, false, nil
+			}
+			return GenerationEvent{}, false, err
+		}
+		ev, ok, err := s.readEvent()
+		if err != nil {
+			if s.isClosed() {
+				return GenerationEvent{}, false, nil
+			}
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return GenerationEvent{}, false, ctxErr
+			}
+			s.disconnect("read_error", err)
+			continue
+		}
+		if !ok {
+			s.disconnect("eof", nil)
+			continue
+		}
+		s.failures, s.lastErr = 0, nil
+		s.client.telemetry.metric(s.ctx, "sdk_generations_sse_events", 1, map[string]string{"event": ev.Event})
+		return ev, true, nil
+	}
+}
+
+func (s *GenerationsSubscription) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// ensureConnected opens the stream if needed, retrying with backoff. Failed
+// connection attempts and connections that end without delivering an event
+// both count toward RetryConfig.MaxAttempts.
+func (s *GenerationsSubscription) ensureConnected() error {
+	s.mu.Lock()
+	connected := s.body != nil
+	s.mu.Unlock()
+	if connected {
+		return nil
+	}
+	for {
+		if s.failures >= s.cfg.MaxAttempts {
+			if s.lastErr != nil {
+				return s.lastErr
+			}
+			return TransportError{Kind: TransportErrorEmptyResponse, Message: "generations feed closed without events"}
+		}
+		if s.connects > 0 || s.failures > 0 {
+			delay := s.cfg.backoffDelay(s.failures + 2)
+			if s.retryHint > delay {
+				delay = s.retryHint
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return s.ctx.Err()
+			case <-timer.C:
+			}
+		}
+		err := s.connect()
+		if err == nil {
+			return nil
+		}
+		s.failures++
+		s.lastErr = err
+		if !retryableStreamError(err) || s.ctx.Err() != nil {
+			return err
+		}
+		s.client.telemetry.log(s.ctx, LogLevelError, "sdk_generations_sse_connect_failed", map[string]any{
+			"attempt":      s.failures,
+			"max_attempts": s.cfg.MaxAttempts,
+			"error":        err.Error(),
+		})
+	}
+}
+
+func (s *GenerationsSubscription) connect() error {
+	req, err := s.client.newJSONRequest(s.ctx, http.MethodGet, routes.GenerationsSSE, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if s.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+	resp, _, err := s.client.sendStreaming(req, &RetryConfig{MaxAttempts: 1})
+	if err != nil {
+		return err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "text/event-stream") {
+		//nolint:errcheck // best-effort cleanup on protocol violation
+		_ = resp.Body.Close()
+		return StreamProtocolError{
+			ExpectedContentType: "text/event-stream",
+			ReceivedContentType: contentType,
+			Status:              resp.StatusCode,
+		}
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		//nolint:errcheck // best-effort cleanup after Close
+		_ = resp.Body.Close()
+		return context.Canceled
+	}
+	s.body = resp.Body
+	s.mu.Unlock()
+	s.reader = bufio.NewReader(resp.Body)
+
+	labels := map[string]string{"reconnect": strconv.FormatBool(s.connects > 0)}
+	if s.connects > 0 {
+		s.client.telemetry.metric(s.ctx, "sdk_generations_sse_reconnects", 1, nil)
+	}
+	s.connects++
+	s.client.telemetry.metric(s.ctx, "sdk_generations_sse_connected", 1, labels)
+	return nil
+}
+
+func (s *GenerationsSubscription) disconnect(reason string, cause error) {
+	s.failures++
+	s.lastErr = cause
+	s.mu.Lock()
+	if s.body != nil {
+		//nolint:errcheck // best-effort cleanup before reconnect
+		_ = s.body.Close()
+		s.body = nil
+	}
+	s.mu.Unlock()
+	s.client.telemetry.metric(s.ctx, "sdk_generations_sse_connected", 0, map[string]string{"reason": reason})
+	fields := map[string]any{"reason": reason, "last_event_id": s.lastEventID}
+	if cause != nil {
+		fields["error"] = cause.Error()
+	}
+	s.client.telemetry.log(s.ctx, LogLevelInfo, "sdk_generations_sse_disconnected", fields)
+}
+
+// readEvent reads one dispatched SSE event, skipping comments and events
+// without data. It returns ok=false when the stream ends.
+func (s *GenerationsSubscription) readEvent() (GenerationEvent, bool, error) {
+	var (
+		id, event string
+		data      []string
+		hasID     bool
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			// An event not terminated by a blank line is incomplete and discarded.
+			if errors.Is(err, io.EOF) {
+				return GenerationEvent{}, false, nil
+			}
+			return GenerationEvent{}, false, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if hasID {
+				s.lastEventID = id
+			}
+			if len(data) > 0 {
+				ev := GenerationEvent{ID: s.lastEventID, Event: event}
+				if ev.Event == "" {
+					ev.Event = "message"
+				}
+				if decodeErr := json.Unmarshal([]byte(strings.Join(data, "\n")), &ev.Generation); decodeErr != nil {
+					return GenerationEvent{}, false, ProtocolError{Message: "invalid generation event: " + decodeErr.Error()}
+				}
+				return ev, true, nil
+			}
+			id, event, data, hasID = "", "", nil, false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id, hasID = value, true
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, convErr := strconv.Atoi(value); convErr == nil && ms >= 0 {
+				s.retryHint = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// retryableStreamError reports whether a stream connection failure should be
+// retried: transport errors, 429 and 5xx responses.
+func retryableStreamError(err error) bool {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusTooManyRequests || apiErr.Status >= 500
+	}
+	var protoErr StreamProtocolError
+	if errors.As(err, &protoErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
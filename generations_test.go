@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+)
+
+func TestGenerationsLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != routes.GenerationsLatest {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"generations":[{"model":"demo","generation":7,"updated_at":"2025-01-02T03:04:05Z"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	gens, err := client.Generations.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("latest: %v", err)
+	}
+	if len(gens) != 1 || gens[0].Model != NewModelID("demo") || gens[0].Generation != 7 {
+		t.Fatalf("unexpected generations: %+v", gens)
+	}
+}
+
+func TestGenerationsSubscribeReconnectsWithLastEventID(t *testing.T) {
+	var conns atomic.Int32
+	var lastEventIDs sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != routes.GenerationsSSE || r.Header.Get("Accept") != "text/event-stream" {
+			t.Fatalf("unexpected request %s accept=%q", r.URL.Path, r.Header.Get("Accept"))
+		}
+		n := conns.Add(1)
+		lastEventIDs.Store(n, r.Header.Get("Last-Event-ID"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch n {
+		case 1:
+			_, _ = fmt.Fprint(w, ": keepalive\n\nretry: 1\n\n")
+			_, _ = fmt.Fprint(w, "id: 1\nevent: generation\ndata: {\"model\":\"a\",\"generation\":1}\n\n")
+			_, _ = fmt.Fprint(w, "id: 2\ndata: {\"model\":\"b\",\n")
+			_, _ = fmt.Fprint(w, "data: \"generation\":2}\n\n")
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = fmt.Fprint(w, "id: 3\nevent: generation\ndata: {\"model\":\"a\",\"generation\":3}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	var mu sync.Mutex
+	metrics := map[string]float64{}
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL),
+		WithTelemetry(TelemetryHooks{OnMetric: func(_ context.Context, m Metric) {
+			mu.Lock()
+			metrics[m.Name] += m.Value
+			mu.Unlock()
+		}}),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub, err := client.Generations.Subscribe(ctx, WithGenerationsRetry(RetryConfig{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer func() { _ = sub.Close() }()
+
+	want := []GenerationEvent{
+		{ID: "1", Event: "generation", Generation: ModelGeneration{Model: "a", Generation: 1}},
+		{ID: "2", Event: "message", Generation: ModelGeneration{Model: "b", Generation: 2}},
+		{ID: "3", Event: "generation", Generation: ModelGeneration{Model: "a", Generation: 3}},
+	}
+	for i, w := range want {
+		ev, ok, err := sub.Next()
+		if err != nil || !ok {
+			t.Fatalf("next %d: ok=%v err=%v", i, ok, err)
+		}
+		if ev != w {
+			t.Fatalf("event %d: got %+v want %+v", i, ev, w)
+		}
+	}
+	if id, _ := lastEventIDs.Load(int32(3)); id != "2" {
+		t.Fatalf("expected reconnect with Last-Event-ID 2, got %v", id)
+	}
+	mu.Lock()
+	reconnects, events := metrics["sdk_generations_sse_reconnects"], metrics["sdk_generations_sse_events"]
+	mu.Unlock()
+	if reconnects != 1 || events != 3 {
+		t.Fatalf("unexpected metrics: reconnects=%v events=%v", reconnects, events)
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, ok, err := sub.Next(); ok || err != nil {
+		t.Fatalf("expected clean end after Close, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestGenerationsSubscribeDoesNotRetryClientErrors(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conns.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"unauthorized","code":"UNAUTHORIZED","message":"bad key"}`))
+	}))
+	t.Cleanup(srv.Close)
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	_, err := client.Generations.Subscribe(context.Background(), WithGenerationsRetry(RetryConfig{MaxAttempts: 5, BaseBackoff: time.Millisecond}))
+	if apiErr, ok := err.(APIError); !ok || !apiErr.IsUnauthorized() {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if conns.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", conns.Load())
+	}
+}
@@ -9,6 +9,7 @@ require (
 	github.com/oapi-codegen/runtime v1.1.2
 	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	go.opentelemetry.io/otel/trace v1.39.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
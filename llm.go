@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -39,19 +40,57 @@ func (c *ResponsesClient) Create(ctx context.Context, req ResponseRequest, optio
 	if requireModel && c.client != nil && c.client.hasJWTAccessToken() {
 		requireModel = false
 	}
+	if callOpts.router != nil {
+		requireModel = false
+	}
 	if err := req.validate(requireModel); err != nil {
 		return nil, err
 	}
+	newInput := req.input
+	if conv := callOpts.conversation; conv != nil {
+		history, err := conv.store.Load(ctx, conv.id)
+		if err != nil && !errors.Is(err, ErrConversationNotFound) {
+			return nil, err
+		}
+		req.input = conversationInput(conversationItems(history), req.input)
+	}
+
+	respPayload, err := routeResponse(ctx, c.client, callOpts.router, req, func(req ResponseRequest) (*Response, error) {
+		return c.create(ctx, req, callOpts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if conv := callOpts.conversation; conv != nil {
+		items := conversationItems(newInput)
+		if out := responseAssistantItem(respPayload); out != nil {
+			items = append(items, *out)
+		}
+		if err := conv.store.Append(ctx, conv.id, items...); err != nil {
+			return respPayload, fmt.Errorf("sdk: record conversation: %w", err)
+		}
+	}
+	return respPayload, nil
+}
 
+// create sends a single /responses request.
+func (c *ResponsesClient) create(ctx context.Context, req ResponseRequest, callOpts responseCallOptions) (*Response, error) {
+	cacheKey, cached := c.client.lookupCachedResponse(ctx, callOpts, req)
+	if cached != nil {
+		return cached, nil
+	}
 	reqPayload := newResponseRequestPayload(req)
+	ctx, ticket := c.client.withRateLimitTicket(ctx, req.maxOutputTokens)
 	httpReq, err := c.client.newJSONRequest(ctx, http.MethodPost, routes.Responses, reqPayload)
 	if err != nil {
+		ticket.reconcile(0)
 		return nil, err
 	}
 	httpReq.Header.Set("Accept", "application/json")
 	applyResponseHeaders(httpReq, callOpts)
 	resp, retryMeta, err := c.client.send(httpReq, callOpts.timeout, callOpts.retry)
 	if err != nil {
+		ticket.reconcile(0)
 		c.client.telemetry.log(ctx, LogLevelError, "responses_create_failed", map[string]any{"error": err.Error(), "retries": retryMeta})
 		return nil, err
 	}
@@ -59,9 +98,12 @@ func (c *ResponsesClient) Create(ctx context.Context, req ResponseRequest, optio
 	defer func() { _ = resp.Body.Close() }()
 	var respPayload Response
 	if err := json.NewDecoder(resp.Body).Decode(&respPayload); err != nil {
+		ticket.reconcile(0)
 		return nil, err
 	}
+	ticket.reconcile(respPayload.Usage.Total())
 	respPayload.RequestID = requestIDFromHeaders(resp.Header)
+	c.client.storeCachedResponse(ctx, callOpts.cache, cacheKey, &respPayload)
 	return &respPayload, nil
 }
 
@@ -234,13 +276,32 @@ func (c *ResponsesClient) Stream(ctx context.Context, req ResponseRequest, optio
 	if requireModel && c.client != nil && c.client.hasJWTAccessToken() {
 		requireModel = false
 	}
+	if callOpts.router != nil {
+		requireModel = false
+	}
 	if err := req.validate(requireModel); err != nil {
 		return nil, err
 	}
+	if callOpts.conversation != nil {
+		return nil, ConfigError{Reason: "conversation stores are not supported for streaming; use Create"}
+	}
+
+	return routeResponse(ctx, c.client, callOpts.router, req, func(req ResponseRequest) (*StreamHandle, error) {
+		return c.stream(ctx, req, callOpts)
+	})
+}
 
+// stream opens a single /responses stream.
+func (c *ResponsesClient) stream(ctx context.Context, req ResponseRequest, callOpts responseCallOptions) (*StreamHandle, error) {
+	cacheKey, cached := c.client.lookupCachedResponse(ctx, callOpts, req)
+	if cached != nil {
+		return &StreamHandle{stream: &mockStreamReader{events: cachedResponseEvents(cached)}, startedAt: time.Now()}, nil
+	}
 	payload := newResponseRequestPayload(req)
-	httpReq, err := c.client.newJSONRequest(ctx, http.MethodPost, routes.Responses, payload)
+	streamCtx, ticket := c.client.withRateLimitTicket(ctx, req.maxOutputTokens)
+	httpReq, err := c.client.newJSONRequest(streamCtx, http.MethodPost, routes.Responses, payload)
 	if err != nil {
+		ticket.reconcile(0)
 		return nil, err
 	}
 	// All streaming uses unified NDJSON format
@@ -250,12 +311,14 @@ func (c *ResponsesClient) Stream(ctx context.Context, req ResponseRequest, optio
 	//nolint:bodyclose // resp.Body is transferred to stream and will be closed by stream.Close()
 	resp, _, err := c.client.sendStreaming(httpReq, callOpts.retry)
 	if err != nil {
+		ticket.reconcile(0)
 		return nil, err
 	}
 	contentType := resp.Header.Get("Content-Type")
 	if !isNDJSONContentType(contentType) {
 		//nolint:errcheck // best-effort cleanup on protocol violation
 		_ = resp.Body.Close()
+		ticket.reconcile(0)
 		return nil, StreamProtocolError{
 			ExpectedContentType: "application/x-ndjson",
 			ReceivedContentType: contentType,
@@ -264,9 +327,9 @@ func (c *ResponsesClient) Stream(ctx context.Context, req ResponseRequest, optio
 	}
 	requestID := requestIDFromHeaders(resp.Header)
 	reqCtx := newRequestContext(httpReq.Method, httpReq.URL.Path, req.model, requestID)
-	stream := newNDJSONStream(ctx, resp.Body, c.client.telemetry, startedAt, reqCtx, callOpts.stream)
+	stream := rateLimitStream(ticket, newNDJSONStream(ctx, resp.Body, c.client.telemetry, startedAt, reqCtx, callOpts.stream))
 	return &StreamHandle{
-		stream:    stream,
+		stream:    c.client.cachingStream(ctx, callOpts.cache, cacheKey, stream),
 		RequestID: requestID,
 		startedAt: startedAt,
 	}, nil
@@ -291,13 +354,25 @@ func StreamJSON[T any](ctx context.Context, c *ResponsesClient, req ResponseRequ
 	if requireModel && c.client != nil && c.client.hasJWTAccessToken() {
 		requireModel = false
 	}
+	if callOpts.router != nil {
+		requireModel = false
+	}
 	if err := req.validate(requireModel); err != nil {
 		return nil, err
 	}
 
+	return routeResponse(ctx, c.client, callOpts.router, req, func(req ResponseRequest) (*StructuredJSONStream[T], error) {
+		return streamJSON[T](ctx, c, req, callOpts)
+	})
+}
+
+// streamJSON opens a single structured /responses stream.
+func streamJSON[T any](ctx context.Context, c *ResponsesClient, req ResponseRequest, callOpts responseCallOptions) (*StructuredJSONStream[T], error) {
 	payload := newResponseRequestPayload(req)
-	httpReq, err := c.client.newJSONRequest(ctx, http.MethodPost, routes.Responses, payload)
+	streamCtx, ticket := c.client.withRateLimitTicket(ctx, req.maxOutputTokens)
+	httpReq, err := c.client.newJSONRequest(streamCtx, http.MethodPost, routes.Responses, payload)
 	if err != nil {
+		ticket.reconcile(0)
 		return nil, err
 	}
 	httpReq.Header.Set("Accept", responsesStreamAccept)
@@ -306,6 +381,7 @@ func StreamJSON[T any](ctx context.Context, c *ResponsesClient, req ResponseRequ
 	//nolint:bodyclose // resp.Body is owned by the StructuredJSONStream
 	resp, retryMeta, err := c.client.sendStreaming(httpReq, callOpts.retry)
 	if err != nil {
+		ticket.reconcile(0)
 		return nil, err
 	}
 	contentType := resp.Header.Get("Content-Type")
@@ -313,6 +389,7 @@ func StreamJSON[T any](ctx context.Context, c *ResponsesClient, req ResponseRequ
 		// Best-effort cleanup before returning a typed transport error.
 		//nolint:errcheck // best-effort cleanup on protocol violation
 		_ = resp.Body.Close()
+		ticket.reconcile(0)
 		return nil, StreamProtocolError{
 			ExpectedContentType: "application/x-ndjson",
 			ReceivedContentType: contentType,
@@ -320,13 +397,15 @@ func StreamJSON[T any](ctx context.Context, c *ResponsesClient, req ResponseRequ
 		}
 	}
 
-	return newStructuredJSONStream[T](
+	stream := newStructuredJSONStream[T](
 		ctx,
 		resp.Body,
 		requestIDFromHeaders(resp.Header),
 		retryMeta,
 		callOpts.stream,
-	), nil
+	)
+	stream.trackRateLimit(ticket)
+	return stream, nil
 }
 
 type responseRequestPayload struct {
@@ -20,6 +20,7 @@ const (
 	localBashDefaultTimeout        time.Duration = 10 * time.Second
 	localBashDefaultMaxOutputBytes uint64        = 32_000
 	localBashHardMaxOutputBytes    uint64        = 256_000
+	localBashWaitDelay             time.Duration = time.Second
 )
 
 // BashResult is the structured result for the tools.v0 `bash` tool.
@@ -249,7 +250,8 @@ func (p *LocalBashToolPack) RegisterInto(registry *ToolRegistry) *ToolRegistry {
 	if registry == nil {
 		return nil
 	}
-	registry.Register(ToolNameBash, p.bashTool)
+	registry.RegisterContext(ToolNameBash, p.bashTool)
+	registry.MarkSerial(ToolNameBash)
 	return registry
 }
 
@@ -277,7 +279,7 @@ func (p *LocalBashToolPack) ensureReady() error {
 	return nil
 }
 
-func (p *LocalBashToolPack) bashTool(_ map[string]any, call llm.ToolCall) (any, error) {
+func (p *LocalBashToolPack) bashTool(parent context.Context, _ map[string]any, call llm.ToolCall) (any, error) {
 	if err := p.ensureReady(); err != nil {
 		return nil, err
 	}
@@ -292,7 +294,7 @@ func (p *LocalBashToolPack) bashTool(_ map[string]any, call llm.ToolCall) (any,
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.timeout)
+	ctx, cancel := context.WithTimeout(parent, p.cfg.timeout)
 	defer cancel()
 
 	var truncated bool
@@ -307,8 +309,14 @@ func (p *LocalBashToolPack) bashTool(_ map[string]any, call llm.ToolCall) (any,
 	cmd.Env = p.buildEnv()
 	cmd.Stdout = w
 	cmd.Stderr = w
+	// Don't block on grandchildren holding the output pipes open after cancellation.
+	cmd.WaitDelay = localBashWaitDelay
 
 	err := cmd.Run()
+	if parentErr := parent.Err(); parentErr != nil {
+		// The caller canceled (or its deadline passed); the subprocess was killed.
+		return nil, parentErr
+	}
 
 	outStr := w.String()
 	res := BashResult{
@@ -1,6 +1,8 @@
 package sdk
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -121,3 +123,24 @@ func TestLocalBashTools_WorkingDirAndEnvPolicy(t *testing.T) {
 		}
 	})
 }
+
+func TestLocalBashTools_ContextCancellation(t *testing.T) {
+	root := t.TempDir()
+	reg := NewLocalBashTools(
+		root,
+		WithLocalBashAllowAllCommands(),
+		WithLocalBashTimeout(10*time.Second),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	res := reg.ExecuteContext(ctx, toolCallJSON("bash", map[string]any{"command": "sleep 5"}))
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("expected cancellation to stop command promptly, took %s", elapsed)
+	}
+	if !errors.Is(res.Error, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got result=%+v err=%v", res.Result, res.Error)
+	}
+}
@@ -209,8 +209,9 @@ func (p *LocalFSToolPack) RegisterInto(registry *ToolRegistry) *ToolRegistry {
 	}
 	registry.Register(ToolNameFSReadFile, p.readFileTool)
 	registry.Register(ToolNameFSListFiles, p.listFilesTool)
-	registry.Register(ToolNameFSSearch, p.searchTool)
+	registry.RegisterContext(ToolNameFSSearch, p.searchTool)
 	registry.Register(ToolNameFSEdit, p.editTool)
+	registry.MarkSerial(ToolNameFSEdit)
 	return registry
 }
 
@@ -501,7 +502,7 @@ func (p *LocalFSToolPack) listFilesTool(_ map[string]any, call llm.ToolCall) (an
 	return strings.Join(out, "\n"), nil
 }
 
-func (p *LocalFSToolPack) searchTool(_ map[string]any, call llm.ToolCall) (any, error) {
+func (p *LocalFSToolPack) searchTool(ctx context.Context, _ map[string]any, call llm.ToolCall) (any, error) {
 	if err := p.ensureReady(); err != nil {
 		return nil, err
 	}
@@ -533,9 +534,9 @@ func (p *LocalFSToolPack) searchTool(_ map[string]any, call llm.ToolCall) (any,
 	}
 
 	if rg, ok := p.rgBinary(); ok {
-		return p.searchWithRipgrep(rg, args.Query, dirAbs, maxMatches)
+		return p.searchWithRipgrep(ctx, rg, args.Query, dirAbs, maxMatches)
 	}
-	return p.searchWithGo(args.Query, dirAbs, maxMatches)
+	return p.searchWithGo(ctx, args.Query, dirAbs, maxMatches)
 }
 
 func (p *LocalFSToolPack) editTool(_ map[string]any, call llm.ToolCall) (any, error) {
@@ -617,12 +618,12 @@ func (p *LocalFSToolPack) rgBinary() (string, bool) {
 	return p.rgPath, true
 }
 
-func (p *LocalFSToolPack) searchWithRipgrep(rgPath, query string, dirAbs string, maxMatches uint64) (any, error) {
+func (p *LocalFSToolPack) searchWithRipgrep(parent context.Context, rgPath, query string, dirAbs string, maxMatches uint64) (any, error) {
 	timeout := p.cfg.searchTimeout
 	if timeout <= 0 {
 		timeout = localFSDefaultSearchTimeout
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 
 	args := []string{
@@ -681,6 +682,9 @@ func (p *LocalFSToolPack) searchWithRipgrep(rgPath, query string, dirAbs string,
 		// We canceled the process intentionally after reaching cap.
 		return strings.Join(lines, "\n"), nil
 	}
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
 	if scanErr != nil {
 		return nil, fmt.Errorf("fs_search: rg scan: %w", scanErr)
 	}
@@ -734,7 +738,7 @@ func (p *LocalFSToolPack) normalizeRipgrepLine(line string) string {
 	return line
 }
 
-func (p *LocalFSToolPack) searchWithGo(query string, dirAbs string, maxMatches uint64) (any, error) {
+func (p *LocalFSToolPack) searchWithGo(ctx context.Context, query string, dirAbs string, maxMatches uint64) (any, error) {
 	re, err := regexp.Compile(query)
 	if err != nil {
 		return nil, &ToolArgsError{Message: "invalid query regex: " + err.Error()}
@@ -752,6 +756,9 @@ func (p *LocalFSToolPack) searchWithGo(query string, dirAbs string, maxMatches u
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if time.Now().After(deadline) {
 			return context.DeadlineExceeded
 		}
@@ -822,6 +829,9 @@ func (p *LocalFSToolPack) searchWithGo(query string, dirAbs string, maxMatches u
 		if errors.As(walkErr, &stop) {
 			return strings.Join(out, "\n"), nil
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if errors.Is(walkErr, context.DeadlineExceeded) {
 			return nil, fmt.Errorf("fs_search: timed out after %s", timeout)
 		}
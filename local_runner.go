@@ -0,0 +1,1398 @@
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+// ResponsesCreator creates model responses for llm nodes executed by a
+// LocalRunner. *ResponsesClient and *MockResponsesClient implement it.
+type ResponsesCreator interface {
+	Create(ctx context.Context, req ResponseRequest, opts ...ResponseOption) (*Response, error)
+}
+
+// ResponsesCreatorFunc adapts a function to ResponsesCreator.
+type ResponsesCreatorFunc func(ctx context.Context, req ResponseRequest) (*Response, error)
+
+// Create implements ResponsesCreator.
+func (f ResponsesCreatorFunc) Create(ctx context.Context, req ResponseRequest, _ ...ResponseOption) (*Response, error) {
+	return f(ctx, req)
+}
+
+const defaultLocalMaxToolSteps = 8
+
+type localRunnerOptions struct {
+	tools        *ToolRegistry
+	maxToolSteps int
+}
+
+// LocalRunnerOption configures a LocalRunner.
+type LocalRunnerOption func(*localRunnerOptions)
+
+// WithLocalRunnerTools executes tool calls made by llm nodes against
+// registry. Without a registry, a node whose model calls a tool fails.
+func WithLocalRunnerTools(registry *ToolRegistry) LocalRunnerOption {
+	return func(o *localRunnerOptions) { o.tools = registry }
+}
+
+// WithLocalRunnerMaxToolSteps bounds the model calls made by one llm node
+// while it executes tools (default 8).
+func WithLocalRunnerMaxToolSteps(n int) LocalRunnerOption {
+	return func(o *localRunnerOptions) { o.maxToolSteps = n }
+}
+
+type localRunOptions struct {
+	inputs        map[string]any
+	modelOverride string
+	handler       RunEventHandler
+}
+
+// LocalRunOption configures a single local run.
+type LocalRunOption func(*localRunOptions)
+
+// WithLocalRunInputs provides runtime inputs, as WithRunInputs does for
+// server runs. Declared defaults fill in missing inputs.
+func WithLocalRunInputs(inputs map[string]any) LocalRunOption {
+	return func(o *localRunOptions) { o.inputs = inputs }
+}
+
+// WithLocalRunModelOverride uses model for every llm node.
+func WithLocalRunModelOverride(model string) LocalRunOption {
+	return func(o *localRunOptions) { o.modelOverride = strings.TrimSpace(model) }
+}
+
+// WithLocalRunEventHandler receives each run event as it is emitted. A
+// handler error aborts the run and is returned from Run.
+func WithLocalRunEventHandler(handler RunEventHandler) LocalRunOption {
+	return func(o *localRunOptions) { o.handler = handler }
+}
+
+// LocalRunner executes workflow specs in-process, for dry runs and tests.
+//
+// It walks the DAG honoring MaxParallelism, calls a ResponsesCreator for
+// llm, llm.responses, route.switch and map.fanout subnodes, and evaluates
+// joins, transform.json nodes and edge conditions locally. Runs emit the same
+// event envelopes as RunsClient.StreamEvents, so existing consumers work
+// unchanged; node_output and run_completed events carry artifact metadata
+// only, and the payloads are returned by Run.
+//
+// Local semantics differ from the server in a few places:
+//   - Conditions on the output of an llm node are evaluated against the
+//     assistant text parsed as JSON, or the text itself when it is not JSON.
+//   - A node whose incoming conditional edges are not taken is skipped along
+//     with everything that requires it; join.any and join.collect run when
+//     at least one upstream node ran.
+//   - map.fanout nodes only support llm subnodes and output the array of
+//     subnode responses in item order. Intent subnodes receive the item via
+//     the {{item}} placeholder.
+//   - No costs are priced; CostSummary only counts requests and tokens.
+//
+// Example:
+//
+//	runner := sdk.NewLocalRunner(client.Responses)
+//	run, err := runner.Run(ctx, spec, sdk.WithLocalRunInputs(map[string]any{"topic": "tides"}))
+type LocalRunner struct {
+	responses ResponsesCreator
+	opts      localRunnerOptions
+}
+
+// NewLocalRunner creates a runner that calls responses for llm nodes.
+func NewLocalRunner(responses ResponsesCreator, opts ...LocalRunnerOption) *LocalRunner {
+	options := localRunnerOptions{maxToolSteps: defaultLocalMaxToolSteps}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	if options.maxToolSteps < 1 {
+		options.maxToolSteps = 1
+	}
+	return &LocalRunner{responses: responses, opts: options}
+}
+
+// Run executes an intent spec to completion. The spec is checked with
+// ValidateWorkflow first. A node failure is reported through the returned
+// status and node results, not as an error.
+func (r *LocalRunner) Run(ctx context.Context, spec WorkflowSpec, opts ...LocalRunOption) (*RunsGetResponse, error) {
+	plan, err := localPlanFromIntent(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.run(ctx, plan, opts)
+}
+
+// RunV1 is Run for workflow.v1 specs.
+func (r *LocalRunner) RunV1(ctx context.Context, spec workflow.SpecV1, opts ...LocalRunOption) (*RunsGetResponse, error) {
+	plan, err := localPlanFromV1(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.run(ctx, plan, opts)
+}
+
+// StreamEvents executes an intent spec in the background and returns its
+// events as a RunsEventStream. Closing the stream aborts the run.
+func (r *LocalRunner) StreamEvents(ctx context.Context, spec WorkflowSpec, opts ...LocalRunOption) (*RunsEventStream, error) {
+	plan, err := localPlanFromIntent(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.stream(ctx, plan, opts)
+}
+
+// StreamEventsV1 is StreamEvents for workflow.v1 specs.
+func (r *LocalRunner) StreamEventsV1(ctx context.Context, spec workflow.SpecV1, opts ...LocalRunOption) (*RunsEventStream, error) {
+	plan, err := localPlanFromV1(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.stream(ctx, plan, opts)
+}
+
+func (r *LocalRunner) run(ctx context.Context, plan *localPlan, opts []LocalRunOption) (*RunsGetResponse, error) {
+	x, err := r.newExec(plan, opts)
+	if err != nil {
+		return nil, err
+	}
+	handler := x.opts.handler
+	return x.execute(ctx, func(ctx context.Context, env RunEventEnvelope) error {
+		if handler == nil {
+			return nil
+		}
+		ev, err := decodeRunEvent(env)
+		if err != nil {
+			return err
+		}
+		return handler(ctx, ev)
+	})
+}
+
+func (r *LocalRunner) stream(ctx context.Context, plan *localPlan, opts []LocalRunOption) (*RunsEventStream, error) {
+	x, err := r.newExec(plan, opts)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	enc := json.NewEncoder(pw)
+	go func() {
+		_, err := x.execute(ctx, func(_ context.Context, env RunEventEnvelope) error {
+			return enc.Encode(env)
+		})
+		_ = pw.CloseWithError(err)
+	}()
+	return &RunsEventStream{body: pr, dec: json.NewDecoder(pr)}, nil
+}
+
+func (r *LocalRunner) newExec(plan *localPlan, opts []LocalRunOption) (*localExec, error) {
+	if r == nil || r.responses == nil {
+		return nil, ConfigError{Reason: "local runner requires a ResponsesCreator"}
+	}
+	var options localRunOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	inputs := make(map[string]any, len(options.inputs)+len(plan.inputs))
+	for name, v := range options.inputs {
+		decoded, err := localDecode(v)
+		if err != nil {
+			return nil, ConfigError{Reason: "input " + name + ": " + err.Error()}
+		}
+		inputs[name] = decoded
+	}
+	for _, decl := range plan.inputs {
+		if _, ok := inputs[decl.name]; ok {
+			continue
+		}
+		switch {
+		case len(decl.def) > 0:
+			var v any
+			if err := json.Unmarshal(decl.def, &v); err != nil {
+				return nil, ConfigError{Reason: "input " + decl.name + ": invalid default: " + err.Error()}
+			}
+			inputs[decl.name] = v
+		case decl.required:
+			return nil, ConfigError{Reason: "input " + decl.name + " is required"}
+		}
+	}
+	return &localExec{
+		runner:  r,
+		plan:    plan,
+		opts:    options,
+		runID:   NewRunID(),
+		inputs:  inputs,
+		values:  make(map[string]any),
+		texts:   make(map[string]string),
+		results: make(map[string]*NodeResult),
+		cost:    make(map[string]*workflow.CostLineItemV0),
+	}, nil
+}
+
+// Plans.
+
+type localPlan struct {
+	hash        PlanHash
+	inputs      []localInputDecl
+	nodes       []*localNode
+	outputs     []localOutput
+	parallelism int
+	nodeTimeout time.Duration
+	runTimeout  time.Duration
+}
+
+type localInputDecl struct {
+	name     string
+	required bool
+	def      json.RawMessage
+}
+
+type localOutput struct {
+	name    string
+	from    string
+	pointer string
+}
+
+type localEdge struct {
+	from string
+	when *workflow.ConditionV1
+}
+
+type localRef struct {
+	from    string
+	input   string
+	pointer string
+}
+
+// localNode is the executable form shared by intent and v1 nodes.
+type localNode struct {
+	id  string
+	typ workflow.NodeTypeV1
+	in  []localEdge
+
+	// llm nodes: request is the /responses payload as decoded JSON, bindings
+	// are applied to it before each call and intent nodes substitute
+	// {{placeholders}} from inputs and upstream nodes.
+	request  map[string]any
+	bindings []workflow.LLMResponsesBindingV1
+	intent   bool
+
+	predicate *workflow.ConditionV1
+	limit     *int64
+
+	object map[string]localRef
+	merge  []localRef
+
+	items        localRef
+	itemsPath    string
+	itemBindings []workflow.MapFanoutItemBindingV1
+	fanoutLimit  int
+	sub          *localNode
+}
+
+func localPlanHash(spec any) (PlanHash, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return PlanHash(hex.EncodeToString(sum[:])), nil
+}
+
+func localParallelism(n *int64, fallback int) int {
+	if n != nil && *n > 0 {
+		return int(*n)
+	}
+	if fallback < 1 {
+		return 1
+	}
+	return fallback
+}
+
+func localPlanFromIntent(spec WorkflowSpec) (*localPlan, error) {
+	if err := ValidateWorkflow(spec); err != nil {
+		return nil, err
+	}
+	hash, err := localPlanHash(spec)
+	if err != nil {
+		return nil, err
+	}
+	plan := &localPlan{hash: hash, parallelism: localParallelism(spec.MaxParallelism, len(spec.Nodes))}
+	for _, in := range spec.Inputs {
+		plan.inputs = append(plan.inputs, localInputDecl{name: in.Name, required: in.Required, def: in.Default})
+	}
+	types := make(map[string]workflowintent.NodeType, len(spec.Nodes))
+	for _, n := range spec.Nodes {
+		types[n.ID] = n.Type
+	}
+	for _, n := range spec.Nodes {
+		node, err := localIntentNode(spec, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range n.DependsOn {
+			node.in = append(node.in, localEdge{from: dep})
+		}
+		if n.Type == workflowintent.NodeTypeMapFanout {
+			node.items = localRef{from: n.ItemsFrom, input: n.ItemsFromInput, pointer: n.ItemsPointer}
+			if node.items.from != "" && node.items.pointer == "" && types[node.items.from] == workflowintent.NodeTypeLLM {
+				node.items.pointer = string(LLMTextOutputPointer)
+			}
+			node.itemsPath = n.ItemsPath
+			node.fanoutLimit = localParallelism(n.MaxParallelism, plan.parallelism)
+			sub, err := localIntentNode(spec, *n.SubNode)
+			if err != nil {
+				return nil, err
+			}
+			node.sub = sub
+		}
+		plan.nodes = append(plan.nodes, node)
+	}
+	for _, o := range spec.Outputs {
+		plan.outputs = append(plan.outputs, localOutput{name: o.Name, from: o.From, pointer: o.Pointer})
+	}
+	return plan, nil
+}
+
+func localIntentNode(spec WorkflowSpec, n workflowintent.Node) (*localNode, error) {
+	node := &localNode{id: n.ID, typ: workflow.NodeTypeV1(n.Type), limit: n.Limit}
+	if p := n.Predicate; p != nil {
+		node.predicate = &workflow.ConditionV1{
+			Source: workflow.ConditionSourceV1(p.Source),
+			Op:     workflow.ConditionOpV1(p.Op),
+			Path:   workflow.JSONPath(p.Path),
+			Value:  p.Value,
+		}
+	}
+	if len(n.Object) > 0 {
+		node.object = make(map[string]localRef, len(n.Object))
+		for key, v := range n.Object {
+			node.object[key] = localRef{from: v.From, pointer: v.Pointer}
+		}
+	}
+	for _, v := range n.Merge {
+		node.merge = append(node.merge, localRef{from: v.From, pointer: v.Pointer})
+	}
+	if n.Type != workflowintent.NodeTypeLLM {
+		return node, nil
+	}
+
+	node.typ = workflow.NodeTypeV1LLMResponses
+	node.intent = true
+	model := n.Model
+	if model == "" {
+		model = spec.Model
+	}
+	payload := responseRequestPayload{Model: model, Input: n.Input, OutputFormat: n.OutputFormat, Stop: n.Stop}
+	if len(payload.Input) == 0 {
+		if n.System != "" {
+			payload.Input = append(payload.Input, llm.NewSystemText(n.System))
+		}
+		payload.Input = append(payload.Input, llm.NewUserText(n.User))
+	}
+	if n.MaxOutputTokens != nil {
+		payload.MaxOutputTokens = *n.MaxOutputTokens
+	}
+	for _, ref := range n.Tools {
+		payload.Tools = append(payload.Tools, ref.Tool)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &node.request); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// localLLMInputV1 is the input of llm.responses and route.switch nodes.
+type localLLMInputV1 struct {
+	Request  map[string]any                   `json:"request"`
+	Bindings []workflow.LLMResponsesBindingV1 `json:"bindings,omitempty"`
+}
+
+func localPlanFromV1(spec workflow.SpecV1) (*localPlan, error) {
+	if err := ValidateWorkflowV1(spec); err != nil {
+		return nil, err
+	}
+	hash, err := localPlanHash(spec)
+	if err != nil {
+		return nil, err
+	}
+	plan := &localPlan{hash: hash, parallelism: len(spec.Nodes)}
+	if exec := spec.Execution; exec != nil {
+		plan.parallelism = localParallelism(exec.MaxParallelism, len(spec.Nodes))
+		if exec.NodeTimeoutMS != nil {
+			plan.nodeTimeout = time.Duration(*exec.NodeTimeoutMS) * time.Millisecond
+		}
+		if exec.RunTimeoutMS != nil {
+			plan.runTimeout = time.Duration(*exec.RunTimeoutMS) * time.Millisecond
+		}
+	}
+	for _, in := range spec.Inputs {
+		plan.inputs = append(plan.inputs, localInputDecl{name: string(in.Name), required: in.Required, def: in.Default})
+	}
+	byID := make(map[NodeID]*localNode, len(spec.Nodes))
+	for _, n := range spec.Nodes {
+		node, err := localV1Node(n.ID, n.Type, n.Input, plan.parallelism)
+		if err != nil {
+			return nil, err
+		}
+		byID[n.ID] = node
+		plan.nodes = append(plan.nodes, node)
+	}
+	for _, e := range spec.Edges {
+		byID[e.To].in = append(byID[e.To].in, localEdge{from: string(e.From), when: e.When})
+	}
+	for _, o := range spec.Outputs {
+		plan.outputs = append(plan.outputs, localOutput{name: string(o.Name), from: string(o.From), pointer: string(o.Pointer)})
+	}
+	return plan, nil
+}
+
+func localV1Node(id NodeID, typ workflow.NodeTypeV1, raw json.RawMessage, parallelism int) (*localNode, error) {
+	node := &localNode{id: string(id), typ: typ}
+	decode := func(v any) error {
+		if len(raw) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("node %s: invalid input: %w", id, err)
+		}
+		return nil
+	}
+	switch typ {
+	case workflow.NodeTypeV1LLMResponses, workflow.NodeTypeV1RouteSwitch:
+		var in localLLMInputV1
+		if err := decode(&in); err != nil {
+			return nil, err
+		}
+		node.request, node.bindings = in.Request, in.Bindings
+		if node.request == nil {
+			node.request = map[string]any{}
+		}
+	case workflow.NodeTypeV1JoinAny:
+		var in workflow.JoinAnyNodeInputV1
+		if err := decode(&in); err != nil {
+			return nil, err
+		}
+		node.predicate = in.Predicate
+	case workflow.NodeTypeV1JoinCollect:
+		var in workflow.JoinCollectNodeInputV1
+		if err := decode(&in); err != nil {
+			return nil, err
+		}
+		node.predicate, node.limit = in.Predicate, in.Limit
+	case workflow.NodeTypeV1TransformJSON:
+		var in workflowV1NodeInput
+		if err := decode(&in); err != nil {
+			return nil, err
+		}
+		if len(in.Object) > 0 {
+			node.object = make(map[string]localRef, len(in.Object))
+			for key, v := range in.Object {
+				node.object[key] = localRef{from: string(v.From), pointer: string(v.Pointer)}
+			}
+		}
+		for _, v := range in.Merge {
+			node.merge = append(node.merge, localRef{from: string(v.From), pointer: string(v.Pointer)})
+		}
+	case workflow.NodeTypeV1MapFanout:
+		var in workflow.MapFanoutNodeInputV1
+		if err := decode(&in); err != nil {
+			return nil, err
+		}
+		node.items = localRef{from: string(in.Items.From), input: string(in.Items.FromInput), pointer: string(in.Items.Pointer)}
+		node.itemsPath = string(in.Items.Path)
+		node.itemBindings = in.ItemBindings
+		node.fanoutLimit = localParallelism(in.MaxParallelism, parallelism)
+		sub, err := localV1Node(in.SubNode.ID, in.SubNode.Type, in.SubNode.Input, parallelism)
+		if err != nil {
+			return nil, err
+		}
+		node.sub = sub
+	}
+	return node, nil
+}
+
+// Execution.
+
+type localExec struct {
+	runner *LocalRunner
+	plan   *localPlan
+	opts   localRunOptions
+	runID  RunID
+	inputs map[string]any
+
+	// emitMu serializes sink calls so events arrive in seq order; mu guards
+	// the execution state and is not held while the sink runs.
+	emitMu  sync.Mutex
+	mu      sync.Mutex
+	seq     int64
+	ctx     context.Context
+	sink    func(context.Context, RunEventEnvelope) error
+	sinkErr error
+	cancel  context.CancelFunc
+	values  map[string]any
+	texts   map[string]string
+	results map[string]*NodeResult
+	cost    map[string]*workflow.CostLineItemV0
+}
+
+type localNodeDone struct {
+	node  *localNode
+	value any
+	text  string
+	err   *NodeError
+}
+
+func (x *localExec) execute(parent context.Context, sink func(context.Context, RunEventEnvelope) error) (*RunsGetResponse, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	if x.plan.runTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, x.plan.runTimeout)
+		defer cancelTimeout()
+	}
+	x.ctx, x.sink, x.cancel = parent, sink, cancel
+
+	x.emitRun(RunEventRunCompiled, nil)
+	x.emitRun(RunEventRunStarted, nil)
+
+	// finished holds nodes that succeeded (true) or were skipped (false).
+	finished := make(map[string]bool, len(x.plan.nodes))
+	pending := append([]*localNode(nil), x.plan.nodes...)
+	sem := make(chan struct{}, x.plan.parallelism)
+	done := make(chan localNodeDone)
+	running := 0
+	var failure *NodeError
+
+	for {
+		for progressed := failure == nil; progressed; {
+			progressed = false
+			next := pending[:0]
+			for _, n := range pending {
+				run, ready := x.ready(n, finished)
+				switch {
+				case !ready:
+					next = append(next, n)
+				case !run:
+					finished[n.id] = false
+					progressed = true
+				default:
+					running++
+					go func(n *localNode) {
+						select {
+						case sem <- struct{}{}:
+						case <-ctx.Done():
+							done <- localNodeDone{node: n, err: &NodeError{Code: "canceled", Message: ctx.Err().Error()}}
+							return
+						}
+						defer func() { <-sem }()
+						done <- x.node(ctx, n)
+					}(n)
+				}
+			}
+			pending = next
+		}
+		if running == 0 {
+			break
+		}
+		d := <-done
+		running--
+		switch {
+		case failure != nil:
+			x.abandon(d.node.id)
+		case d.err != nil:
+			if err := parent.Err(); err != nil {
+				d.err = &NodeError{Code: "canceled", Message: err.Error()}
+			}
+			failure = d.err
+			x.fail(d.node.id, d.err)
+			cancel()
+		default:
+			finished[d.node.id] = true
+			x.succeed(d.node, d.value, d.text)
+		}
+	}
+
+	out := x.response()
+	if failure == nil {
+		x.complete(out, finished)
+	} else if failure.Code == "canceled" {
+		out.Status = workflow.StatusCanceled
+	}
+	x.mu.Lock()
+	sinkErr := x.sinkErr
+	x.mu.Unlock()
+	if sinkErr != nil {
+		return nil, sinkErr
+	}
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ready reports whether every upstream node of n has finished and, if so,
+// whether n runs or is skipped.
+func (x *localExec) ready(n *localNode, finished map[string]bool) (run, ready bool) {
+	active := 0
+	for _, e := range n.in {
+		ran, ok := finished[e.from]
+		if !ok {
+			return false, false
+		}
+		if ran && (e.when == nil || x.condition(e.when, e.from)) {
+			active++
+		}
+	}
+	switch {
+	case len(n.in) == 0:
+		return true, true
+	case n.typ == workflow.NodeTypeV1JoinAny || n.typ == workflow.NodeTypeV1JoinCollect:
+		return active > 0, true
+	default:
+		return active == len(n.in), true
+	}
+}
+
+func (x *localExec) node(ctx context.Context, n *localNode) localNodeDone {
+	if x.plan.nodeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, x.plan.nodeTimeout)
+		defer cancel()
+	}
+	x.mu.Lock()
+	x.results[n.id] = &NodeResult{ID: NodeID(n.id), Type: n.typ, Status: workflow.NodeStatusRunning, StartedAt: time.Now().UTC()}
+	x.mu.Unlock()
+	x.emitNode(RunEventNodeStarted, n.id, nil)
+
+	switch n.typ {
+	case workflow.NodeTypeV1LLMResponses, workflow.NodeTypeV1RouteSwitch:
+		req, err := x.request(n, nil)
+		if err != nil {
+			return localNodeDone{node: n, err: &NodeError{Code: "invalid_request", Message: err.Error()}}
+		}
+		resp, nodeErr := x.callLLM(ctx, n.id, req, 1)
+		if nodeErr != nil {
+			return localNodeDone{node: n, err: nodeErr}
+		}
+		value, err := localDecode(resp)
+		if err != nil {
+			return localNodeDone{node: n, err: &NodeError{Code: "invalid_response", Message: err.Error()}}
+		}
+		return localNodeDone{node: n, value: value, text: resp.AssistantText()}
+	case workflow.NodeTypeV1MapFanout:
+		value, nodeErr := x.fanout(ctx, n)
+		return localNodeDone{node: n, value: value, err: nodeErr}
+	default:
+		value, err := x.eval(ctx, n)
+		if err != nil {
+			return localNodeDone{node: n, err: &NodeError{Code: "evaluation_failed", Message: err.Error()}}
+		}
+		return localNodeDone{node: n, value: value}
+	}
+}
+
+// callLLM performs the model calls of one llm node, executing tool calls
+// between steps.
+func (x *localExec) callLLM(ctx context.Context, nodeID string, req ResponseRequest, step int64) (*Response, *NodeError) {
+	for limit := step + int64(x.runner.opts.maxToolSteps); ; step++ {
+		requestID := uuid.NewString()
+		resp, err := x.runner.responses.Create(ctx, req)
+		if err != nil {
+			nodeErr := &NodeError{Code: "llm_error", Message: err.Error()}
+			var apiErr APIError
+			if errors.As(err, &apiErr) && apiErr.Code != "" {
+				nodeErr.Code = string(apiErr.Code)
+			}
+			return nil, nodeErr
+		}
+		x.record(req, resp)
+		x.emitNode(RunEventNodeLLMCall, nodeID, func(env *RunEventEnvelope) {
+			env.LLMCall = &NodeLLMCall{
+				Step:       step,
+				RequestID:  requestID,
+				Provider:   resp.Provider,
+				Model:      resp.Model.String(),
+				ResponseID: resp.ID,
+				StopReason: string(resp.StopReason),
+				Usage:      workflow.TokenUsage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens, TotalTokens: resp.Usage.Total()},
+			}
+		})
+		calls := resp.ToolCalls()
+		if len(calls) == 0 {
+			return resp, nil
+		}
+		if x.runner.opts.tools == nil {
+			return nil, &NodeError{Code: "tool_execution_unavailable", Message: "model called tools but the local runner has no ToolRegistry"}
+		}
+		if step+1 >= limit {
+			return nil, &NodeError{Code: "max_tool_steps", Message: fmt.Sprintf("exceeded %d tool steps", x.runner.opts.maxToolSteps)}
+		}
+		req.input = append(req.input, AssistantMessageWithToolCalls(resp.AssistantText(), calls))
+		for _, call := range calls {
+			args := ToolCallWithArguments{ID: call.ID, Arguments: "{}"}
+			if call.Function != nil {
+				args.Name = call.Function.Name
+				if call.Function.Arguments != "" {
+					args.Arguments = call.Function.Arguments
+				}
+			}
+			x.emitNode(RunEventNodeToolCall, nodeID, func(env *RunEventEnvelope) {
+				env.ToolCall = &NodeToolCall{Step: step, RequestID: requestID, ToolCall: args}
+			})
+			res := x.runner.opts.tools.ExecuteContext(ctx, call)
+			output := toolExecutionOutput(res)
+			x.emitNode(RunEventNodeToolResult, nodeID, func(env *RunEventEnvelope) {
+				env.ToolResult = &NodeToolResult{Step: step, RequestID: requestID, ToolCall: ToolCall{ID: args.ID, Name: args.Name}, Output: output}
+				if res.Error != nil {
+					env.ToolResult.Error = res.Error.Error()
+				}
+			})
+			msg, err := ToolResultMessage(call.ID, output)
+			if err != nil {
+				return nil, &NodeError{Code: "tool_error", Message: err.Error()}
+			}
+			req.input = append(req.input, msg)
+		}
+	}
+}
+
+func (x *localExec) fanout(ctx context.Context, n *localNode) (any, *NodeError) {
+	if n.sub == nil || n.sub.typ != workflow.NodeTypeV1LLMResponses {
+		return nil, &NodeError{Code: "unsupported", Message: "local map.fanout only supports llm subnodes"}
+	}
+	doc, err := x.resolve(n.items)
+	if err != nil {
+		return nil, &NodeError{Code: "invalid_items", Message: err.Error()}
+	}
+	if s, ok := doc.(string); ok {
+		if err := json.Unmarshal([]byte(s), &doc); err != nil {
+			return nil, &NodeError{Code: "invalid_items", Message: "items are not valid JSON: " + err.Error()}
+		}
+	}
+	doc, ok := resolveJSONPointer(doc, n.itemsPath)
+	items, isArray := doc.([]any)
+	if !ok || !isArray {
+		return nil, &NodeError{Code: "invalid_items", Message: fmt.Sprintf("items path %q does not select an array", n.itemsPath)}
+	}
+
+	out := make([]any, len(items))
+	errs := make([]*NodeError, len(items))
+	sem := make(chan struct{}, n.fanoutLimit)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		req, err := x.request(n.sub, &localItem{value: item, bindings: n.itemBindings})
+		if err != nil {
+			return nil, &NodeError{Code: "invalid_request", Message: fmt.Sprintf("item %d: %v", i, err)}
+		}
+		wg.Add(1)
+		go func(i int, req ResponseRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			resp, nodeErr := x.callLLM(ctx, n.id, req, 1)
+			if nodeErr == nil {
+				var err error
+				if out[i], err = localDecode(resp); err != nil {
+					nodeErr = &NodeError{Code: "invalid_response", Message: err.Error()}
+				}
+			}
+			errs[i] = nodeErr
+		}(i, req)
+	}
+	wg.Wait()
+	for i, nodeErr := range errs {
+		if nodeErr != nil {
+			nodeErr.Message = fmt.Sprintf("item %d: %s", i, nodeErr.Message)
+			return nil, nodeErr
+		}
+	}
+	return out, nil
+}
+
+// eval computes the output of join and transform nodes.
+func (x *localExec) eval(ctx context.Context, n *localNode) (any, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var ran []string
+	for _, e := range n.in {
+		if _, ok := x.values[e.from]; ok {
+			ran = append(ran, e.from)
+		}
+	}
+	switch n.typ {
+	case workflow.NodeTypeV1JoinAll:
+		out := make(map[string]any, len(ran))
+		for _, id := range ran {
+			out[id] = x.values[id]
+		}
+		return out, nil
+	case workflow.NodeTypeV1JoinAny, workflow.NodeTypeV1JoinCollect:
+		out := []any{}
+		for _, id := range ran {
+			if n.predicate != nil && !x.conditionLocked(n.predicate, id) {
+				continue
+			}
+			if n.typ == workflow.NodeTypeV1JoinAny {
+				return x.values[id], nil
+			}
+			if n.limit != nil && int64(len(out)) >= *n.limit {
+				break
+			}
+			out = append(out, x.values[id])
+		}
+		if n.typ == workflow.NodeTypeV1JoinAny {
+			return nil, errors.New("no upstream node satisfied the join")
+		}
+		return out, nil
+	case workflow.NodeTypeV1TransformJSON:
+		if len(n.object) > 0 {
+			out := make(map[string]any, len(n.object))
+			for key, ref := range n.object {
+				v, err := x.resolveLocked(ref)
+				if err != nil {
+					return nil, err
+				}
+				out[key] = v
+			}
+			return out, nil
+		}
+		out := map[string]any{}
+		for _, ref := range n.merge {
+			v, err := x.resolveLocked(ref)
+			if err != nil {
+				return nil, err
+			}
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("merge source %s is not an object", ref.from)
+			}
+			for k, val := range obj {
+				out[k] = val
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported node type %q", n.typ)
+	}
+}
+
+type localItem struct {
+	value    any
+	bindings []workflow.MapFanoutItemBindingV1
+}
+
+// request renders the ResponseRequest of an llm node.
+func (x *localExec) request(n *localNode, item *localItem) (ResponseRequest, error) {
+	raw, err := json.Marshal(n.request)
+	if err != nil {
+		return ResponseRequest{}, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ResponseRequest{}, err
+	}
+	placeholders := map[string]string{}
+	bind := func(value any, to, placeholder string, encoding workflow.LLMResponsesBindingEncodingV1) error {
+		if encoding == workflow.LLMResponsesBindingEncodingJSONStringV1 {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			value = string(encoded)
+		}
+		if placeholder != "" {
+			placeholders[placeholder] = localText(value)
+			return nil
+		}
+		doc, err = setJSONPointer(doc, to, value)
+		return err
+	}
+	for _, b := range n.bindings {
+		v, err := x.resolve(localRef{from: string(b.From), input: string(b.FromInput), pointer: string(b.Pointer)})
+		if err != nil {
+			return ResponseRequest{}, err
+		}
+		if err := bind(v, string(b.To), string(b.ToPlaceholder), b.Encoding); err != nil {
+			return ResponseRequest{}, err
+		}
+	}
+	if item != nil {
+		for _, b := range item.bindings {
+			v, ok := resolveJSONPointer(item.value, string(b.Path))
+			if !ok {
+				return ResponseRequest{}, fmt.Errorf("item path %q not found", b.Path)
+			}
+			if err := bind(v, string(b.To), string(b.ToPlaceholder), b.Encoding); err != nil {
+				return ResponseRequest{}, err
+			}
+		}
+		if n.intent {
+			placeholders["item"] = localText(item.value)
+		}
+	}
+
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return ResponseRequest{}, err
+	}
+	var payload responseRequestPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return ResponseRequest{}, fmt.Errorf("invalid request: %w", err)
+	}
+	for i := range payload.Input {
+		for j, part := range payload.Input[i].Content {
+			if part.Type == llm.ContentPartTypeText {
+				payload.Input[i].Content[j].Text = x.substitute(part.Text, placeholders, n.intent)
+			}
+		}
+	}
+	if x.opts.modelOverride != "" {
+		payload.Model = x.opts.modelOverride
+	}
+	req := ResponseRequest{
+		provider:        NewProviderID(payload.Provider),
+		model:           NewModelID(payload.Model),
+		stateID:         payload.StateID,
+		input:           payload.Input,
+		outputFormat:    payload.OutputFormat,
+		maxOutputTokens: payload.MaxOutputTokens,
+		temperature:     payload.Temperature,
+		stop:            payload.Stop,
+		tools:           payload.Tools,
+		toolChoice:      payload.ToolChoice,
+	}
+	return req, nil
+}
+
+// substitute replaces {{name}} placeholders. Intent nodes also resolve names
+// to run inputs or upstream node outputs.
+func (x *localExec) substitute(text string, placeholders map[string]string, intent bool) string {
+	return workflowPlaceholderRe.ReplaceAllStringFunc(text, func(m string) string {
+		name := workflowPlaceholderRe.FindStringSubmatch(m)[1]
+		if v, ok := placeholders[name]; ok {
+			return v
+		}
+		if !intent {
+			return m
+		}
+		x.mu.Lock()
+		defer x.mu.Unlock()
+		if v, ok := x.inputs[name]; ok {
+			return localText(v)
+		}
+		if t, ok := x.texts[name]; ok {
+			return t
+		}
+		if v, ok := x.values[name]; ok {
+			return localText(v)
+		}
+		return m
+	})
+}
+
+func (x *localExec) resolve(ref localRef) (any, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.resolveLocked(ref)
+}
+
+func (x *localExec) resolveLocked(ref localRef) (any, error) {
+	var doc any
+	switch {
+	case ref.input != "":
+		v, ok := x.inputs[ref.input]
+		if !ok {
+			return nil, fmt.Errorf("input %q was not provided", ref.input)
+		}
+		doc = v
+	default:
+		v, ok := x.values[ref.from]
+		if !ok {
+			return nil, fmt.Errorf("node %q has no output", ref.from)
+		}
+		doc = v
+	}
+	v, ok := resolveJSONPointer(doc, ref.pointer)
+	if !ok {
+		return nil, fmt.Errorf("pointer %q not found", ref.pointer)
+	}
+	return v, nil
+}
+
+func (x *localExec) condition(cond *workflow.ConditionV1, nodeID string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.conditionLocked(cond, nodeID)
+}
+
+func (x *localExec) conditionLocked(cond *workflow.ConditionV1, nodeID string) bool {
+	var doc any
+	if cond.Source == workflow.ConditionSourceNodeStatus {
+		doc = string(workflow.NodeStatusSucceeded)
+	} else if text, ok := x.texts[nodeID]; ok {
+		doc = text
+		_ = json.Unmarshal([]byte(text), &doc)
+	} else {
+		doc = x.values[nodeID]
+	}
+	return evalLocalCondition(cond, doc)
+}
+
+func evalLocalCondition(cond *workflow.ConditionV1, doc any) bool {
+	v, ok := evalJSONPath(doc, string(cond.Path))
+	switch cond.Op {
+	case workflow.ConditionOpExists:
+		return ok && v != nil
+	case workflow.ConditionOpEquals:
+		var want any
+		if !ok || json.Unmarshal(cond.Value, &want) != nil {
+			return false
+		}
+		return reflect.DeepEqual(v, want)
+	case workflow.ConditionOpMatches:
+		var pattern string
+		if !ok || json.Unmarshal(cond.Value, &pattern) != nil {
+			return false
+		}
+		re, err := regexp.Compile(pattern)
+		return err == nil && re.MatchString(localText(v))
+	default:
+		return false
+	}
+}
+
+func (x *localExec) record(req ResponseRequest, resp *Response) {
+	model := resp.Model.String()
+	if model == "" {
+		model = req.model.String()
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	item := x.cost[model]
+	if item == nil {
+		item = &workflow.CostLineItemV0{ProviderID: workflow.ProviderID(resp.Provider), Model: workflow.ModelID(model)}
+		x.cost[model] = item
+	}
+	item.Requests++
+	item.InputTokens += resp.Usage.InputTokens
+	item.OutputTokens += resp.Usage.OutputTokens
+}
+
+func (x *localExec) succeed(n *localNode, value any, text string) {
+	raw, _ := json.Marshal(value)
+	x.mu.Lock()
+	x.values[n.id] = value
+	if n.typ == workflow.NodeTypeV1LLMResponses || n.typ == workflow.NodeTypeV1RouteSwitch {
+		x.texts[n.id] = text
+	}
+	result := x.results[n.id]
+	result.Status = workflow.NodeStatusSucceeded
+	result.Output = raw
+	result.EndedAt = time.Now().UTC()
+	x.mu.Unlock()
+	x.emitNode(RunEventNodeOutput, n.id, func(env *RunEventEnvelope) {
+		env.Output = localArtifact(workflow.ArtifactKeyNodeOutputV0, raw)
+	})
+	x.emitNode(RunEventNodeSucceeded, n.id, nil)
+}
+
+func (x *localExec) fail(nodeID string, nodeErr *NodeError) {
+	x.mu.Lock()
+	if result := x.results[nodeID]; result != nil {
+		result.Status = workflow.NodeStatusFailed
+		result.Error = nodeErr
+		result.EndedAt = time.Now().UTC()
+	}
+	x.mu.Unlock()
+	x.emitNode(RunEventNodeFailed, nodeID, func(env *RunEventEnvelope) { env.Error = nodeErr })
+	typ := RunEventRunFailed
+	if nodeErr.Code == "canceled" {
+		typ = RunEventRunCanceled
+	}
+	x.emitRun(typ, func(env *RunEventEnvelope) {
+		env.Error = &NodeError{Code: nodeErr.Code, Message: "node " + nodeID + " failed: " + nodeErr.Message}
+	})
+}
+
+// abandon marks a node that was still running when the run failed.
+func (x *localExec) abandon(nodeID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if result := x.results[nodeID]; result != nil && result.Status == workflow.NodeStatusRunning {
+		result.Status = workflow.NodeStatusCanceled
+		result.EndedAt = time.Now().UTC()
+	}
+}
+
+func (x *localExec) complete(out *RunsGetResponse, finished map[string]bool) {
+	outputs := make(map[OutputName]json.RawMessage, len(x.plan.outputs))
+	for _, o := range x.plan.outputs {
+		v, err := x.resolve(localRef{from: o.from, pointer: o.pointer})
+		if !finished[o.from] {
+			err = fmt.Errorf("node %q was skipped", o.from)
+		}
+		if err != nil {
+			out.Status = workflow.StatusFailed
+			x.emitRun(RunEventRunFailed, func(env *RunEventEnvelope) {
+				env.Error = &NodeError{Code: "output_error", Message: "output " + o.name + ": " + err.Error()}
+			})
+			return
+		}
+		raw, _ := json.Marshal(v)
+		outputs[OutputName(o.name)] = raw
+	}
+	out.Outputs = outputs
+	out.Status = workflow.StatusSucceeded
+	raw, _ := json.Marshal(outputs)
+	x.emitRun(RunEventRunCompleted, func(env *RunEventEnvelope) {
+		env.Outputs = localArtifact(workflow.ArtifactKeyRunOutputsV0, raw)
+	})
+}
+
+// response snapshots the run state in the shape returned by RunsClient.Get.
+func (x *localExec) response() *RunsGetResponse {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	out := &RunsGetResponse{RunID: x.runID, Status: workflow.StatusFailed, PlanHash: x.plan.hash}
+	for _, n := range x.plan.nodes {
+		if result, ok := x.results[n.id]; ok {
+			out.Nodes = append(out.Nodes, *result)
+		}
+	}
+	models := make([]string, 0, len(x.cost))
+	for model := range x.cost {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	out.CostSummary.LineItems = []workflow.CostLineItemV0{}
+	for _, model := range models {
+		out.CostSummary.LineItems = append(out.CostSummary.LineItems, *x.cost[model])
+	}
+	return out
+}
+
+func (x *localExec) emitRun(typ RunEventType, fill func(*RunEventEnvelope)) {
+	hash := x.plan.hash
+	x.emit(RunEventEnvelope{Type: typ, PlanHash: &hash}, fill)
+}
+
+func (x *localExec) emitNode(typ RunEventType, nodeID string, fill func(*RunEventEnvelope)) {
+	x.emit(RunEventEnvelope{Type: typ, NodeID: NodeID(nodeID)}, fill)
+}
+
+// emit delivers events in seq order. The first sink error cancels the run.
+func (x *localExec) emit(env RunEventEnvelope, fill func(*RunEventEnvelope)) {
+	x.emitMu.Lock()
+	defer x.emitMu.Unlock()
+
+	x.mu.Lock()
+	if x.sinkErr != nil {
+		x.mu.Unlock()
+		return
+	}
+	x.seq++
+	env.EnvelopeVersion = RunEventEnvelopeVersion
+	env.RunID = x.runID
+	env.Seq = x.seq
+	env.TS = time.Now().UTC()
+	if fill != nil {
+		fill(&env)
+	}
+	x.mu.Unlock()
+
+	if err := x.sink(x.ctx, env); err != nil {
+		x.mu.Lock()
+		x.sinkErr = err
+		x.mu.Unlock()
+		x.cancel()
+	}
+}
+
+func localArtifact(key string, payload []byte) *PayloadArtifact {
+	sum := sha256.Sum256(payload)
+	return &PayloadArtifact{
+		ArtifactKey: key,
+		Info:        workflow.PayloadInfo{Bytes: int64(len(payload)), SHA256: hex.EncodeToString(sum[:])},
+	}
+}
+
+func localDecode(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// localText renders a value for a prompt: strings verbatim, anything else as
+// JSON.
+func localText(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+// resolveJSONPointer resolves an RFC 6901 pointer against decoded JSON.
+func resolveJSONPointer(doc any, pointer string) (any, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	cur := doc
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[tok]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// setJSONPointer sets the value at pointer, creating intermediate objects.
+// The "-" token appends to an array.
+func setJSONPointer(doc any, pointer string, value any) (any, error) {
+	if pointer == "" {
+		return value, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tok, rest, _ := strings.Cut(pointer[1:], "/")
+	tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	if rest != "" {
+		rest = "/" + rest
+	}
+	switch v := doc.(type) {
+	case nil:
+		doc = map[string]any{}
+		return setJSONPointer(doc, pointer, value)
+	case map[string]any:
+		next, err := setJSONPointer(v[tok], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		v[tok] = next
+		return v, nil
+	case []any:
+		if tok == "-" {
+			next, err := setJSONPointer(nil, rest, value)
+			if err != nil {
+				return nil, err
+			}
+			return append(v, next), nil
+		}
+		i, err := strconv.Atoi(tok)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, fmt.Errorf("JSON pointer %q: index %q out of range", pointer, tok)
+		}
+		next, err := setJSONPointer(v[i], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = next
+		return v, nil
+	default:
+		return nil, fmt.Errorf("JSON pointer %q: cannot index %T", pointer, doc)
+	}
+}
+
+// evalJSONPath evaluates the subset of JSONPath used by conditions: $, .key,
+// ['key'] and [index].
+func evalJSONPath(doc any, path string) (any, bool) {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "$" {
+		return doc, true
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, false
+	}
+	cur, rest := doc, path[1:]
+	for rest != "" {
+		var key string
+		index := -1
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key, rest = rest[1:end+1], rest[end+1:]
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest[2:], string(rest[1])+"]")
+			if end < 0 {
+				return nil, false
+			}
+			key, rest = rest[2:end+2], rest[end+4:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, false
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, false
+			}
+			index, rest = i, rest[end+1:]
+		default:
+			return nil, false
+		}
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if index >= 0 || !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			if index < 0 || index >= len(v) {
+				return nil, false
+			}
+			cur = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
@@ -0,0 +1,237 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+func localTextResponse(text string) *Response {
+	return &Response{
+		ID:     "resp_local",
+		Model:  NewModelID("demo"),
+		Output: []llm.OutputItem{{Type: llm.OutputItemTypeMessage, Role: llm.RoleAssistant, Content: []llm.ContentPart{llm.TextPart(text)}}},
+		Usage:  Usage{InputTokens: 3, OutputTokens: 2},
+	}
+}
+
+func localLastUserText(req ResponseRequest) string {
+	input := req.Input()
+	if len(input) == 0 || len(input[len(input)-1].Content) == 0 {
+		return ""
+	}
+	return input[len(input)-1].Content[0].Text
+}
+
+func TestLocalRunnerIntentSpec(t *testing.T) {
+	var inflight, peak atomic.Int32
+	responses := ResponsesCreatorFunc(func(_ context.Context, req ResponseRequest) (*Response, error) {
+		if n := inflight.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer inflight.Add(-1)
+		time.Sleep(5 * time.Millisecond)
+		return localTextResponse(strings.ToUpper(localLastUserText(req))), nil
+	})
+	parallelism := int64(1)
+	spec := WorkflowSpec{
+		Kind:           workflowintent.KindWorkflow,
+		Model:          "demo",
+		MaxParallelism: &parallelism,
+		Inputs:         []workflowintent.InputDecl{{Name: "topic", Type: "string", Default: json.RawMessage(`"tides"`)}},
+		Nodes: []workflowintent.Node{
+			{ID: "cost", Type: workflowintent.NodeTypeLLM, User: "cost of {{topic}}"},
+			{ID: "risk", Type: workflowintent.NodeTypeLLM, User: "risk of {{topic}}"},
+			{ID: "join", Type: workflowintent.NodeTypeJoinAll, DependsOn: []string{"cost", "risk"}},
+			{ID: "summary", Type: workflowintent.NodeTypeTransformJSON, DependsOn: []string{"join"}, Object: map[string]workflowintent.TransformValue{
+				"cost": {From: "join", Pointer: JoinOutput("cost").Text().String()},
+				"risk": {From: "join", Pointer: JoinOutput("risk").Text().String()},
+			}},
+		},
+		Outputs: []workflowintent.OutputRef{{Name: "summary", From: "summary"}},
+	}
+
+	var events []RunEvent
+	run, err := NewLocalRunner(responses).Run(context.Background(), spec, WithLocalRunEventHandler(func(_ context.Context, ev RunEvent) error {
+		events = append(events, ev)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if run.Status != RunStatusSucceeded || string(run.Outputs["summary"]) != `{"cost":"COST OF TIDES","risk":"RISK OF TIDES"}` {
+		t.Fatalf("unexpected run: status=%s outputs=%s", run.Status, run.Outputs["summary"])
+	}
+	if peak.Load() != 1 {
+		t.Fatalf("expected max parallelism 1, saw %d concurrent calls", peak.Load())
+	}
+	if len(run.Nodes) != 4 || len(run.CostSummary.LineItems) != 1 || run.CostSummary.LineItems[0].Requests != 2 {
+		t.Fatalf("unexpected nodes or cost: %+v %+v", run.Nodes, run.CostSummary)
+	}
+	if _, ok := events[0].(RunEventRunCompiledV0); !ok {
+		t.Fatalf("expected run_compiled first, got %T", events[0])
+	}
+	if _, ok := events[len(events)-1].(RunEventRunCompletedV0); !ok {
+		t.Fatalf("expected run_completed last, got %T", events[len(events)-1])
+	}
+	var llmCalls int
+	for i, ev := range events {
+		if runEventSeq(ev) != int64(i+1) {
+			t.Fatalf("event %d has seq %d", i, runEventSeq(ev))
+		}
+		if _, ok := ev.(RunEventNodeLLMCallV0); ok {
+			llmCalls++
+		}
+	}
+	if llmCalls != 2 {
+		t.Fatalf("expected 2 llm call events, got %d", llmCalls)
+	}
+}
+
+func TestLocalRunnerV1RoutingAndFanout(t *testing.T) {
+	var mu sync.Mutex
+	var prompts []string
+	responses := ResponsesCreatorFunc(func(_ context.Context, req ResponseRequest) (*Response, error) {
+		text := localLastUserText(req)
+		mu.Lock()
+		prompts = append(prompts, text)
+		mu.Unlock()
+		switch {
+		case text == "classify":
+			return localTextResponse(`{"route":"billing"}`), nil
+		case strings.HasPrefix(text, "summarize "):
+			return localTextResponse(strings.TrimPrefix(text, "summarize ")), nil
+		default:
+			return localTextResponse("handled " + text), nil
+		}
+	})
+	request := func(user string, bindings ...workflow.LLMResponsesBindingV1) json.RawMessage {
+		raw, _ := json.Marshal(map[string]any{
+			"request":  map[string]any{"model": "demo", "input": []llm.InputItem{llm.NewUserText(user)}},
+			"bindings": bindings,
+		})
+		return raw
+	}
+	fanout, _ := json.Marshal(workflow.MapFanoutNodeInputV1{
+		Items:        workflow.MapFanoutItemsFromInput("tickets", ""),
+		ItemBindings: []workflow.MapFanoutItemBindingV1{{Path: "/title", ToPlaceholder: "title"}},
+		SubNode:      workflow.MapFanoutSubNodeV1{ID: "summarize", Type: workflow.NodeTypeV1LLMResponses, Input: request("summarize {{title}}")},
+	})
+	when := func(route string) *workflow.ConditionV1 {
+		return &workflow.ConditionV1{Source: workflow.ConditionSourceNodeOutput, Op: workflow.ConditionOpEquals, Path: "$.route", Value: json.RawMessage(`"` + route + `"`)}
+	}
+	spec := workflow.SpecV1{
+		Kind:   workflow.KindV1,
+		Inputs: []workflow.InputDeclV1{{Name: "tickets", Type: "array", Required: true}},
+		Nodes: []workflow.NodeV1{
+			{ID: "route", Type: workflow.NodeTypeV1RouteSwitch, Input: request("classify")},
+			{ID: "billing", Type: workflow.NodeTypeV1LLMResponses, Input: request("billing: {{route}}", workflow.LLMResponsesBindingV1{
+				From: "route", Pointer: LLMTextOutputPointer, ToPlaceholder: "route",
+			})},
+			{ID: "support", Type: workflow.NodeTypeV1LLMResponses, Input: request("support")},
+			{ID: "answer", Type: workflow.NodeTypeV1JoinAny},
+			{ID: "titles", Type: workflow.NodeTypeV1MapFanout, Input: fanout},
+		},
+		Edges: []workflow.EdgeV1{
+			{From: "route", To: "billing", When: when("billing")},
+			{From: "route", To: "support", When: when("support")},
+			{From: "billing", To: "answer"},
+			{From: "support", To: "answer"},
+		},
+		Outputs: []workflow.OutputRefV1{
+			{Name: "answer", From: "answer", Pointer: LLMTextOutputPointer},
+			{Name: "first_title", From: "titles", Pointer: "/0/output/0/content/0/text"},
+		},
+	}
+
+	run, err := NewLocalRunner(responses).RunV1(context.Background(), spec, WithLocalRunInputs(map[string]any{
+		"tickets": []map[string]string{{"title": "refund"}, {"title": "login"}},
+	}))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if run.Status != RunStatusSucceeded {
+		t.Fatalf("unexpected status %s: %+v", run.Status, run.Nodes)
+	}
+	if got := string(run.Outputs["answer"]); got != `"handled billing: {\"route\":\"billing\"}"` {
+		t.Fatalf("unexpected answer %s", got)
+	}
+	if got := string(run.Outputs["first_title"]); got != `"refund"` {
+		t.Fatalf("unexpected fanout output %s", got)
+	}
+	for _, p := range prompts {
+		if p == "support" {
+			t.Fatalf("support branch should have been skipped")
+		}
+	}
+	if len(prompts) != 4 {
+		t.Fatalf("expected 4 model calls, got %v", prompts)
+	}
+
+	if _, err := NewLocalRunner(responses).RunV1(context.Background(), spec); err == nil {
+		t.Fatalf("expected error for missing required input")
+	}
+}
+
+func TestLocalRunnerStreamEventsAndFailure(t *testing.T) {
+	responses := ResponsesCreatorFunc(func(_ context.Context, req ResponseRequest) (*Response, error) {
+		if localLastUserText(req) == "boom" {
+			return nil, errors.New("provider unavailable")
+		}
+		return localTextResponse("ok"), nil
+	})
+	spec := WorkflowSpec{
+		Kind:  workflowintent.KindWorkflow,
+		Model: "demo",
+		Nodes: []workflowintent.Node{
+			{ID: "first", Type: workflowintent.NodeTypeLLM, User: "hi"},
+			{ID: "second", Type: workflowintent.NodeTypeLLM, User: "boom", DependsOn: []string{"first"}},
+		},
+		Outputs: []workflowintent.OutputRef{{Name: "out", From: "second"}},
+	}
+	runner := NewLocalRunner(responses)
+
+	stream, err := runner.StreamEvents(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	t.Cleanup(func() { _ = stream.Close() })
+	var last RunEvent
+	for {
+		ev, ok, err := stream.Next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if !ok {
+			break
+		}
+		last = ev
+	}
+	failed, ok := last.(RunEventRunFailedV0)
+	if !ok || !strings.Contains(failed.Error.Message, "provider unavailable") {
+		t.Fatalf("expected run_failed, got %#v", last)
+	}
+
+	run, err := runner.Run(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if run.Status != RunStatusFailed || len(run.Nodes) != 2 || run.Nodes[1].Status != NodeStatusFailed {
+		t.Fatalf("unexpected failed run: %+v", run)
+	}
+
+	stop := errors.New("stop")
+	_, err = runner.Run(context.Background(), spec, WithLocalRunEventHandler(func(context.Context, RunEvent) error { return stop }))
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+}
@@ -163,6 +163,7 @@ func (p *LocalWriteFileToolPack) RegisterInto(registry *ToolRegistry) *ToolRegis
 		return nil
 	}
 	registry.Register(ToolNameWriteFile, p.writeFileTool)
+	registry.MarkSerial(ToolNameWriteFile)
 	return registry
 }
 
@@ -0,0 +1,218 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/modelrelay/modelrelay/sdk/go/headers"
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+)
+
+// RoundTripFunc sends one API request attempt.
+type RoundTripFunc func(req *MiddlewareRequest) (*http.Response, error)
+
+// Middleware wraps the round trip of every API request, JSON and streaming
+// alike. It runs once per attempt, after authentication and default headers
+// are applied and inside the client's retry loop, so it can sign requests,
+// inject headers, rewrite payloads, audit traffic, short-circuit with a
+// synthetic response or call next several times to implement its own retries.
+//
+// Middleware registered first runs outermost.
+//
+// Example:
+//
+//	audit := func(next sdk.RoundTripFunc) sdk.RoundTripFunc {
+//		return func(req *sdk.MiddlewareRequest) (*http.Response, error) {
+//			resp, err := next(req)
+//			log.Printf("%s %s model=%s customer=%s", req.HTTP.Method, req.Route, req.Model, req.CustomerID)
+//			return resp, err
+//		}
+//	}
+//	client, err := sdk.NewClientWithKey(key, sdk.WithMiddleware(audit))
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware appends middleware to the client's chain.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *clientOptions) {
+		for _, m := range mw {
+			if m != nil {
+				o.middleware = append(o.middleware, m)
+			}
+		}
+	}
+}
+
+// MiddlewareRequest describes an outgoing request attempt.
+type MiddlewareRequest struct {
+	// HTTP is the outgoing request. Middleware may modify its headers or
+	// replace it; use SetPayload to change the body.
+	HTTP *http.Request
+	// Route is the API route template (for example routes.RunsByID) with the
+	// client base path removed. Unknown paths have UUID segments replaced by "{id}".
+	Route string
+	// Model is the "model" field of the JSON payload, when present.
+	Model ModelID
+	// CustomerID is the X-ModelRelay-Customer-Id header, when present.
+	CustomerID string
+	// Stream reports whether the response is consumed as a stream (NDJSON or SSE).
+	Stream bool
+	// Attempt is the 1-based attempt number under the client's RetryConfig.
+	Attempt int
+
+	body []byte
+}
+
+// Body returns the raw request body.
+func (r *MiddlewareRequest) Body() []byte {
+	return r.body
+}
+
+// Payload decodes the JSON request body. It returns nil for requests without a body.
+func (r *MiddlewareRequest) Payload() (map[string]any, error) {
+	if len(r.body) == 0 {
+		return nil, nil
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(r.body, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// SetPayload replaces the request body with the JSON encoding of payload and
+// refreshes Model.
+func (r *MiddlewareRequest) SetPayload(payload any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	r.setBody(encoded)
+	r.Model = payloadModel(encoded)
+	return nil
+}
+
+func (r *MiddlewareRequest) setBody(body []byte) {
+	r.body = body
+	r.HTTP.Body = io.NopCloser(bytes.NewReader(body))
+	r.HTTP.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.HTTP.ContentLength = int64(len(body))
+}
+
+// middlewareChain applies client middleware around httpClient.Do.
+type middlewareChain struct {
+	basePath string
+	mws      []Middleware
+}
+
+func newMiddlewareChain(baseURL string, opts clientOptions) *middlewareChain {
+	if len(opts.middleware) == 0 {
+		return nil
+	}
+	m := &middlewareChain{mws: append([]Middleware(nil), opts.middleware...)}
+	if u, err := url.Parse(baseURL); err == nil {
+		m.basePath = strings.TrimSuffix(u.Path, "/")
+	}
+	return m
+}
+
+func (m *middlewareChain) roundTrip(httpClient *http.Client, req *http.Request, attempt int) (*http.Response, error) {
+	if m == nil {
+		return httpClient.Do(req)
+	}
+	mreq := &MiddlewareRequest{
+		HTTP:       req,
+		Route:      middlewareRoute(strings.TrimPrefix(req.URL.Path, m.basePath)),
+		CustomerID: req.Header.Get(headers.CustomerID),
+		Attempt:    attempt,
+	}
+	accept := strings.ToLower(req.Header.Get("Accept"))
+	mreq.Stream = strings.Contains(accept, "ndjson") || strings.Contains(accept, "event-stream")
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		//nolint:errcheck // body is fully buffered
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		mreq.setBody(body)
+		mreq.Model = payloadModel(body)
+	}
+
+	next := RoundTripFunc(func(r *MiddlewareRequest) (*http.Response, error) {
+		return httpClient.Do(r.HTTP)
+	})
+	for i := len(m.mws) - 1; i >= 0; i-- {
+		next = m.mws[i](next)
+	}
+	resp, err := next(mreq)
+	if resp == nil && err == nil {
+		return nil, errors.New("sdk: middleware returned neither a response nor an error")
+	}
+	return resp, err
+}
+
+func payloadModel(body []byte) ModelID {
+	var probe struct {
+		Model string `json:"model"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &probe) != nil {
+		return ""
+	}
+	return NewModelID(probe.Model)
+}
+
+// middlewareTemplates are the parameterized routes, checked after literal
+// routes such as routes.CustomersMe.
+var middlewareTemplates = []string{
+	routes.CustomersByID,
+	routes.CustomersSubscribe,
+	routes.CustomersSubscription,
+	routes.RunsByID,
+	routes.RunsEvents,
+	routes.RunsToolResults,
+	routes.RunsPendingTools,
+}
+
+var middlewareLiterals = map[string]bool{
+	routes.CustomersMe:             true,
+	routes.CustomersMeUsage:        true,
+	routes.CustomersMeSubscription: true,
+}
+
+func middlewareRoute(path string) string {
+	if middlewareLiterals[path] {
+		return path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, tmpl := range middlewareTemplates {
+		parts := strings.Split(strings.Trim(tmpl, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		match := true
+		for i, part := range parts {
+			if !strings.HasPrefix(part, "{") && part != segments[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return tmpl
+		}
+	}
+	for i, seg := range segments {
+		if _, err := uuid.Parse(seg); err == nil {
+			segments[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,191 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+	"github.com/modelrelay/modelrelay/sdk/go/testutil"
+)
+
+func TestMiddlewareSeesJSONAndStreamRequests(t *testing.T) {
+	fake := testutil.NewFakeServer()
+	t.Cleanup(fake.Close)
+
+	type seen struct {
+		route    string
+		model    ModelID
+		customer string
+		stream   bool
+	}
+	var (
+		mu    sync.Mutex
+		calls []seen
+		order []string
+	)
+	audit := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *MiddlewareRequest) (*http.Response, error) {
+			mu.Lock()
+			order = append(order, "audit")
+			calls = append(calls, seen{req.Route, req.Model, req.CustomerID, req.Stream})
+			mu.Unlock()
+			return next(req)
+		}
+	}
+	redact := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *MiddlewareRequest) (*http.Response, error) {
+			mu.Lock()
+			order = append(order, "redact")
+			mu.Unlock()
+			req.HTTP.Header.Set("X-Signed", "yes")
+			payload, err := req.Payload()
+			if err != nil {
+				return nil, err
+			}
+			if payload != nil {
+				raw, _ := json.Marshal(payload["input"])
+				var input any
+				_ = json.Unmarshal([]byte(strings.ReplaceAll(string(raw), "secret-123", "[redacted]")), &input)
+				payload["input"] = input
+				if err := req.SetPayload(payload); err != nil {
+					return nil, err
+				}
+			}
+			return next(req)
+		}
+	}
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(fake.URL),
+		WithMiddleware(audit, redact),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	ctx := context.Background()
+
+	text, err := client.Responses.Text(ctx, NewModelID("demo"), "sys", "my key is secret-123")
+	if err != nil {
+		t.Fatalf("text: %v", err)
+	}
+	if text != "echo: my key is [redacted]" {
+		t.Fatalf("expected redacted payload to reach server, got %q", text)
+	}
+
+	req, opts, err := client.Responses.New().CustomerID("cust_1").User("stream secret-123").Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	stream, err := client.Responses.Stream(ctx, req, opts...)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	resp, err := stream.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if resp.AssistantText() != "echo: stream [redacted]" {
+		t.Fatalf("unexpected streamed text %q", resp.AssistantText())
+	}
+
+	want := []seen{
+		{route: routes.Responses, model: "demo"},
+		{route: routes.Responses, customer: "cust_1", stream: true},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != len(want) {
+		t.Fatalf("expected %d calls, got %+v", len(want), calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("call %d: got %+v want %+v", i, calls[i], want[i])
+		}
+	}
+	if strings.Join(order[:2], ",") != "audit,redact" {
+		t.Fatalf("expected first-registered middleware outermost, got %v", order)
+	}
+}
+
+func TestMiddlewareShortCircuitAndRetry(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"generations":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	// Retries 409s, which the client's RetryConfig never does.
+	retryConflict := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *MiddlewareRequest) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil && resp.StatusCode == http.StatusConflict {
+				_ = resp.Body.Close()
+				return next(req)
+			}
+			return resp, err
+		}
+	}
+	cached := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *MiddlewareRequest) (*http.Response, error) {
+			if req.Route != routes.RunsByID {
+				return next(req)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"run_id":"` + strings.TrimPrefix(req.HTTP.URL.Path, "/runs/") + `","status":"succeeded"}`)),
+				Request:    req.HTTP,
+			}, nil
+		}
+	}
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL),
+		WithMiddleware(retryConflict, cached),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+
+	if _, err := client.Generations.Latest(context.Background()); err != nil {
+		t.Fatalf("latest: %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("expected middleware retry, got %d hits", hits.Load())
+	}
+
+	runID := NewRunID()
+	run, err := client.Runs.Get(context.Background(), runID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	if run.RunID != runID || hits.Load() != 2 {
+		t.Fatalf("expected short-circuited response, got %+v (hits=%d)", run, hits.Load())
+	}
+}
+
+func TestMiddlewareRoute(t *testing.T) {
+	cases := map[string]string{
+		"/runs/3f2504e0-4f89-11d3-9a0c-0305e82c3301":        routes.RunsByID,
+		"/runs/3f2504e0-4f89-11d3-9a0c-0305e82c3301/events": routes.RunsEvents,
+		"/customers/me/usage":                               routes.CustomersMeUsage,
+		"/customers/cust_1":                                 routes.CustomersByID,
+		"/sessions/3f2504e0-4f89-11d3-9a0c-0305e82c3301":    "/sessions/{id}",
+		"/responses": routes.Responses,
+	}
+	for path, want := range cases {
+		if got := middlewareRoute(path); got != want {
+			t.Errorf("middlewareRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ModelCandidate is one model/provider a ModelRouter may send a request to.
+type ModelCandidate struct {
+	Model    ModelID
+	Provider ProviderID
+	// Weight is the relative share of traffic when ModelRoutingConfig.LoadBalance
+	// is set. Default 1.
+	Weight int
+}
+
+func (c ModelCandidate) String() string {
+	if c.Provider.IsEmpty() {
+		return c.Model.String()
+	}
+	return c.Provider.String() + "/" + c.Model.String()
+}
+
+// ModelRoutingConfig configures a ModelRouter.
+type ModelRoutingConfig struct {
+	// Candidates are tried in order until one succeeds.
+	Candidates []ModelCandidate
+	// FallbackCodes are the API error codes that move on to the next candidate.
+	// Default: RATE_LIMIT, SERVICE_UNAVAILABLE, INTERNAL_ERROR and
+	// MODEL_CAPABILITY_UNSUPPORTED. HTTP 429 and 5xx responses always fall back.
+	FallbackCodes []APIErrorCode
+	// DisableTransportFallback stops fallback on timeouts and connection errors.
+	DisableTransportFallback bool
+	// LoadBalance picks the first candidate by weighted random choice instead of
+	// always starting with Candidates[0]. Remaining candidates keep their order.
+	LoadBalance bool
+	// CircuitBreaker, if enabled, skips candidates that keep failing.
+	CircuitBreaker CircuitBreakerConfig
+}
+
+var defaultFallbackCodes = []APIErrorCode{
+	ErrCodeRateLimit,
+	ErrCodeUnavailable,
+	ErrCodeInternal,
+	ErrCodeModelCapabilityUnsupported,
+}
+
+// ModelRouter routes /responses calls across an ordered list of models with
+// fallback, optional weighted load balancing and per-candidate circuit
+// breakers. Each candidate is retried according to the call's retry policy
+// before the router falls back. It is safe for concurrent use; share one
+// router across calls so breaker state accumulates.
+//
+// Example:
+//
+//	router, err := sdk.NewModelRouter(sdk.ModelRoutingConfig{
+//		Candidates: []sdk.ModelCandidate{
+//			{Model: sdk.NewModelID("claude-sonnet-4-5")},
+//			{Model: sdk.NewModelID("gpt-4o"), Provider: sdk.NewProviderID("openai")},
+//		},
+//		CircuitBreaker: sdk.CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute},
+//	})
+//	req, opts, err := client.Responses.New().Router(router).User("Hello").Build()
+//	resp, err := client.Responses.Create(ctx, req, opts...)
+type ModelRouter struct {
+	candidates []ModelCandidate
+	breakers   []*circuitBreaker
+	codes      map[APIErrorCode]struct{}
+	transport  bool
+	balance    bool
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewModelRouter validates cfg and returns a router.
+func NewModelRouter(cfg ModelRoutingConfig) (*ModelRouter, error) {
+	if len(cfg.Candidates) == 0 {
+		return nil, ConfigError{Reason: "at least one model candidate is required"}
+	}
+	codes := cfg.FallbackCodes
+	if len(codes) == 0 {
+		codes = defaultFallbackCodes
+	}
+	r := &ModelRouter{
+		candidates: make([]ModelCandidate, len(cfg.Candidates)),
+		breakers:   make([]*circuitBreaker, len(cfg.Candidates)),
+		codes:      make(map[APIErrorCode]struct{}, len(codes)),
+		transport:  !cfg.DisableTransportFallback,
+		balance:    cfg.LoadBalance,
+		//nolint:gosec // math/rand is acceptable for load balancing
+		rand: rand.New(rand.NewSource(rand.Int63())),
+	}
+	for i, cand := range cfg.Candidates {
+		if cand.Model.IsEmpty() {
+			return nil, ConfigError{Reason: fmt.Sprintf("model candidate %d: model is required", i)}
+		}
+		if cand.Weight < 0 {
+			return nil, ConfigError{Reason: fmt.Sprintf("model candidate %d: weight must be >= 0", i)}
+		}
+		if cand.Weight == 0 {
+			cand.Weight = 1
+		}
+		r.candidates[i] = cand
+		if cfg.CircuitBreaker.enabled() {
+			r.breakers[i] = newCircuitBreaker(cfg.CircuitBreaker)
+		}
+	}
+	for _, code := range codes {
+		r.codes[code] = struct{}{}
+	}
+	return r, nil
+}
+
+// Candidates returns the configured candidates.
+func (r *ModelRouter) Candidates() []ModelCandidate {
+	return append([]ModelCandidate(nil), r.candidates...)
+}
+
+// CircuitState reports the breaker state of candidate i.
+func (r *ModelRouter) CircuitState(i int) CircuitState {
+	if i < 0 || i >= len(r.breakers) {
+		return CircuitClosed
+	}
+	return r.breakers[i].currentState()
+}
+
+// order returns candidate indexes in the order they should be tried.
+func (r *ModelRouter) order() []int {
+	idx := make([]int, len(r.candidates))
+	for i := range idx {
+		idx[i] = i
+	}
+	if !r.balance || len(idx) < 2 {
+		return idx
+	}
+	total := 0
+	for _, c := range r.candidates {
+		total += c.Weight
+	}
+	r.mu.Lock()
+	pick := r.rand.Intn(total)
+	r.mu.Unlock()
+	first := 0
+	for i, c := range r.candidates {
+		if pick < c.Weight {
+			first = i
+			break
+		}
+		pick -= c.Weight
+	}
+	return append([]int{first}, append(idx[:first:first], idx[first+1:]...)...)
+}
+
+// shouldFallback reports whether err from one candidate should move on to the next.
+func (r *ModelRouter) shouldFallback(err error) bool {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		if _, ok := r.codes[apiErr.Code]; ok {
+			return true
+		}
+		return apiErr.Status == http.StatusTooManyRequests || apiErr.Status >= 500
+	}
+	var transportErr TransportError
+	if errors.As(err, &transportErr) {
+		if !r.transport {
+			return false
+		}
+		return transportErr.Kind == TransportErrorTimeout || transportErr.Kind == TransportErrorConnect || transportErr.Kind == TransportErrorEmptyResponse
+	}
+	return false
+}
+
+// ModelRoutingError is returned when every candidate failed or was skipped by
+// an open circuit breaker.
+type ModelRoutingError struct {
+	// Attempts maps each tried candidate to its error, in order.
+	Attempts []ModelRoutingAttempt
+}
+
+// ModelRoutingAttempt records the outcome of one routed candidate.
+type ModelRoutingAttempt struct {
+	Candidate ModelCandidate
+	Err       error
+}
+
+func (e ModelRoutingError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "sdk: no model candidate available (all circuit breakers open)"
+	}
+	parts := make([]string, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		parts = append(parts, a.Candidate.String()+": "+a.Err.Error())
+	}
+	return "sdk: all model candidates failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns the last candidate's error.
+func (e ModelRoutingError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// routeResponse runs attempt once per routing candidate until one succeeds.
+// Without a router it runs attempt once with req unchanged.
+func routeResponse[T any](ctx context.Context, c *Client, router *ModelRouter, req ResponseRequest, attempt func(ResponseRequest) (T, error)) (T, error) {
+	if router == nil {
+		return attempt(req)
+	}
+	var zero T
+	var tried []ModelRoutingAttempt
+	for n, i := range router.order() {
+		cand := router.candidates[i]
+		breaker := router.breakers[i]
+		if !breaker.allow() {
+			c.telemetry.log(ctx, LogLevelInfo, "sdk_model_route_skipped", map[string]any{
+				"model":  cand.Model.String(),
+				"reason": "circuit_open",
+			})
+			continue
+		}
+
+		routed := req
+		routed.model = cand.Model
+		if !cand.Provider.IsEmpty() {
+			routed.provider = cand.Provider
+		}
+		res, err := attempt(routed)
+		annotateRetryModel(err, cand)
+		if err == nil {
+			breaker.success()
+			c.telemetry.log(ctx, LogLevelInfo, "sdk_model_route", map[string]any{
+				"model":     cand.Model.String(),
+				"provider":  cand.Provider.String(),
+				"fallbacks": len(tried),
+				"position":  n,
+			})
+			c.telemetry.metric(ctx, "sdk_model_route_total", 1, map[string]string{"model": cand.Model.String()})
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			breaker.release()
+			return zero, err
+		}
+		if !router.shouldFallback(err) {
+			// The candidate answered; the error is caused by the request itself.
+			breaker.success()
+			return zero, err
+		}
+		breaker.failure()
+		tried = append(tried, ModelRoutingAttempt{Candidate: cand, Err: err})
+		c.telemetry.log(ctx, LogLevelError, "sdk_model_fallback", map[string]any{
+			"model": cand.Model.String(),
+			"error": err.Error(),
+		})
+	}
+	return zero, ModelRoutingError{Attempts: tried}
+}
+
+// annotateRetryModel records the candidate on the error's RetryMetadata.
+func annotateRetryModel(err error, cand ModelCandidate) {
+	if err == nil {
+		return
+	}
+	var meta *RetryMetadata
+	var apiErr APIError
+	var transportErr TransportError
+	switch {
+	case errors.As(err, &apiErr):
+		meta = apiErr.Retry
+	case errors.As(err, &transportErr):
+		meta = transportErr.Retry
+	}
+	if meta != nil {
+		meta.Model = cand.Model
+		meta.Provider = cand.Provider
+	}
+}
@@ -0,0 +1,194 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRoutingServer fails requests for models in failing with the given status
+// and records the model of every request.
+func newRoutingServer(t *testing.T, failing map[string]int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		models = append(models, payload.Model)
+		mu.Unlock()
+		if status, ok := failing[payload.Model]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			code := "SERVICE_UNAVAILABLE"
+			if status == http.StatusBadRequest {
+				code = "VALIDATION_ERROR"
+			}
+			_, _ = w.Write([]byte(`{"error":"nope","code":"` + code + `","message":"nope"}`))
+			return
+		}
+		if r.Header.Get("Accept") == "application/json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"resp_1","model":"` + payload.Model + `","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"ok"}]}]}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"type":"start","request_id":"resp_1","model":"` + payload.Model + `"}` + "\n"))
+		_, _ = w.Write([]byte(`{"type":"completion","content":"ok"}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &models
+}
+
+func mustModelRouter(t *testing.T, cfg ModelRoutingConfig) *ModelRouter {
+	t.Helper()
+	router, err := NewModelRouter(cfg)
+	if err != nil {
+		t.Fatalf("new router: %v", err)
+	}
+	return router
+}
+
+func TestModelRouterFallback(t *testing.T) {
+	srv, models := newRoutingServer(t, map[string]int{"primary": http.StatusServiceUnavailable})
+	client := newTestClient(t, srv, "mr_sk_test")
+	router := mustModelRouter(t, ModelRoutingConfig{Candidates: []ModelCandidate{
+		{Model: "primary"},
+		{Model: "backup"},
+	}})
+
+	req, opts, err := client.Responses.New().Router(router).User("hi").DisableRetry().Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	resp, err := client.Responses.Create(context.Background(), req, opts...)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if resp.Model != "backup" {
+		t.Fatalf("expected backup model, got %q", resp.Model)
+	}
+	if len(*models) != 2 || (*models)[0] != "primary" || (*models)[1] != "backup" {
+		t.Fatalf("unexpected request order %v", *models)
+	}
+
+	stream, err := client.Responses.Stream(context.Background(), req, opts...)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer stream.Close()
+	if len(*models) != 4 || (*models)[3] != "backup" {
+		t.Fatalf("expected stream to fall back, got %v", *models)
+	}
+}
+
+func TestModelRouterNoFallbackOnRequestError(t *testing.T) {
+	srv, models := newRoutingServer(t, map[string]int{"primary": http.StatusBadRequest})
+	client := newTestClient(t, srv, "mr_sk_test")
+	router := mustModelRouter(t, ModelRoutingConfig{Candidates: []ModelCandidate{
+		{Model: "primary"},
+		{Model: "backup"},
+	}})
+
+	req, opts, _ := client.Responses.New().Router(router).User("hi").DisableRetry().Build()
+	_, err := client.Responses.Create(context.Background(), req, opts...)
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if apiErr.Retry == nil || apiErr.Retry.Model != "primary" {
+		t.Fatalf("expected retry metadata to name the model, got %+v", apiErr.Retry)
+	}
+	if len(*models) != 1 {
+		t.Fatalf("expected no fallback, got %v", *models)
+	}
+}
+
+func TestModelRouterCircuitBreaker(t *testing.T) {
+	srv, models := newRoutingServer(t, map[string]int{"primary": http.StatusServiceUnavailable, "backup": http.StatusServiceUnavailable})
+	client := newTestClient(t, srv, "mr_sk_test")
+	router := mustModelRouter(t, ModelRoutingConfig{
+		Candidates:     []ModelCandidate{{Model: "primary"}, {Model: "backup"}},
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Hour},
+	})
+
+	req, opts, _ := client.Responses.New().Router(router).User("hi").DisableRetry().Build()
+	_, err := client.Responses.Create(context.Background(), req, opts...)
+	var routeErr ModelRoutingError
+	if !errors.As(err, &routeErr) || len(routeErr.Attempts) != 2 {
+		t.Fatalf("expected routing error with 2 attempts, got %v", err)
+	}
+	if router.CircuitState(0) != CircuitOpen || router.CircuitState(1) != CircuitOpen {
+		t.Fatalf("expected both breakers open")
+	}
+
+	_, err = client.Responses.Create(context.Background(), req, opts...)
+	if !errors.As(err, &routeErr) || len(routeErr.Attempts) != 0 {
+		t.Fatalf("expected all candidates skipped, got %v", err)
+	}
+	if len(*models) != 2 {
+		t.Fatalf("expected no requests while breakers are open, got %v", *models)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	if !b.allow() {
+		t.Fatal("expected breaker closed after one failure")
+	}
+	b.failure()
+	if b.allow() {
+		t.Fatal("expected breaker open")
+	}
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("expected trial call after cooldown")
+	}
+	if b.allow() {
+		t.Fatal("expected only one trial call")
+	}
+	b.success()
+	if b.currentState() != CircuitClosed || !b.allow() {
+		t.Fatal("expected breaker closed after successful trial")
+	}
+}
+
+func TestModelRouterLoadBalance(t *testing.T) {
+	router := mustModelRouter(t, ModelRoutingConfig{
+		Candidates:  []ModelCandidate{{Model: "a", Weight: 1}, {Model: "b", Weight: 3}, {Model: "c", Weight: 1}},
+		LoadBalance: true,
+	})
+	router.rand = rand.New(rand.NewSource(1))
+
+	firsts := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		order := router.order()
+		if len(order) != 3 {
+			t.Fatalf("unexpected order %v", order)
+		}
+		seen := map[int]bool{}
+		for _, idx := range order {
+			seen[idx] = true
+		}
+		if len(seen) != 3 {
+			t.Fatalf("order is not a permutation: %v", order)
+		}
+		firsts[order[0]]++
+	}
+	if firsts[1] < firsts[0] || firsts[1] < firsts[2] {
+		t.Fatalf("expected heaviest candidate to lead most often, got %v", firsts)
+	}
+}
@@ -175,7 +175,7 @@ func (r *PluginRunner) handleWaitingEvents(ctx context.Context, runID RunID, wai
 			continue
 		}
 
-		var results []RunsToolResultItemV0
+		var calls []llm.ToolCall
 		for _, call := range ev.Waiting.PendingToolCalls {
 			toolCallID := call.ToolCall.ID
 			if toolCallID == "" {
@@ -189,16 +189,20 @@ func (r *PluginRunner) handleWaitingEvents(ctx context.Context, runID RunID, wai
 				continue
 			}
 
-			tc := llm.ToolCall{
+			calls = append(calls, llm.ToolCall{
 				ID:       toolCallID,
 				Type:     llm.ToolTypeFunction,
 				Function: &llm.FunctionCall{Name: name, Arguments: call.ToolCall.Arguments},
-			}
-			execRes := registry.Execute(tc)
+			})
+		}
+
+		// ExecuteAllContext honors the registry's concurrency settings and keeps input order.
+		var results []RunsToolResultItemV0
+		for _, execRes := range registry.ExecuteAllContext(ctx, calls) {
 			results = append(results, RunsToolResultItemV0{
 				ToolCall: ToolCall{
-					ID:   toolCallID,
-					Name: ToolName(name),
+					ID:   execRes.ToolCallID,
+					Name: execRes.ToolName,
 				},
 				Output: toolExecutionOutput(execRes),
 			})
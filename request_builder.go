@@ -26,6 +26,9 @@ type ResponseBuilder struct {
 	timeout    *time.Duration
 	stream     StreamTimeouts
 	retry      *RetryConfig
+
+	conversation *responseConversation
+	router       *ModelRouter
 }
 
 // New returns a fresh builder. Set either Model(...) or CustomerID(...).
@@ -327,6 +330,23 @@ func (b ResponseBuilder) DisableRetry() ResponseBuilder {
 	return b
 }
 
+// Router routes the request through router (see WithModelRouter). Model may be
+// omitted when a router is set.
+func (b ResponseBuilder) Router(router *ModelRouter) ResponseBuilder {
+	b.router = router
+	return b
+}
+
+// Conversation loads and records history in store under id (see WithConversation).
+func (b ResponseBuilder) Conversation(store ConversationStore, id string) ResponseBuilder {
+	if store == nil {
+		b.conversation = nil
+		return b
+	}
+	b.conversation = &responseConversation{store: store, id: id}
+	return b
+}
+
 func (b ResponseBuilder) Build() (ResponseRequest, []ResponseOption, error) {
 	opts := b.buildOptions()
 	callOpts := buildResponseCallOptions(opts)
@@ -334,6 +354,9 @@ func (b ResponseBuilder) Build() (ResponseRequest, []ResponseOption, error) {
 	if requireModel && b.client != nil && b.client.hasJWTAccessToken() {
 		requireModel = false
 	}
+	if b.router != nil {
+		requireModel = false
+	}
 	if err := b.req.validate(requireModel); err != nil {
 		return ResponseRequest{}, nil, err
 	}
@@ -363,6 +386,12 @@ func (b ResponseBuilder) buildOptions() []ResponseOption {
 	if b.retry != nil {
 		opts = append(opts, WithRetry(*b.retry))
 	}
+	if b.router != nil {
+		opts = append(opts, WithModelRouter(b.router))
+	}
+	if b.conversation != nil {
+		opts = append(opts, WithConversation(b.conversation.store, b.conversation.id))
+	}
 
 	return opts
 }
@@ -0,0 +1,392 @@
+package sdk
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/headers"
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// ResponseCache stores Responses keyed by ResponseCacheKey.
+//
+// The SDK ships an in-memory LRU (NewMemoryResponseCache) and a directory of
+// JSON files (NewFileResponseCache). Caches are enabled per call with
+// WithResponseCache.
+type ResponseCache interface {
+	// Get returns the cached response for key, or false when it is missing or expired.
+	Get(ctx context.Context, key string) (*Response, bool, error)
+	// Set stores resp under key. A zero ttl means the entry does not expire.
+	Set(ctx context.Context, key string, resp *Response, ttl time.Duration) error
+}
+
+// ResponseCachePolicy controls which calls use the cache and for how long.
+type ResponseCachePolicy struct {
+	// TTL is how long stored responses stay valid. Zero keeps them until evicted.
+	TTL time.Duration
+	// DeterministicOnly restricts caching to requests with temperature set to 0.
+	DeterministicOnly bool
+}
+
+type responseCacheOptions struct {
+	cache  ResponseCache
+	policy ResponseCachePolicy
+}
+
+// WithResponseCache serves identical requests from cache instead of calling
+// the API. ResponsesClient.Create stores successful responses; Stream stores
+// streams that complete and replays hits as synthetic StreamEvents. Requests
+// bound to a state handle are never cached.
+func WithResponseCache(cache ResponseCache, policy ResponseCachePolicy) ResponseOption {
+	return func(opts *responseCallOptions) {
+		if cache == nil {
+			return
+		}
+		opts.cache = &responseCacheOptions{cache: cache, policy: policy}
+	}
+}
+
+// applies reports whether the cache should be consulted for req.
+func (o *responseCacheOptions) applies(req ResponseRequest) bool {
+	if o == nil || req.stateID != nil {
+		return false
+	}
+	if o.policy.DeterministicOnly && (req.temperature == nil || *req.temperature != 0) {
+		return false
+	}
+	return true
+}
+
+// ResponseCacheKey returns the canonical cache key of req: a hex SHA-256 over
+// the provider, model, input, tools, tool choice, output format, temperature,
+// max output tokens and stop sequences.
+func ResponseCacheKey(req ResponseRequest) (string, error) {
+	canonical := struct {
+		Provider        string            `json:"provider"`
+		Model           string            `json:"model"`
+		Input           []llm.InputItem   `json:"input"`
+		Tools           []llm.Tool        `json:"tools"`
+		ToolChoice      *llm.ToolChoice   `json:"tool_choice"`
+		OutputFormat    *llm.OutputFormat `json:"output_format"`
+		Temperature     *float64          `json:"temperature"`
+		MaxOutputTokens int64             `json:"max_output_tokens"`
+		Stop            []string          `json:"stop"`
+	}{
+		Provider:        req.provider.String(),
+		Model:           req.model.String(),
+		Input:           req.input,
+		Tools:           req.tools,
+		ToolChoice:      req.toolChoice,
+		OutputFormat:    req.outputFormat,
+		Temperature:     req.temperature,
+		MaxOutputTokens: req.maxOutputTokens,
+		Stop:            req.stop,
+	}
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("sdk: response cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lookupCachedResponse returns the cache key and cached response for req,
+// reporting the outcome to telemetry. Customer-attributed calls are keyed per
+// customer since the customer's tier may pick the model.
+func (c *Client) lookupCachedResponse(ctx context.Context, callOpts responseCallOptions, req ResponseRequest) (string, *Response) {
+	opts := callOpts.cache
+	if !opts.applies(req) {
+		return "", nil
+	}
+	key, err := ResponseCacheKey(req)
+	if err != nil {
+		return "", nil
+	}
+	if customerID := callOpts.headers.Get(headers.CustomerID); customerID != "" {
+		sum := sha256.Sum256([]byte(key + "\x00" + customerID))
+		key = hex.EncodeToString(sum[:])
+	}
+	resp, ok, err := opts.cache.Get(ctx, key)
+	if err != nil {
+		c.telemetry.log(ctx, LogLevelError, "response_cache_get_failed", map[string]any{"error": err.Error()})
+	}
+	result := "miss"
+	if ok && resp != nil {
+		result = "hit"
+	} else {
+		resp = nil
+	}
+	c.telemetry.metric(ctx, "sdk_response_cache_total", 1, map[string]string{"result": result, "model": req.model.String()})
+	return key, resp
+}
+
+func (c *Client) storeCachedResponse(ctx context.Context, opts *responseCacheOptions, key string, resp *Response) {
+	if key == "" || resp == nil {
+		return
+	}
+	if err := opts.cache.Set(ctx, key, resp, opts.policy.TTL); err != nil {
+		c.telemetry.log(ctx, LogLevelError, "response_cache_set_failed", map[string]any{"error": err.Error()})
+	}
+}
+
+// cachedResponseEvents renders resp as the start, update and completion
+// events a live stream would have produced.
+func cachedResponseEvents(resp *Response) []StreamEvent {
+	text := resp.AssistantText()
+	var toolCalls []llm.ToolCall
+	for _, item := range resp.Output {
+		toolCalls = append(toolCalls, item.ToolCalls...)
+	}
+	usage := resp.Usage
+	events := []StreamEvent{{
+		Kind:       llm.StreamEventKindMessageStart,
+		Name:       "start",
+		ResponseID: resp.ID,
+		Model:      resp.Model,
+	}}
+	if text != "" {
+		events = append(events, StreamEvent{
+			Kind:       llm.StreamEventKindMessageDelta,
+			Name:       "update",
+			ResponseID: resp.ID,
+			Model:      resp.Model,
+			TextDelta:  text,
+		})
+	}
+	return append(events, StreamEvent{
+		Kind:       llm.StreamEventKindMessageStop,
+		Name:       "completion",
+		ResponseID: resp.ID,
+		Model:      resp.Model,
+		StopReason: resp.StopReason,
+		TextDelta:  text,
+		ToolCalls:  toolCalls,
+		Usage:      &usage,
+	})
+}
+
+// cachingStreamReader records a live stream and stores the collected response
+// once the stream completes without error.
+type cachingStreamReader struct {
+	streamReader
+	events []StreamEvent
+	failed bool
+	stored bool
+	store  func([]StreamEvent)
+}
+
+func (r *cachingStreamReader) Next() (StreamEvent, bool, error) {
+	ev, ok, err := r.streamReader.Next()
+	switch {
+	case err != nil:
+		r.failed = true
+	case ok:
+		if ev.ErrorStatus > 0 {
+			r.failed = true
+		}
+		r.events = append(r.events, ev)
+	case !r.failed && !r.stored:
+		r.stored = true
+		r.store(r.events)
+	}
+	return ev, ok, err
+}
+
+func (c *Client) cachingStream(ctx context.Context, opts *responseCacheOptions, key string, stream streamReader) streamReader {
+	if key == "" {
+		return stream
+	}
+	return &cachingStreamReader{streamReader: stream, store: func(events []StreamEvent) {
+		completed := false
+		for _, ev := range events {
+			completed = completed || ev.Kind == llm.StreamEventKindMessageStop
+		}
+		if !completed {
+			return
+		}
+		resp, err := newResponseStream(&StreamHandle{stream: &mockStreamReader{events: events}}).Collect(ctx)
+		if err == nil {
+			c.storeCachedResponse(ctx, opts, key, resp)
+		}
+	}}
+}
+
+// MemoryResponseCache is an in-memory LRU ResponseCache. It is safe for concurrent use.
+type MemoryResponseCache struct {
+	capacity int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryResponseEntry struct {
+	key       string
+	resp      Response
+	expiresAt time.Time
+}
+
+// NewMemoryResponseCache returns an LRU cache holding at most capacity
+// responses. A capacity of zero or less means unbounded.
+func NewMemoryResponseCache(capacity int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements ResponseCache.
+func (c *MemoryResponseCache) Get(_ context.Context, key string) (*Response, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryResponseEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	resp := entry.resp
+	return &resp, true, nil
+}
+
+// Set implements ResponseCache.
+func (c *MemoryResponseCache) Set(_ context.Context, key string, resp *Response, ttl time.Duration) error {
+	if resp == nil {
+		return nil
+	}
+	entry := &memoryResponseEntry{key: key, resp: *resp}
+	entry.resp.RequestID = ""
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryResponseEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached entries, including expired ones not yet evicted.
+func (c *MemoryResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// FileResponseCache stores each response as a JSON file in a directory, so
+// cached results survive process restarts (e.g. across CI runs).
+type FileResponseCache struct {
+	dir string
+	now func() time.Time
+}
+
+type fileResponseEntry struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Response  Response   `json:"response"`
+}
+
+// NewFileResponseCache returns a cache rooted at dir, creating the directory
+// if it does not exist.
+func NewFileResponseCache(dir string) (*FileResponseCache, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, ConfigError{Reason: "directory is required for FileResponseCache"}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("sdk: create response cache directory: %w", err)
+	}
+	return &FileResponseCache{dir: dir, now: time.Now}, nil
+}
+
+func (c *FileResponseCache) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\.`) {
+		return "", fmt.Errorf("sdk: invalid response cache key %q", key)
+	}
+	return filepath.Join(c.dir, key+".json"), nil
+}
+
+// Get implements ResponseCache.
+func (c *FileResponseCache) Get(_ context.Context, key string) (*Response, bool, error) {
+	path, err := c.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var entry fileResponseEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("sdk: decode cached response %s: %w", key, err)
+	}
+	if entry.ExpiresAt != nil && !c.now().Before(*entry.ExpiresAt) {
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	return &entry.Response, true, nil
+}
+
+// Set implements ResponseCache. Entries are written to a temporary file and
+// renamed into place so concurrent readers never see partial JSON.
+func (c *FileResponseCache) Set(_ context.Context, key string, resp *Response, ttl time.Duration) error {
+	if resp == nil {
+		return nil
+	}
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	entry := fileResponseEntry{Response: *resp}
+	if ttl > 0 {
+		expires := c.now().Add(ttl)
+		entry.ExpiresAt = &expires
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,180 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+)
+
+func TestResponsesCreateCacheHit(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","model":"demo","stop_reason":"end_turn","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"cached answer"}]}],"usage":{"input_tokens":1,"output_tokens":2,"total_tokens":3}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var mu sync.Mutex
+	results := map[string]int{}
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL),
+		WithTelemetry(TelemetryHooks{OnMetric: func(_ context.Context, m Metric) {
+			if m.Name == "sdk_response_cache_total" {
+				mu.Lock()
+				results[m.Labels["result"]]++
+				mu.Unlock()
+			}
+		}}),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+
+	cache := NewMemoryResponseCache(8)
+	req, opts, _ := client.Responses.New().Model(NewModelID("demo")).Temperature(0).User("hi").Build()
+	opts = append(opts, WithResponseCache(cache, ResponseCachePolicy{TTL: time.Minute, DeterministicOnly: true}))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Responses.Create(ctx, req, opts...)
+		if err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+		if resp.AssistantText() != "cached answer" {
+			t.Fatalf("unexpected text %q", resp.AssistantText())
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected one API call, got %d", calls.Load())
+	}
+	if results["miss"] != 1 || results["hit"] != 2 {
+		t.Fatalf("unexpected cache metrics: %v", results)
+	}
+
+	// Cached results replay through Stream without reaching the API.
+	stream, err := client.Responses.Stream(ctx, req, opts...)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	resp, err := stream.Collect(ctx)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if resp.AssistantText() != "cached answer" || resp.StopReason != StopReasonEndTurn || resp.Usage.TotalTokens != 3 {
+		t.Fatalf("unexpected replayed response: %+v", resp)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected stream to be served from cache, got %d calls", calls.Load())
+	}
+
+	// Non-zero temperature bypasses a DeterministicOnly cache.
+	warm, warmOpts, _ := client.Responses.New().Model(NewModelID("demo")).Temperature(0.7).User("hi").Build()
+	warmOpts = append(warmOpts, WithResponseCache(cache, ResponseCachePolicy{DeterministicOnly: true}))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Responses.Create(ctx, warm, warmOpts...); err != nil {
+			t.Fatalf("create warm %d: %v", i, err)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected uncached calls for temperature 0.7, got %d", calls.Load())
+	}
+}
+
+func TestResponsesStreamStoresCompletedStream(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != routes.Responses {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"type":"start","request_id":"resp_1","model":"demo"}` + "\n"))
+		_, _ = w.Write([]byte(`{"type":"update","delta":"Hello"}` + "\n"))
+		_, _ = w.Write([]byte(`{"type":"completion","content":"Hello","usage":{"input_tokens":1,"output_tokens":2,"total_tokens":3}}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	cache := NewMemoryResponseCache(0)
+	req, opts, _ := client.Responses.New().Model(NewModelID("demo")).User("hi").Build()
+	opts = append(opts, WithResponseCache(cache, ResponseCachePolicy{}))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		stream, err := client.Responses.Stream(ctx, req, opts...)
+		if err != nil {
+			t.Fatalf("stream %d: %v", i, err)
+		}
+		resp, err := stream.Collect(ctx)
+		if err != nil {
+			t.Fatalf("collect %d: %v", i, err)
+		}
+		if resp.AssistantText() != "Hello" {
+			t.Fatalf("stream %d: unexpected text %q", i, resp.AssistantText())
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected second stream to replay from cache, got %d calls", calls.Load())
+	}
+}
+
+func TestMemoryResponseCacheEvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	cache := NewMemoryResponseCache(2)
+	cache.now = func() time.Time { return now }
+
+	_ = cache.Set(ctx, "a", &Response{ID: "a"}, 0)
+	_ = cache.Set(ctx, "b", &Response{ID: "b"}, time.Minute)
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	_ = cache.Set(ctx, "c", &Response{ID: "c"}, 0)
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Fatal("expected least recently used entry b to be evicted")
+	}
+
+	_ = cache.Set(ctx, "d", &Response{ID: "d"}, time.Minute)
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := cache.Get(ctx, "d"); ok {
+		t.Fatal("expected d to expire")
+	}
+	if resp, ok, _ := cache.Get(ctx, "c"); !ok || resp.ID != "c" {
+		t.Fatalf("expected c without ttl to remain, got %v %v", resp, ok)
+	}
+}
+
+func TestFileResponseCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	cache, err := NewFileResponseCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("cache: %v", err)
+	}
+	cache.now = func() time.Time { return now }
+
+	req, _, _ := (ResponseBuilder{}).Model(NewModelID("demo")).User("hi").Build()
+	key, err := ResponseCacheKey(req)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	if err := cache.Set(ctx, key, &Response{ID: "resp_1", Model: NewModelID("demo")}, time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	resp, ok, err := cache.Get(ctx, key)
+	if err != nil || !ok || resp.ID != "resp_1" {
+		t.Fatalf("get: resp=%v ok=%v err=%v", resp, ok, err)
+	}
+	now = now.Add(time.Hour)
+	if _, ok, _ := cache.Get(ctx, key); ok {
+		t.Fatal("expected entry to expire")
+	}
+	if _, _, err := cache.Get(ctx, "../escape"); err == nil {
+		t.Fatal("expected invalid key error")
+	}
+}
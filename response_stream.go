@@ -33,16 +33,7 @@ func (s *responseStream) CollectWithMetrics(ctx context.Context) (*Response, Res
 		startedAt = time.Now()
 	}
 
-	var usage *Usage
-	var stop StopReason
-	var model ModelID
-	var responseID string
-	var finalContent string
-	var contentBuilder strings.Builder
-	var sawDelta bool
-	var toolCalls []llm.ToolCall
-	var firstToken time.Time
-
+	c := newResponseCollector(s.handle.RequestID)
 	for {
 		select {
 		case <-ctx.Done():
@@ -63,108 +54,142 @@ func (s *responseStream) CollectWithMetrics(ctx context.Context) (*Response, Res
 			if msg == "" {
 				msg = "stream error"
 			}
-			metrics := ResponseStreamMetrics{
-				Duration: time.Since(startedAt),
-				Model:    model,
-				ID:       responseID,
-				Usage:    usage,
-			}
-			if !firstToken.IsZero() {
-				metrics.TTFT = firstToken.Sub(startedAt)
-			}
-			if metrics.TTFT < 0 {
-				metrics.TTFT = 0
-			}
-			if metrics.Duration < 0 {
-				metrics.Duration = 0
-			}
-			return nil, metrics, APIError{
+			return nil, c.metrics(startedAt), APIError{
 				Status:    ev.ErrorStatus,
 				Code:      APIErrorCode(strings.TrimSpace(ev.ErrorCode)),
 				Message:   msg,
 				RequestID: s.handle.RequestID,
 			}
 		}
+		c.add(ev)
+	}
+	return c.response(), c.metrics(startedAt), nil
+}
+
+// responseCollector aggregates stream events into a Response. It backs both
+// Collect and the per-turn aggregation of AgentStream.
+type responseCollector struct {
+	requestID  string
+	responseID string
+	model      ModelID
+	stop       StopReason
+	usage      *Usage
+	firstToken time.Time
+
+	text      strings.Builder
+	final     string
+	sawDelta  bool
+	toolCalls []llm.ToolCall
+	acc       *ToolCallAccumulator
+}
+
+func newResponseCollector(requestID string) *responseCollector {
+	return &responseCollector{requestID: requestID, acc: NewToolCallAccumulator()}
+}
+
+// add records ev and returns any new text and reasoning fragments.
+func (c *responseCollector) add(ev StreamEvent) (text string, reasoning string) {
+	if ev.ResponseID != "" {
+		c.responseID = ev.ResponseID
+	}
+	if !ev.Model.IsEmpty() {
+		c.model = ev.Model
+	}
+	if ev.StopReason != "" {
+		c.stop = ev.StopReason
+	}
+	if ev.Usage != nil {
+		c.usage = ev.Usage
+	}
+	if ev.ToolCallDelta != nil {
+		c.acc.ProcessDelta(ev.ToolCallDelta)
+	}
+	if len(ev.ToolCalls) > 0 {
+		c.toolCalls = mergeToolCalls(c.toolCalls, ev.ToolCalls)
+	}
 
-		if ev.ResponseID != "" {
-			responseID = ev.ResponseID
+	switch ev.Kind {
+	case llm.StreamEventKindReasoningDelta:
+		// Reasoning tokens count toward TTFT. For reasoning models, the first
+		// token arrives during the reasoning phase, which is the correct moment
+		// to measure TTFT (not after reasoning completes).
+		if ev.ReasoningDelta != "" {
+			c.markFirstToken()
+			reasoning = ev.ReasoningDelta
 		}
-		if !ev.Model.IsEmpty() {
-			model = ev.Model
+	case llm.StreamEventKindMessageDelta:
+		if ev.TextDelta != "" {
+			c.markFirstToken()
+			c.sawDelta = true
+			c.text.WriteString(ev.TextDelta)
+			text = ev.TextDelta
 		}
-		switch ev.Kind {
-		case llm.StreamEventKindReasoningDelta:
-			// Reasoning tokens count toward TTFT. For reasoning models, the first
-			// token arrives during the reasoning phase, which is the correct moment
-			// to measure TTFT (not after reasoning completes).
-			if ev.ReasoningDelta != "" && firstToken.IsZero() {
-				firstToken = time.Now()
+	case llm.StreamEventKindMessageStop:
+		if ev.TextDelta != "" {
+			c.markFirstToken()
+			// Completion payload may include the full content; treat it as authoritative.
+			c.final = ev.TextDelta
+			if !c.sawDelta {
+				text = ev.TextDelta
 			}
-		case llm.StreamEventKindMessageDelta:
-			if ev.TextDelta != "" {
-				if firstToken.IsZero() {
-					firstToken = time.Now()
-				}
-				sawDelta = true
-				contentBuilder.WriteString(ev.TextDelta)
-			}
-		case llm.StreamEventKindMessageStop:
-			if ev.TextDelta != "" {
-				if firstToken.IsZero() {
-					firstToken = time.Now()
-				}
-				// Completion payload may include the full content; treat it as authoritative.
-				finalContent = ev.TextDelta
-			}
-		default:
-			// Ignore non-text events.
-		}
-		if ev.StopReason != "" {
-			stop = ev.StopReason
-		}
-		if ev.Usage != nil {
-			usage = ev.Usage
-		}
-		if len(ev.ToolCalls) > 0 {
-			toolCalls = append(toolCalls[:0], ev.ToolCalls...)
 		}
+	default:
+		// Other events only contribute metadata.
 	}
+	return text, reasoning
+}
 
-	if finalContent == "" && (sawDelta || contentBuilder.Len() > 0) {
-		finalContent = contentBuilder.String()
+func (c *responseCollector) markFirstToken() {
+	if c.firstToken.IsZero() {
+		c.firstToken = time.Now()
+	}
+}
+
+func (c *responseCollector) response() *Response {
+	content := c.final
+	if content == "" {
+		content = c.text.String()
+	}
+	toolCalls := c.toolCalls
+	if len(toolCalls) == 0 {
+		toolCalls = c.acc.GetToolCalls()
 	}
 
 	var output []llm.OutputItem
-	if finalContent != "" || len(toolCalls) > 0 {
+	if content != "" || len(toolCalls) > 0 {
 		item := llm.OutputItem{
 			Type:      llm.OutputItemTypeMessage,
 			Role:      llm.RoleAssistant,
 			ToolCalls: toolCalls,
 		}
-		if finalContent != "" {
-			item.Content = []llm.ContentPart{llm.TextPart(finalContent)}
+		if content != "" {
+			item.Content = []llm.ContentPart{llm.TextPart(content)}
 		}
 		output = []llm.OutputItem{item}
 	}
 
 	resp := &Response{
-		ID:         responseID,
-		Model:      model,
+		ID:         c.responseID,
+		Model:      c.model,
 		Output:     output,
-		StopReason: stop,
-		RequestID:  s.handle.RequestID,
+		StopReason: c.stop,
+		RequestID:  c.requestID,
 	}
-	if usage != nil {
-		resp.Usage = *usage
+	if c.usage != nil {
+		resp.Usage = *c.usage
 	}
+	return resp
+}
+
+func (c *responseCollector) metrics(startedAt time.Time) ResponseStreamMetrics {
 	metrics := ResponseStreamMetrics{
 		Duration: time.Since(startedAt),
-		Model:    model,
-		ID:       responseID,
-		Usage:    usage,
+		Model:    c.model,
+		ID:       c.responseID,
+		Usage:    c.usage,
 	}
-	if !firstToken.IsZero() {
-		metrics.TTFT = firstToken.Sub(startedAt)
+	if !c.firstToken.IsZero() {
+		metrics.TTFT = c.firstToken.Sub(startedAt)
 	}
 	if metrics.TTFT < 0 {
 		metrics.TTFT = 0
@@ -172,5 +197,23 @@ func (s *responseStream) CollectWithMetrics(ctx context.Context) (*Response, Res
 	if metrics.Duration < 0 {
 		metrics.Duration = 0
 	}
-	return resp, metrics, nil
+	return metrics
+}
+
+// mergeToolCalls appends calls not already present (by ID) and replaces existing ones.
+func mergeToolCalls(existing []llm.ToolCall, calls []llm.ToolCall) []llm.ToolCall {
+	for _, call := range calls {
+		replaced := false
+		for i := range existing {
+			if call.ID != "" && existing[i].ID == call.ID {
+				existing[i] = call
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			existing = append(existing, call)
+		}
+	}
+	return existing
 }
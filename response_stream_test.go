@@ -106,3 +106,21 @@ func TestResponseStreamCollectHandlesReaderError(t *testing.T) {
 		t.Fatalf("expected reader error, got %v", err)
 	}
 }
+
+func TestResponseStreamCollectAssemblesToolCallDeltas(t *testing.T) {
+	handle := &StreamHandle{stream: &testStream{events: []StreamEvent{
+		{Kind: llm.StreamEventKindToolUseStart, ToolCallDelta: &llm.ToolCallDelta{Index: 0, ID: "call_1", Type: "function", Function: &llm.FunctionCallDelta{Name: "echo"}}},
+		{Kind: llm.StreamEventKindToolUseDelta, ToolCallDelta: &llm.ToolCallDelta{Index: 0, Function: &llm.FunctionCallDelta{Arguments: `{"message":`}}},
+		{Kind: llm.StreamEventKindToolUseDelta, ToolCallDelta: &llm.ToolCallDelta{Index: 0, Function: &llm.FunctionCallDelta{Arguments: `"hi"}`}}},
+		{Kind: llm.StreamEventKindMessageStop, StopReason: StopReasonToolCalls},
+	}}}
+
+	resp, err := newResponseStream(handle).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	calls := resp.ToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call_1" || GetToolName(calls[0]) != "echo" || GetToolArgsRaw(calls[0]) != `{"message":"hi"}` {
+		t.Fatalf("expected tool call assembled from deltas, got %+v", calls)
+	}
+}
@@ -21,6 +21,10 @@ type RetryMetadata struct {
 	LastBackoff time.Duration
 	LastStatus  int
 	LastError   string
+	// Model and Provider identify the candidate that was tried when the call
+	// was routed through a ModelRouter.
+	Model    ModelID
+	Provider ProviderID
 }
 
 func defaultRetryConfig() RetryConfig {
@@ -83,6 +83,9 @@ const (
 	// WorkflowsCompile compiles a workflow spec (workflow) into a canonical plan and plan_hash.
 	WorkflowsCompile = "/workflows/compile"
 
+	// WorkflowsLint lints a workflow spec and optionally compiles it.
+	WorkflowsLint = "/workflows/lint"
+
 	// RunEventSchema returns the run event envelope v0 JSON Schema (draft-07).
 	RunEventSchema = "/schemas/run_event.schema.json"
 
@@ -0,0 +1,260 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+// RunOutputIssue describes one run output that could not be bound.
+type RunOutputIssue struct {
+	Output OutputName
+	Error  StructuredErrorDetail
+}
+
+// RunOutputsError is returned when run outputs are missing, cannot be decoded
+// into their fields, or do not match their node's output schema.
+type RunOutputsError struct {
+	Issues []RunOutputIssue
+}
+
+func (e RunOutputsError) Error() string {
+	parts := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		parts = append(parts, fmt.Sprintf("%s: %s", issue.Output, structuredErrorMessage(issue.Error)))
+	}
+	return "run outputs: " + strings.Join(parts, "; ")
+}
+
+// RunFailedError is returned by CreateAndWait when a run fails or is
+// canceled.
+type RunFailedError struct {
+	RunID  RunID
+	Status RunStatus
+	Cause  NodeError
+}
+
+func (e RunFailedError) Error() string {
+	return fmt.Sprintf("run %s %s: %s", e.RunID, e.Status, e.Cause.Message)
+}
+
+type runOutputsOptions struct {
+	spec *WorkflowSpec
+}
+
+// RunOutputsOption configures DecodeRunOutputs.
+type RunOutputsOption func(*runOutputsOptions)
+
+// WithRunOutputsSpec supplies the spec that produced the run. Outputs whose
+// source node declares a json_schema OutputFormat are then validated against
+// that schema, and response envelopes from those nodes are reduced to their
+// JSON text before decoding.
+func WithRunOutputsSpec(spec WorkflowSpec) RunOutputsOption {
+	return func(o *runOutputsOptions) { o.spec = &spec }
+}
+
+// DecodeRunOutputs binds run outputs to the fields of struct type T.
+//
+// Each exported field takes the output named by its `output` tag, falling
+// back to its `json` tag and then its name; "-" skips the field. Outputs are
+// required unless the field is a pointer or tagged `output:"name,optional"`.
+// Outputs that carry JSON as text, such as those selected with
+// LLMTextOutputPointer, are parsed before decoding into non-string fields;
+// string fields receive the output as-is.
+//
+// All problems are collected into a RunOutputsError whose issues use
+// StructuredErrorDetail, as Structured does for single responses.
+//
+// Example:
+//
+//	type Report struct {
+//		Summary string   `output:"summary"`
+//		Verdict Verdict  `output:"verdict"`
+//		Notes   []string `output:"notes,optional"`
+//	}
+//
+//	run, _ := client.Runs.Get(ctx, runID)
+//	report, err := sdk.DecodeRunOutputs[Report](run.Outputs, sdk.WithRunOutputsSpec(spec))
+func DecodeRunOutputs[T any](outputs map[OutputName]json.RawMessage, opts ...RunOutputsOption) (T, error) {
+	var out T
+	var options runOutputsOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	rv := reflect.ValueOf(&out).Elem()
+	if rv.Kind() != reflect.Struct {
+		return out, ConfigError{Reason: fmt.Sprintf("run outputs must decode into a struct, got %s", rv.Type())}
+	}
+
+	var issues []RunOutputIssue
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		name, optional, ok := runOutputFieldName(field)
+		if !ok {
+			continue
+		}
+		raw, present := outputs[OutputName(name)]
+		if !present || string(bytes.TrimSpace(raw)) == "null" {
+			if !optional && field.Type.Kind() != reflect.Pointer {
+				issues = append(issues, RunOutputIssue{Output: OutputName(name), Error: StructuredErrorDetail{
+					Kind:    StructuredErrorKindDecode,
+					Message: "output is missing",
+				}})
+			}
+			continue
+		}
+
+		format := runOutputFormat(options.spec, name)
+		value := runOutputJSON(raw, format != nil)
+		if format != nil && len(format.JSONSchema.Schema) > 0 {
+			schema, err := compileSchema(format.JSONSchema.Schema)
+			if err != nil {
+				return out, err
+			}
+			if detail := validateAgainstSchema(schema, string(value)); detail != nil {
+				issues = append(issues, RunOutputIssue{Output: OutputName(name), Error: *detail})
+				continue
+			}
+		}
+		if field.Type.Kind() == reflect.String {
+			value = raw
+		}
+		if err := json.Unmarshal(value, rv.Field(i).Addr().Interface()); err != nil {
+			issues = append(issues, RunOutputIssue{Output: OutputName(name), Error: StructuredErrorDetail{
+				Kind:    StructuredErrorKindDecode,
+				Message: err.Error(),
+			}})
+		}
+	}
+	if len(issues) > 0 {
+		return out, RunOutputsError{Issues: issues}
+	}
+	return out, nil
+}
+
+// CreateAndWait starts a run, follows its events until it finishes and
+// decodes its outputs into T with DecodeRunOutputs, validating them against
+// spec. A failed or canceled run returns a RunFailedError.
+//
+// Example:
+//
+//	report, err := sdk.CreateAndWait[Report](ctx, client.Runs, spec,
+//		sdk.WithRunInputs(map[string]any{"ticket": text}))
+func CreateAndWait[T any](ctx context.Context, c *RunsClient, spec WorkflowSpec, opts ...RunCreateOption) (T, error) {
+	var zero T
+	created, err := c.Create(ctx, spec, opts...)
+	if err != nil {
+		return zero, err
+	}
+	final, err := c.Watch(ctx, created.RunID, func(context.Context, RunEvent) error { return nil })
+	if err != nil {
+		return zero, err
+	}
+	switch ev := final.(type) {
+	case RunEventRunFailedV0:
+		return zero, RunFailedError{RunID: created.RunID, Status: RunStatusFailed, Cause: ev.Error}
+	case RunEventRunCanceledV0:
+		return zero, RunFailedError{RunID: created.RunID, Status: RunStatusCanceled, Cause: ev.Error}
+	}
+	run, err := c.Get(ctx, created.RunID)
+	if err != nil {
+		return zero, err
+	}
+	return DecodeRunOutputs[T](run.Outputs, WithRunOutputsSpec(spec))
+}
+
+func runOutputFieldName(field reflect.StructField) (name string, optional, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag, hasTag := field.Tag.Lookup("output")
+	if !hasTag {
+		tag, _ = field.Tag.Lookup("json")
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, hasTag && opts == "optional", true
+}
+
+// runOutputFormat returns the structured output format of the node behind an
+// output, when the output selects the node's whole response or its text.
+func runOutputFormat(spec *WorkflowSpec, name string) *llm.OutputFormat {
+	if spec == nil {
+		return nil
+	}
+	for _, o := range spec.Outputs {
+		if o.Name != name || (o.Pointer != "" && o.Pointer != string(LLMTextOutputPointer)) {
+			continue
+		}
+		for _, n := range spec.Nodes {
+			if n.ID == o.From && n.Type == workflowintent.NodeTypeLLM && n.OutputFormat.IsStructured() && n.OutputFormat.JSONSchema != nil {
+				return n.OutputFormat
+			}
+		}
+	}
+	return nil
+}
+
+// runOutputJSON unwraps JSON carried as text: a JSON string holding a JSON
+// document, or, for structured nodes, a response envelope.
+func runOutputJSON(raw json.RawMessage, structured bool) json.RawMessage {
+	raw = bytes.TrimSpace(raw)
+	var text string
+	switch {
+	case len(raw) > 0 && raw[0] == '"':
+		if json.Unmarshal(raw, &text) != nil {
+			return raw
+		}
+	case structured && len(raw) > 0 && raw[0] == '{':
+		var envelope struct {
+			Output []llm.OutputItem `json:"output"`
+		}
+		if json.Unmarshal(raw, &envelope) != nil || len(envelope.Output) == 0 {
+			return raw
+		}
+		var b strings.Builder
+		for _, item := range envelope.Output {
+			if item.Type != llm.OutputItemTypeMessage || item.Role != llm.RoleAssistant {
+				continue
+			}
+			for _, part := range item.Content {
+				b.WriteString(part.Text)
+			}
+		}
+		text = b.String()
+	default:
+		return raw
+	}
+	if trimmed := strings.TrimSpace(text); json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	return raw
+}
+
+func structuredErrorMessage(detail StructuredErrorDetail) string {
+	if detail.Kind == StructuredErrorKindDecode {
+		return detail.Message
+	}
+	parts := make([]string, 0, len(detail.Issues))
+	for _, issue := range detail.Issues {
+		if issue.Path != nil {
+			parts = append(parts, fmt.Sprintf("%s: %s", *issue.Path, issue.Message))
+		} else {
+			parts = append(parts, issue.Message)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,156 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+)
+
+type runOutputsVerdict struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+type runOutputsReport struct {
+	Summary string            `output:"summary"`
+	Verdict runOutputsVerdict `output:"verdict"`
+	Scores  []int             `json:"scores"`
+	Notes   *string           `output:"notes"`
+	Extra   string            `output:"extra,optional"`
+	Ignored string            `output:"-"`
+}
+
+func runOutputsSpec(t *testing.T) WorkflowSpec {
+	t.Helper()
+	spec, err := WorkflowIntent().
+		Model("demo").
+		LLM("judge", func(n LLMNodeBuilder) LLMNodeBuilder {
+			return n.User("judge").OutputFormat(*MustOutputFormatFromType[runOutputsVerdict]("verdict"))
+		}).
+		LLM("summarize", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("summarize") }).
+		Output("verdict", "judge").
+		OutputWithPointer("summary", "summarize", string(LLMTextOutputPointer)).
+		Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	return spec
+}
+
+func TestDecodeRunOutputs(t *testing.T) {
+	spec := runOutputsSpec(t)
+	envelope, _ := json.Marshal(localTextResponse(`{"approved":true,"reason":"fine"}`))
+	outputs := map[OutputName]json.RawMessage{
+		"summary": json.RawMessage(`"All good."`),
+		"verdict": envelope,
+		"scores":  json.RawMessage(`"[1, 2, 3]"`),
+	}
+	report, err := DecodeRunOutputs[runOutputsReport](outputs, WithRunOutputsSpec(spec))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.Summary != "All good." || !report.Verdict.Approved || report.Verdict.Reason != "fine" || len(report.Scores) != 3 || report.Notes != nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	outputs["verdict"] = json.RawMessage(`"{\"approved\":\"yes\"}"`)
+	delete(outputs, "summary")
+	_, err = DecodeRunOutputs[runOutputsReport](outputs, WithRunOutputsSpec(spec))
+	var outErr RunOutputsError
+	if !errors.As(err, &outErr) || len(outErr.Issues) != 2 {
+		t.Fatalf("expected two output issues, got %v", err)
+	}
+	if outErr.Issues[0].Output != "summary" || outErr.Issues[0].Error.Message != "output is missing" {
+		t.Fatalf("unexpected missing issue: %+v", outErr.Issues[0])
+	}
+	if detail := outErr.Issues[1].Error; outErr.Issues[1].Output != "verdict" || detail.Kind != StructuredErrorKindValidation || len(detail.Issues) == 0 {
+		t.Fatalf("unexpected schema issue: %+v", outErr.Issues[1])
+	}
+
+	verdictText := `{"approved":true,"reason":"fine"}`
+	raw, _ := json.Marshal(verdictText)
+	text, err := DecodeRunOutputs[struct {
+		Verdict string `output:"verdict"`
+	}](map[OutputName]json.RawMessage{"verdict": raw}, WithRunOutputsSpec(spec))
+	if err != nil || text.Verdict != verdictText {
+		t.Fatalf("expected structured output kept as text, got %q %v", text.Verdict, err)
+	}
+
+	if _, err := DecodeRunOutputs[map[string]any](outputs); err == nil {
+		t.Fatalf("expected error for non-struct target")
+	}
+}
+
+func TestCreateAndWait(t *testing.T) {
+	spec := runOutputsSpec(t)
+	runID := workflow.NewRunID()
+	failed, stalled := false, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/runs":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(RunsCreateResponse{RunID: runID, Status: RunStatusRunning, PlanHash: PlanHash(strings.Repeat("a", 64))})
+		case strings.HasSuffix(r.URL.Path, "/events"):
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			if stalled {
+				if r.URL.Query().Get("after_seq") == "" {
+					_, _ = w.Write([]byte(runWatchEventLine(t, runID, 1, workflow.EventRunStarted)))
+				}
+				return
+			}
+			_, _ = w.Write([]byte(runWatchEventLine(t, runID, 1, workflow.EventRunStarted)))
+			if failed {
+				hash := PlanHash(strings.Repeat("a", 64))
+				raw, _ := json.Marshal(RunEventEnvelope{
+					EnvelopeVersion: RunEventEnvelopeVersion, RunID: runID, Seq: 2, TS: time.Now().UTC(),
+					Type: RunEventRunFailed, PlanHash: &hash, Error: &NodeError{Message: "judge failed"},
+				})
+				_, _ = w.Write(append(raw, '\n'))
+				return
+			}
+			_, _ = w.Write([]byte(runWatchEventLine(t, runID, 2, workflow.EventRunCompleted)))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(RunsGetResponse{RunID: runID, Status: RunStatusSucceeded, Outputs: map[OutputName]json.RawMessage{
+				"summary": json.RawMessage(`"ok"`),
+				"verdict": json.RawMessage(`{"approved":false,"reason":"no"}`),
+				"scores":  json.RawMessage(`[4]`),
+			}})
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"), WithBaseURL(srv.URL),
+		WithRetryConfig(RetryConfig{MaxAttempts: 2, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}))
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	report, err := CreateAndWait[runOutputsReport](context.Background(), client.Runs, spec)
+	if err != nil {
+		t.Fatalf("create and wait: %v", err)
+	}
+	if report.Summary != "ok" || report.Verdict.Reason != "no" || report.Scores[0] != 4 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	failed = true
+	_, err = CreateAndWait[runOutputsReport](context.Background(), client.Runs, spec)
+	var runErr RunFailedError
+	if !errors.As(err, &runErr) || runErr.Status != RunStatusFailed || runErr.Cause.Message != "judge failed" {
+		t.Fatalf("expected RunFailedError, got %v", err)
+	}
+
+	failed, stalled = false, true
+	_, err = CreateAndWait[runOutputsReport](context.Background(), client.Runs, spec)
+	var transportErr TransportError
+	if !errors.As(err, &transportErr) || transportErr.Kind != TransportErrorEmptyResponse {
+		t.Fatalf("expected CreateAndWait to give up on a stalled run, got %v", err)
+	}
+}
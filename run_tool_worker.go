@@ -0,0 +1,302 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// UserAskAnswerer answers user.ask prompts raised by workflow runs.
+type UserAskAnswerer interface {
+	AnswerUserAsk(ctx context.Context, runID RunID, nodeID NodeID, ask NodeUserAsk) (UserAskToolResult, error)
+}
+
+// UserAskAnswererFunc adapts a function to UserAskAnswerer.
+type UserAskAnswererFunc func(ctx context.Context, runID RunID, nodeID NodeID, ask NodeUserAsk) (UserAskToolResult, error)
+
+// AnswerUserAsk implements UserAskAnswerer.
+func (f UserAskAnswererFunc) AnswerUserAsk(ctx context.Context, runID RunID, nodeID NodeID, ask NodeUserAsk) (UserAskToolResult, error) {
+	return f(ctx, runID, nodeID, ask)
+}
+
+type runToolWorkerOptions struct {
+	answerer       UserAskAnswerer
+	toolTimeout    time.Duration
+	maxConcurrency int
+	retry          *RetryConfig
+}
+
+// RunToolWorkerOption configures a RunToolWorker.
+type RunToolWorkerOption func(*runToolWorkerOptions)
+
+// WithRunToolWorkerAnswerer sets the provider used to answer user.ask calls.
+// Without one, a run that asks the user fails the worker.
+func WithRunToolWorkerAnswerer(a UserAskAnswerer) RunToolWorkerOption {
+	return func(o *runToolWorkerOptions) { o.answerer = a }
+}
+
+// WithRunToolWorkerToolTimeout bounds each tool call. A call that exceeds the
+// timeout is reported to the run as an error result.
+func WithRunToolWorkerToolTimeout(d time.Duration) RunToolWorkerOption {
+	return func(o *runToolWorkerOptions) { o.toolTimeout = d }
+}
+
+// WithRunToolWorkerConcurrency sets how many tool calls may run at the same
+// time across all runs handled by the worker. Values <= 1 (the default)
+// execute calls sequentially.
+func WithRunToolWorkerConcurrency(n int) RunToolWorkerOption {
+	return func(o *runToolWorkerOptions) { o.maxConcurrency = n }
+}
+
+// WithRunToolWorkerRetry overrides the client RetryConfig used to reconnect
+// run event streams.
+func WithRunToolWorkerRetry(cfg RetryConfig) RunToolWorkerOption {
+	return func(o *runToolWorkerOptions) { o.retry = &cfg }
+}
+
+// RunToolWorker executes client-side tool calls for workflow runs whose nodes
+// use client tool execution mode. It watches run events, executes pending
+// calls against a ToolRegistry, answers user.ask calls through a
+// UserAskAnswerer and submits the results.
+//
+// Pending calls are always re-read from RunsClient.PendingTools before they
+// are executed, so replayed waiting events and concurrent workers do not run
+// a call twice, and a submission rejected because the step was already
+// answered is treated as success.
+//
+// Example:
+//
+//	worker := sdk.NewRunToolWorker(client, registry,
+//		sdk.WithRunToolWorkerConcurrency(4),
+//		sdk.WithRunToolWorkerToolTimeout(30*time.Second),
+//	)
+//	final, err := worker.Work(ctx, created.RunID)
+type RunToolWorker struct {
+	client   *Client
+	registry *ToolRegistry
+	opts     runToolWorkerOptions
+	sem      chan struct{}
+}
+
+// NewRunToolWorker creates a worker that executes calls with registry.
+func NewRunToolWorker(client *Client, registry *ToolRegistry, opts ...RunToolWorkerOption) *RunToolWorker {
+	var options runToolWorkerOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	if options.maxConcurrency < 1 {
+		options.maxConcurrency = 1
+	}
+	if registry == nil {
+		registry = NewToolRegistry()
+	}
+	return &RunToolWorker{
+		client:   client,
+		registry: registry,
+		opts:     options,
+		sem:      make(chan struct{}, options.maxConcurrency),
+	}
+}
+
+// Work drives a single run until it reaches a terminal event, which is
+// returned. Inspect it to distinguish completion from failure or cancellation.
+func (w *RunToolWorker) Work(ctx context.Context, runID RunID) (RunEvent, error) {
+	if w == nil || w.client == nil {
+		return nil, errors.New("run tool worker: client required")
+	}
+	var watchOpts []RunWatchOption
+	if w.opts.retry != nil {
+		watchOpts = append(watchOpts, WithRunWatchRetry(*w.opts.retry))
+	}
+	handled := make(map[ToolCallID]struct{})
+	return w.client.Runs.Watch(ctx, runID, func(ctx context.Context, ev RunEvent) error {
+		switch e := ev.(type) {
+		case RunEventNodeWaitingV0:
+			return w.drain(ctx, runID, e.NodeID, e.Waiting.RequestID, handled)
+		case RunEventNodeUserAskV0:
+			return w.drain(ctx, runID, e.NodeID, e.UserAsk.RequestID, handled)
+		case RunEventNodeToolResultV0:
+			handled[e.ToolResult.ToolCall.ID] = struct{}{}
+		}
+		return nil
+	}, watchOpts...)
+}
+
+// WorkAll drives several runs concurrently until each reaches a terminal
+// event. Tool calls from all runs share the worker's concurrency limit. The
+// returned error joins the per-run errors.
+func (w *RunToolWorker) WorkAll(ctx context.Context, runIDs ...RunID) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, runID := range runIDs {
+		wg.Add(1)
+		go func(runID RunID) {
+			defer wg.Done()
+			if _, err := w.Work(ctx, runID); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("run %s: %w", runID, err))
+				mu.Unlock()
+			}
+		}(runID)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// drain executes and submits the calls still pending for a node request.
+func (w *RunToolWorker) drain(ctx context.Context, runID RunID, nodeID NodeID, requestID string, handled map[ToolCallID]struct{}) error {
+	pending, err := w.client.Runs.PendingTools(ctx, runID)
+	if err != nil {
+		return err
+	}
+	for _, node := range pending.Pending {
+		if node.NodeID != nodeID || node.RequestID != requestID {
+			continue
+		}
+		var calls []RunsPendingToolCall
+		for _, call := range node.ToolCalls {
+			if _, ok := handled[call.ToolCall.ID]; !ok && call.ToolCall.ID != "" {
+				calls = append(calls, call)
+			}
+		}
+		if len(calls) == 0 {
+			return nil
+		}
+		results, err := w.execute(ctx, runID, node, calls)
+		if err != nil {
+			return err
+		}
+		_, err = w.client.Runs.SubmitToolResults(ctx, runID, RunsToolResultsRequest{
+			NodeID:    node.NodeID,
+			Step:      node.Step,
+			RequestID: node.RequestID,
+			Results:   results,
+		})
+		var apiErr APIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusConflict {
+			w.client.telemetry.log(ctx, LogLevelInfo, "sdk_run_tool_worker_already_submitted", map[string]any{
+				"run_id":     runID.String(),
+				"node_id":    node.NodeID.String(),
+				"request_id": node.RequestID,
+			})
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, res := range results {
+			handled[res.ToolCall.ID] = struct{}{}
+		}
+		return nil
+	}
+	return nil
+}
+
+// execute runs pending calls in order, honoring the worker concurrency limit
+// and ToolRegistry.MarkSerial. user.ask calls go to the answerer.
+func (w *RunToolWorker) execute(ctx context.Context, runID RunID, node RunsPendingToolsNodeV0, calls []RunsPendingToolCall) ([]RunsToolResultItemV0, error) {
+	results := make([]RunsToolResultItemV0, len(calls))
+	errs := make([]error, len(calls))
+	var wg sync.WaitGroup
+	for i, pending := range calls {
+		call := llm.NewToolCall(pending.ToolCall.ID, pending.ToolCall.Name, pending.ToolCall.Arguments)
+		results[i].ToolCall = ToolCall{ID: call.ID, Name: pending.ToolCall.Name}
+		if IsUserAskToolCall(call) {
+			output, err := w.answer(ctx, runID, node, call)
+			if err != nil {
+				wg.Wait()
+				return nil, err
+			}
+			results[i].Output = output
+			continue
+		}
+		if w.registry.IsSerial(pending.ToolCall.Name) {
+			wg.Wait()
+		}
+		select {
+		case w.sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+		wg.Add(1)
+		go func(i int, call llm.ToolCall) {
+			defer wg.Done()
+			defer func() { <-w.sem }()
+			res := w.callTool(ctx, call)
+			results[i].Output = toolExecutionOutput(res)
+			errs[i] = res.Error
+		}(i, call)
+		if w.registry.IsSerial(pending.ToolCall.Name) {
+			wg.Wait()
+		}
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if calls[i].ToolCall.Name == ToolNameUserAsk {
+			continue
+		}
+		outcome := "ok"
+		if err != nil {
+			outcome = "error"
+		}
+		w.client.telemetry.metric(ctx, "sdk_run_tool_worker_calls", 1, map[string]string{
+			"tool":    string(calls[i].ToolCall.Name),
+			"outcome": outcome,
+		})
+	}
+	return results, nil
+}
+
+// callTool executes a call under the tool timeout. Handlers that ignore ctx
+// are abandoned when the timeout fires.
+func (w *RunToolWorker) callTool(ctx context.Context, call llm.ToolCall) ToolExecutionResult {
+	if w.opts.toolTimeout <= 0 {
+		return w.registry.ExecuteContext(ctx, call)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, w.opts.toolTimeout)
+	defer cancel()
+	done := make(chan ToolExecutionResult, 1)
+	go func() { done <- w.registry.ExecuteContext(callCtx, call) }()
+	select {
+	case res := <-done:
+		return res
+	case <-callCtx.Done():
+		return ToolExecutionResult{ToolCallID: call.ID, ToolName: GetToolName(call), Error: callCtx.Err()}
+	}
+}
+
+func (w *RunToolWorker) answer(ctx context.Context, runID RunID, node RunsPendingToolsNodeV0, call llm.ToolCall) (string, error) {
+	if w.opts.answerer == nil {
+		return "", errors.New("run tool worker: user.ask answerer required")
+	}
+	args, err := ParseUserAskArgs(call)
+	if err != nil {
+		return "", err
+	}
+	ask := NodeUserAsk{
+		Step:          node.Step,
+		RequestID:     node.RequestID,
+		ToolCall:      ToolCallWithArguments{ID: call.ID, Name: call.Function.Name, Arguments: call.Function.Arguments},
+		Question:      args.Question,
+		AllowFreeform: args.AllowFreeform == nil || *args.AllowFreeform,
+	}
+	for _, opt := range args.Options {
+		ask.Options = append(ask.Options, UserAskOption{Label: opt.Label, Description: opt.Description})
+	}
+	result, err := w.opts.answerer.AnswerUserAsk(ctx, runID, node.NodeID, ask)
+	if err != nil {
+		return "", err
+	}
+	return FormatUserAskResult(result)
+}
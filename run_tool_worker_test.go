@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/testutil"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+// toolWorkerModel asks the user a question and calls lookup, then answers
+// with the tool outputs.
+func toolWorkerModel(req testutil.FakeModelRequest) testutil.FakeModelResponse {
+	var outputs []string
+	for _, item := range req.Input {
+		if item.Role == llm.RoleTool && len(item.Content) > 0 {
+			outputs = append(outputs, item.Content[0].Text)
+		}
+	}
+	if len(outputs) > 0 {
+		return testutil.FakeModelResponse{Text: strings.Join(outputs, " | ")}
+	}
+	return testutil.FakeModelResponse{ToolCalls: []llm.ToolCall{
+		llm.NewToolCall("call_ask", ToolNameUserAsk, `{"question":"Which unit?","options":[{"label":"celsius"},{"label":"fahrenheit"}],"allow_freeform":false}`),
+		llm.NewToolCall("call_lookup", "lookup", `{"q":"`+req.LastUserText()+`"}`),
+	}}
+}
+
+func toolWorkerSpec() WorkflowSpec {
+	return WorkflowSpec{
+		Kind:  workflowintent.KindWorkflow,
+		Model: "demo",
+		Nodes: []workflowintent.Node{{
+			ID:   "ask",
+			Type: workflowintent.NodeTypeLLM,
+			User: "weather in {{city}}",
+			Tools: []workflowintent.ToolRef{
+				{Tool: UserAskTool()},
+				{Tool: llm.Tool{Type: llm.ToolTypeFunction, Function: &llm.FunctionTool{Name: "lookup"}}},
+			},
+			ToolExecution: &workflowintent.ToolExecution{Mode: workflowintent.ToolExecutionModeClient},
+		}},
+		Outputs: []workflowintent.OutputRef{{Name: "answer", From: "ask", Pointer: "/output/0/content/0/text"}},
+	}
+}
+
+func TestRunToolWorkerExecutesToolsAndAnswersUserAsk(t *testing.T) {
+	fake := testutil.NewFakeServer(testutil.WithFakeModel(toolWorkerModel))
+	t.Cleanup(fake.Close)
+	client := newTestClient(t, fake.Server, "mr_sk_test")
+	ctx := context.Background()
+
+	var lookups, asks atomic.Int32
+	registry := NewToolRegistry().Register("lookup", func(args map[string]any, _ llm.ToolCall) (any, error) {
+		lookups.Add(1)
+		return "sunny for " + args["q"].(string), nil
+	})
+	answerer := UserAskAnswererFunc(func(_ context.Context, _ RunID, nodeID NodeID, ask NodeUserAsk) (UserAskToolResult, error) {
+		asks.Add(1)
+		if nodeID != "ask" || ask.Question != "Which unit?" || len(ask.Options) != 2 || ask.AllowFreeform {
+			t.Errorf("unexpected user ask on %s: %+v", nodeID, ask)
+		}
+		return UserAskToolResult{Answer: ask.Options[0].Label}, nil
+	})
+	worker := NewRunToolWorker(client, registry,
+		WithRunToolWorkerAnswerer(answerer),
+		WithRunToolWorkerConcurrency(2),
+	)
+
+	var runIDs []RunID
+	for _, city := range []string{"Oslo", "Lima"} {
+		created, err := client.Runs.Create(ctx, toolWorkerSpec(), WithRunInputs(map[string]any{"city": city}))
+		if err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+		runIDs = append(runIDs, created.RunID)
+	}
+	if err := worker.WorkAll(ctx, runIDs...); err != nil {
+		t.Fatalf("work: %v", err)
+	}
+	for i, city := range []string{"Oslo", "Lima"} {
+		run, err := client.Runs.Get(ctx, runIDs[i])
+		if err != nil {
+			t.Fatalf("get run: %v", err)
+		}
+		var answer string
+		_ = json.Unmarshal(run.Outputs["answer"], &answer)
+		want := `{"answer":"celsius","is_freeform":false} | sunny for weather in ` + city
+		if run.Status != RunStatusSucceeded || answer != want {
+			t.Fatalf("run %d: status=%s answer=%q", i, run.Status, answer)
+		}
+	}
+
+	// Replaying a finished run must not execute anything again.
+	final, err := worker.Work(ctx, runIDs[0])
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if _, ok := final.(RunEventRunCompletedV0); !ok {
+		t.Fatalf("expected run_completed, got %T", final)
+	}
+	if lookups.Load() != 2 || asks.Load() != 2 {
+		t.Fatalf("expected one lookup and ask per run, got lookups=%d asks=%d", lookups.Load(), asks.Load())
+	}
+}
+
+func TestRunToolWorkerToolTimeout(t *testing.T) {
+	fake := testutil.NewFakeServer(testutil.WithFakeModel(testutil.ScriptedModel(
+		testutil.FakeModelResponse{ToolCalls: []llm.ToolCall{llm.NewToolCall("call_1", "slow", `{}`)}},
+		testutil.FakeModelResponse{Text: "gave up"},
+	)))
+	t.Cleanup(fake.Close)
+	client := newTestClient(t, fake.Server, "mr_sk_test")
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	registry := NewToolRegistry().Register("slow", func(map[string]any, llm.ToolCall) (any, error) {
+		<-release
+		return "too late", nil
+	})
+	spec := toolWorkerSpec()
+	spec.Nodes[0].Tools = []workflowintent.ToolRef{{Tool: llm.Tool{Type: llm.ToolTypeFunction, Function: &llm.FunctionTool{Name: "slow"}}}}
+	created, err := client.Runs.Create(context.Background(), spec, WithRunInputs(map[string]any{"city": "Oslo"}))
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+
+	worker := NewRunToolWorker(client, registry, WithRunToolWorkerToolTimeout(10*time.Millisecond))
+	if _, err := worker.Work(context.Background(), created.RunID); err != nil {
+		t.Fatalf("work: %v", err)
+	}
+	events, err := client.Runs.ListEvents(context.Background(), created.RunID)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	var output string
+	for _, ev := range events {
+		if res, ok := ev.(RunEventNodeToolResultV0); ok {
+			output = res.ToolResult.Output
+		}
+	}
+	if !strings.Contains(output, "deadline exceeded") {
+		t.Fatalf("expected timeout result, got %q", output)
+	}
+}
@@ -0,0 +1,237 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RunCheckpointStore persists the sequence number of the last run event a
+// watcher has processed, so a restarted process resumes where it left off.
+type RunCheckpointStore interface {
+	// LoadCheckpoint returns the last processed seq for a run, or 0 if none.
+	LoadCheckpoint(ctx context.Context, runID RunID) (int64, error)
+	// SaveCheckpoint records seq as the last processed event of a run.
+	SaveCheckpoint(ctx context.Context, runID RunID, seq int64) error
+}
+
+// MemoryRunCheckpointStore keeps run checkpoints in memory. It is safe for
+// concurrent use.
+type MemoryRunCheckpointStore struct {
+	mu   sync.Mutex
+	seqs map[RunID]int64
+}
+
+// NewMemoryRunCheckpointStore returns an empty in-memory checkpoint store.
+func NewMemoryRunCheckpointStore() *MemoryRunCheckpointStore {
+	return &MemoryRunCheckpointStore{seqs: make(map[RunID]int64)}
+}
+
+// LoadCheckpoint implements RunCheckpointStore.
+func (s *MemoryRunCheckpointStore) LoadCheckpoint(_ context.Context, runID RunID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seqs[runID], nil
+}
+
+// SaveCheckpoint implements RunCheckpointStore.
+func (s *MemoryRunCheckpointStore) SaveCheckpoint(_ context.Context, runID RunID, seq int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seqs[runID] = seq
+	return nil
+}
+
+// RunEventHandler processes a run event delivered by RunsClient.Watch.
+type RunEventHandler func(ctx context.Context, ev RunEvent) error
+
+type runWatchOptions struct {
+	checkpoint RunCheckpointStore
+	retry      *RetryConfig
+	afterSeq   int64
+}
+
+// RunWatchOption configures RunsClient.Watch.
+type RunWatchOption func(*runWatchOptions)
+
+// WithRunWatchCheckpoint loads the starting seq from store and saves each
+// processed seq back to it.
+func WithRunWatchCheckpoint(store RunCheckpointStore) RunWatchOption {
+	return func(o *runWatchOptions) { o.checkpoint = store }
+}
+
+// WithRunWatchRetry overrides the client RetryConfig for reconnects.
+// MaxAttempts bounds consecutive failed connections; the count resets
+// whenever an event is processed.
+func WithRunWatchRetry(cfg RetryConfig) RunWatchOption {
+	return func(o *runWatchOptions) { o.retry = &cfg }
+}
+
+// WithRunWatchAfterSeq skips events up to and including seq. A higher
+// checkpoint takes precedence.
+func WithRunWatchAfterSeq(seq int64) RunWatchOption {
+	return func(o *runWatchOptions) { o.afterSeq = seq }
+}
+
+// Watch follows a run's event stream until a terminal run_completed,
+// run_failed or run_canceled event, calling handler for each event in seq
+// order and returning the terminal event.
+//
+// Dropped connections are re-opened after the last processed seq with
+// backoff per RetryConfig, and replayed events are skipped; a replayed
+// terminal event, as seen when resuming a finished run from a checkpoint, is
+// returned without calling handler again. A connection that ends without
+// delivering a new event counts as a failed attempt. An event is only
+// checkpointed after handler returns nil, so delivery is at-least-once across
+// process restarts. A handler or checkpoint error stops the watch and is
+// returned as-is.
+//
+// Example:
+//
+//	store := sdk.NewMemoryRunCheckpointStore()
+//	final, err := client.Runs.Watch(ctx, runID, func(ctx context.Context, ev sdk.RunEvent) error {
+//		log.Printf("%T", ev)
+//		return nil
+//	}, sdk.WithRunWatchCheckpoint(store))
+func (c *RunsClient) Watch(ctx context.Context, runID RunID, handler RunEventHandler, opts ...RunWatchOption) (RunEvent, error) {
+	if !runID.Valid() {
+		return nil, ConfigError{Reason: "run id is required"}
+	}
+	if handler == nil {
+		return nil, ConfigError{Reason: "run event handler is required"}
+	}
+	var options runWatchOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	cfg := c.client.retryCfg
+	if options.retry != nil {
+		cfg = options.retry.normalized()
+	}
+
+	lastSeq := options.afterSeq
+	if options.checkpoint != nil {
+		seq, err := options.checkpoint.LoadCheckpoint(ctx, runID)
+		if err != nil {
+			return nil, err
+		}
+		lastSeq = maxInt64(lastSeq, seq)
+	}
+
+	failures, connects := 0, 0
+	for {
+		if connects > 0 || failures > 0 {
+			timer := time.NewTimer(cfg.backoffDelay(failures + 2))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		stream, err := c.StreamEvents(ctx, runID, WithRunEventsAfterSeq(lastSeq), WithRunEventsRetry(RetryConfig{MaxAttempts: 1}))
+		if err != nil {
+			failures++
+			if !retryableStreamError(err) || ctx.Err() != nil || failures >= cfg.MaxAttempts {
+				return nil, err
+			}
+			c.client.telemetry.log(ctx, LogLevelError, "sdk_run_watch_connect_failed", map[string]any{
+				"run_id":       runID.String(),
+				"attempt":      failures,
+				"max_attempts": cfg.MaxAttempts,
+				"error":        err.Error(),
+			})
+			continue
+		}
+		if connects > 0 {
+			c.client.telemetry.metric(ctx, "sdk_run_watch_reconnects", 1, nil)
+		}
+		connects++
+
+		var streamErr error
+		progressed := false
+		for {
+			ev, ok, err := stream.Next()
+			if err != nil {
+				streamErr = err
+				break
+			}
+			if !ok {
+				break
+			}
+			seq := runEventSeq(ev)
+			if seq <= lastSeq {
+				if isTerminalRunEvent(ev) {
+					//nolint:errcheck // best-effort cleanup on return
+					_ = stream.Close()
+					return ev, nil
+				}
+				continue
+			}
+			if err := handler(ctx, ev); err != nil {
+				//nolint:errcheck // best-effort cleanup on return
+				_ = stream.Close()
+				return nil, err
+			}
+			lastSeq = seq
+			failures = 0
+			progressed = true
+			if options.checkpoint != nil {
+				if err := options.checkpoint.SaveCheckpoint(ctx, runID, seq); err != nil {
+					//nolint:errcheck // best-effort cleanup on return
+					_ = stream.Close()
+					return nil, err
+				}
+			}
+			if isTerminalRunEvent(ev) {
+				//nolint:errcheck // best-effort cleanup on return
+				_ = stream.Close()
+				return ev, nil
+			}
+		}
+		//nolint:errcheck // best-effort cleanup before reconnect
+		_ = stream.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		fields := map[string]any{"run_id": runID.String(), "last_seq": lastSeq}
+		if streamErr != nil {
+			var protoErr ProtocolError
+			if errors.As(streamErr, &protoErr) {
+				return nil, streamErr
+			}
+			failures++
+			if failures >= cfg.MaxAttempts {
+				return nil, streamErr
+			}
+			fields["error"] = streamErr.Error()
+		} else if !progressed {
+			failures++
+			if failures >= cfg.MaxAttempts {
+				return nil, TransportError{Kind: TransportErrorEmptyResponse, Message: "run event stream closed without new events"}
+			}
+		}
+		c.client.telemetry.log(ctx, LogLevelInfo, "sdk_run_watch_disconnected", fields)
+	}
+}
+
+func runEventSeq(ev RunEvent) int64 {
+	if s, ok := ev.(interface{ seqNum() int64 }); ok {
+		return s.seqNum()
+	}
+	return 0
+}
+
+func isTerminalRunEvent(ev RunEvent) bool {
+	switch ev.(type) {
+	case RunEventRunCompletedV0, RunEventRunFailedV0, RunEventRunCanceledV0:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,187 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+)
+
+func runWatchEventLine(t *testing.T, runID RunID, seq int64, typ workflow.EventTypeV0) string {
+	t.Helper()
+	ev := workflow.EventV0Envelope{
+		EnvelopeVersion: workflow.EventEnvelopeVersionV0,
+		RunID:           runID,
+		Seq:             seq,
+		TS:              time.Now().UTC(),
+		Type:            typ,
+	}
+	hash := workflow.PlanHash(strings.Repeat("a", 64))
+	switch typ {
+	case workflow.EventRunStarted:
+		ev.PlanHash = &hash
+	case workflow.EventRunCompleted:
+		ev.PlanHash = &hash
+		ev.Outputs = &workflow.PayloadArtifact{ArtifactKey: workflow.ArtifactKeyRunOutputsV0, Info: workflow.PayloadInfo{Bytes: 2}}
+	default:
+		ev.NodeID = "a"
+	}
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatalf("marshal event: %v", err)
+	}
+	return string(raw) + "\n"
+}
+
+type runWatchServer struct {
+	mu        sync.Mutex
+	afterSeqs []string
+}
+
+func (s *runWatchServer) serve(t *testing.T, conns ...func(w http.ResponseWriter)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		n := len(s.afterSeqs)
+		s.afterSeqs = append(s.afterSeqs, r.URL.Query().Get("after_seq"))
+		s.mu.Unlock()
+		if n >= len(conns) {
+			t.Errorf("unexpected connection %d", n+1)
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		conns[n](w)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunsWatchReconnectsAndDeduplicates(t *testing.T) {
+	runID := NewRunID()
+	ndjson := func(lines ...string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = fmt.Fprint(w, strings.Join(lines, ""))
+		}
+	}
+	s := &runWatchServer{}
+	srv := s.serve(t,
+		ndjson(runWatchEventLine(t, runID, 1, workflow.EventRunStarted), runWatchEventLine(t, runID, 2, workflow.EventNodeStarted)),
+		// Replays seq 2 and drops mid-line.
+		ndjson(runWatchEventLine(t, runID, 2, workflow.EventNodeStarted), runWatchEventLine(t, runID, 3, workflow.EventNodeSucceeded), `{"envelope_version":`),
+		func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+		ndjson(runWatchEventLine(t, runID, 4, workflow.EventRunCompleted)),
+	)
+	client := newTestClient(t, srv, "mr_sk_test")
+	store := NewMemoryRunCheckpointStore()
+
+	var seqs []int64
+	final, err := client.Runs.Watch(context.Background(), runID, func(_ context.Context, ev RunEvent) error {
+		seqs = append(seqs, runEventSeq(ev))
+		return nil
+	}, WithRunWatchCheckpoint(store), WithRunWatchRetry(RetryConfig{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}))
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if _, ok := final.(RunEventRunCompletedV0); !ok {
+		t.Fatalf("expected run_completed, got %T", final)
+	}
+	if fmt.Sprint(seqs) != "[1 2 3 4]" {
+		t.Fatalf("unexpected delivered seqs %v", seqs)
+	}
+	if fmt.Sprint(s.afterSeqs) != "[ 2 3 3]" {
+		t.Fatalf("unexpected after_seq values %q", s.afterSeqs)
+	}
+	if seq, _ := store.LoadCheckpoint(context.Background(), runID); seq != 4 {
+		t.Fatalf("expected checkpoint 4, got %d", seq)
+	}
+}
+
+func TestRunsWatchResumesFromCheckpointAfterHandlerError(t *testing.T) {
+	runID := NewRunID()
+	all := []string{
+		runWatchEventLine(t, runID, 1, workflow.EventRunStarted),
+		runWatchEventLine(t, runID, 2, workflow.EventNodeStarted),
+		runWatchEventLine(t, runID, 3, workflow.EventRunCompleted),
+	}
+	replay := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = fmt.Fprint(w, strings.Join(all, ""))
+	}
+	s := &runWatchServer{}
+	srv := s.serve(t, replay, replay)
+	client := newTestClient(t, srv, "mr_sk_test")
+	store := NewMemoryRunCheckpointStore()
+
+	errBoom := errors.New("boom")
+	_, err := client.Runs.Watch(context.Background(), runID, func(_ context.Context, ev RunEvent) error {
+		if runEventSeq(ev) == 2 {
+			return errBoom
+		}
+		return nil
+	}, WithRunWatchCheckpoint(store))
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+
+	var seqs []int64
+	if _, err := client.Runs.Watch(context.Background(), runID, func(_ context.Context, ev RunEvent) error {
+		seqs = append(seqs, runEventSeq(ev))
+		return nil
+	}, WithRunWatchCheckpoint(store)); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if fmt.Sprint(seqs) != "[2 3]" {
+		t.Fatalf("expected redelivery from seq 2, got %v", seqs)
+	}
+	if fmt.Sprint(s.afterSeqs) != "[ 1]" {
+		t.Fatalf("unexpected after_seq values %q", s.afterSeqs)
+	}
+}
+
+func TestRunsWatchFinishedRunFromCheckpoint(t *testing.T) {
+	runID := NewRunID()
+	ndjson := func(lines ...string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = fmt.Fprint(w, strings.Join(lines, ""))
+		}
+	}
+	store := NewMemoryRunCheckpointStore()
+	_ = store.SaveCheckpoint(context.Background(), runID, 2)
+	retry := WithRunWatchRetry(RetryConfig{MaxAttempts: 2, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	noop := func(context.Context, RunEvent) error {
+		t.Error("handler called for an already processed event")
+		return nil
+	}
+
+	// The server replays the terminal event despite after_seq.
+	s := &runWatchServer{}
+	srv := s.serve(t, ndjson(runWatchEventLine(t, runID, 1, workflow.EventRunStarted), runWatchEventLine(t, runID, 2, workflow.EventRunCompleted)))
+	final, err := newTestClient(t, srv, "mr_sk_test").Runs.Watch(context.Background(), runID, noop, WithRunWatchCheckpoint(store), retry)
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if _, ok := final.(RunEventRunCompletedV0); !ok || runEventSeq(final) != 2 {
+		t.Fatalf("expected replayed run_completed, got %T", final)
+	}
+
+	// The server sends nothing after the checkpoint.
+	s = &runWatchServer{}
+	srv = s.serve(t, ndjson(), ndjson())
+	_, err = newTestClient(t, srv, "mr_sk_test").Runs.Watch(context.Background(), runID, noop, WithRunWatchCheckpoint(store), retry)
+	var transportErr TransportError
+	if !errors.As(err, &transportErr) || transportErr.Kind != TransportErrorEmptyResponse {
+		t.Fatalf("expected empty response error, got %v", err)
+	}
+	if fmt.Sprint(s.afterSeqs) != "[2 2]" {
+		t.Fatalf("unexpected after_seq values %q", s.afterSeqs)
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/modelrelay/modelrelay/sdk/go/generated"
 	"github.com/modelrelay/modelrelay/sdk/go/routes"
 	"github.com/modelrelay/modelrelay/sdk/go/workflow"
 )
@@ -431,6 +433,30 @@ type RunsPendingToolCall struct {
 	ToolCall ToolCallWithArguments `json:"tool_call"`
 }
 
+// RunStepDetail is the LLM request/response and tool activity of a single node step.
+type RunStepDetail = generated.RunStepDetail
+
+// RunStepsResponse lists the step detail of a run.
+type RunStepsResponse = generated.RunStepsResponse
+
+// RunToolCallDetail pairs a tool call made during a run with its result.
+type RunToolCallDetail = generated.RunToolCallDetail
+
+// RunTask is an entry in a run's task list.
+type RunTask = generated.RunTask
+
+// RunTaskStatus is the progress state of a RunTask.
+type RunTaskStatus = generated.RunTaskStatus
+
+const (
+	RunTaskStatusPending    = generated.RunTaskStatusPending
+	RunTaskStatusInProgress = generated.RunTaskStatusInProgress
+	RunTaskStatusCompleted  = generated.RunTaskStatusCompleted
+)
+
+// RunTasksResponse lists the tasks of a run.
+type RunTasksResponse = generated.RunTasksResponse
+
 type RunsEventStream struct {
 	body io.ReadCloser
 	dec  *json.Decoder
@@ -651,6 +677,32 @@ func (c *RunsClient) CreateV1(ctx context.Context, spec workflow.SpecV1, opts ..
 	return &out, nil
 }
 
+// CreateFromYAMLFile loads a YAML workflow spec (see ParseWorkflowYAML) and
+// starts a run from it. $ref includes resolve relative to the file; workflow.v1
+// documents are sent through CreateV1.
+func (c *RunsClient) CreateFromYAMLFile(ctx context.Context, file string, opts ...RunCreateOption) (*RunsCreateResponse, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := workflowYAMLKind(data)
+	if err != nil {
+		return nil, err
+	}
+	if kind == string(workflow.KindV1) {
+		spec, err := LoadWorkflowV1YAML(file)
+		if err != nil {
+			return nil, err
+		}
+		return c.CreateV1(ctx, spec, opts...)
+	}
+	spec, err := LoadWorkflowYAML(file)
+	if err != nil {
+		return nil, err
+	}
+	return c.Create(ctx, spec, opts...)
+}
+
 // CreateFromPlan starts a workflow run using a precompiled plan hash.
 //
 // Use Workflows().Compile() to compile a workflow spec and obtain a plan_hash,
@@ -50,6 +50,7 @@ type SQLToolLoopOptions struct {
 	SampleRows               *bool
 	SampleRowsLimit           int
 	ResultLimit              int
+	History                  AgentHistoryStrategy
 }
 
 // SQLDescribeTableArgs identifies a table to describe.
@@ -325,8 +326,17 @@ func (c *Client) SQLToolLoop(ctx context.Context, opts SQLToolLoopOptions, handl
 	usage := AgentUsage{}
 	maxTurns := DefaultMaxTurns
 	var lastResp *Response
+	var history AgentHistoryState
 
 	for turn := 0; turn < maxTurns; turn++ {
+		history.Turn = turn
+		history.Usage = usage
+		var err error
+		input, err = applyAgentHistory(ctx, opts.History, input, history)
+		if err != nil {
+			return nil, err
+		}
+
 		builder := c.Responses.New().Model(NewModelID(opts.Model)).Input(input)
 		if len(definitions) > 0 {
 			builder = builder.Tools(definitions)
@@ -345,6 +355,8 @@ func (c *Client) SQLToolLoop(ctx context.Context, opts SQLToolLoopOptions, handl
 		usage.InputTokens += resp.Usage.InputTokens
 		usage.OutputTokens += resp.Usage.OutputTokens
 		usage.TotalTokens += resp.Usage.TotalTokens
+		history.LastUsage = resp.Usage
+		history.LastInputChars = historyChars(input)
 
 		toolCalls := resp.ToolCalls()
 		if len(toolCalls) == 0 {
@@ -353,7 +365,7 @@ func (c *Client) SQLToolLoop(ctx context.Context, opts SQLToolLoopOptions, handl
 		usage.ToolCalls += len(toolCalls)
 		input = append(input, AssistantMessageWithToolCalls(resp.AssistantText(), toolCalls))
 
-		results := registry.ExecuteAll(toolCalls)
+		results := registry.ExecuteAllContext(ctx, toolCalls)
 		input = append(input, registry.ResultsToMessages(results)...)
 	}
 
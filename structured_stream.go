@@ -57,6 +57,11 @@ type StructuredJSONStream[T any] struct {
 	done      chan struct{}
 	terminal  bool // completion or error observed
 
+	// ticket is reconciled against usage, the last usage reported by the
+	// stream, when the stream closes.
+	ticket *rateLimitTicket
+	usage  int64
+
 	currentPayload json.RawMessage
 }
 
@@ -247,6 +252,11 @@ func (s *StructuredJSONStream[T]) Next() (StructuredJSONEvent[T], bool, error) {
 				return StructuredJSONEvent[T]{}, false, s.transportError("failed to decode structured payload", err)
 			}
 			s.monitor.SignalFirstContent()
+			if record.usage != nil {
+				s.mu.Lock()
+				s.usage = record.usage.Total()
+				s.mu.Unlock()
+			}
 			if record.recordType == StructuredRecordTypeCompletion {
 				s.markTerminal()
 				//nolint:errcheck // best-effort cleanup after completion
@@ -324,6 +334,7 @@ func (s *StructuredJSONStream[T]) Close() error {
 	s.closeOnce.Do(func() {
 		s.mu.Lock()
 		s.closed = true
+		s.ticket.reconcile(s.usage)
 		s.mu.Unlock()
 		close(s.done)
 		if s.cancel != nil {
@@ -338,6 +349,17 @@ func (s *StructuredJSONStream[T]) Close() error {
 	return err
 }
 
+// trackRateLimit reconciles ticket once the stream closes.
+func (s *StructuredJSONStream[T]) trackRateLimit(ticket *rateLimitTicket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		ticket.reconcile(s.usage)
+		return
+	}
+	s.ticket = ticket
+}
+
 func (s *StructuredJSONStream[T]) isClosed() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,586 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// CassetteMode selects whether a Recorder talks to the network or a cassette file.
+type CassetteMode string
+
+const (
+	// CassetteRecord forwards requests to the real transport and writes every
+	// interaction to the cassette when the Recorder is stopped.
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay serves interactions from the cassette and never touches the network.
+	CassetteReplay CassetteMode = "replay"
+)
+
+// Redacted replaces scrubbed header, query and body values in cassettes.
+const Redacted = "[REDACTED]"
+
+// DefaultScrubHeaders are always redacted from recorded requests and responses.
+var DefaultScrubHeaders = []string{
+	"X-ModelRelay-Api-Key",
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultScrubQueryParams are always redacted from recorded request URLs.
+var DefaultScrubQueryParams = []string{
+	"api_key",
+	"token",
+	"access_token",
+}
+
+// DefaultScrubFields are JSON object fields whose string values are always
+// redacted from recorded request and response bodies, at any depth. They
+// cover customer tokens minted via /auth/customer-token.
+var DefaultScrubFields = []string{
+	"token",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"api_key",
+	"client_secret",
+}
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the recorded form of an outgoing request.
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// CassetteResponse is the recorded form of a response. Streaming responses
+// (NDJSON and SSE) are stored as Chunks with the delay before each one;
+// other responses use Body.
+type CassetteResponse struct {
+	Status  int             `json:"status"`
+	Headers http.Header     `json:"headers,omitempty"`
+	Body    string          `json:"body,omitempty"`
+	Chunks  []CassetteChunk `json:"chunks,omitempty"`
+}
+
+// CassetteChunk is one read of a streamed response body.
+type CassetteChunk struct {
+	DelayMS int64  `json:"delay_ms,omitempty"`
+	Data    string `json:"data"`
+}
+
+// Matcher reports whether a live request matches a recorded one. req and body
+// are the live request and its body, scrubbed the same way as recorded ones.
+type Matcher func(req *http.Request, body []byte, recorded CassetteRequest) bool
+
+// MatchMethod matches on the HTTP method.
+func MatchMethod(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	return req.Method == recorded.Method
+}
+
+// MatchPath matches on the URL path and query, ignoring scheme and host so
+// cassettes recorded against one server replay against another.
+func MatchPath(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	return req.URL.RequestURI() == recordedRequestURI(recorded.URL)
+}
+
+// MatchBody matches on the request body, comparing JSON bodies semantically.
+func MatchBody(_ *http.Request, body []byte, recorded CassetteRequest) bool {
+	var live, rec any
+	if json.Unmarshal(body, &live) == nil && json.Unmarshal([]byte(recorded.Body), &rec) == nil {
+		a, _ := json.Marshal(live)
+		b, _ := json.Marshal(rec)
+		return bytes.Equal(a, b)
+	}
+	return string(body) == recorded.Body
+}
+
+// MatchHeader returns a Matcher comparing the named header.
+func MatchHeader(name string) Matcher {
+	return func(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+		return req.Header.Get(name) == recorded.Headers.Get(name)
+	}
+}
+
+// DefaultMatchers match on method, path and body.
+var DefaultMatchers = []Matcher{MatchMethod, MatchPath, MatchBody}
+
+// RecorderConfig configures NewRecorder.
+type RecorderConfig struct {
+	// Path is the cassette file. Required.
+	Path string
+	// Mode selects record or replay. Required.
+	Mode CassetteMode
+	// Transport performs real requests in record mode. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Matchers select the recorded interaction for a request in replay mode.
+	// All must match. Defaults to DefaultMatchers.
+	Matchers []Matcher
+	// ScrubHeaders are redacted in addition to DefaultScrubHeaders.
+	ScrubHeaders []string
+	// ScrubQueryParams are redacted in addition to DefaultScrubQueryParams.
+	ScrubQueryParams []string
+	// ScrubFields are redacted from JSON bodies in addition to DefaultScrubFields.
+	ScrubFields []string
+	// ScrubBody, when set, rewrites request bodies, response bodies and
+	// streamed chunks after the default scrubbing.
+	ScrubBody func(body string) string
+	// RealTiming replays streamed chunks with their recorded delays.
+	RealTiming bool
+	// T, when set, fails the test on unmatched requests in replay mode.
+	T testing.TB
+}
+
+// Recorder is an http.RoundTripper that records or replays a cassette.
+// Use it with the SDK via sdk.WithHTTPClient(rec.Client()).
+type Recorder struct {
+	cfg         RecorderConfig
+	scrub       map[string]bool
+	scrubQuery  map[string]bool
+	scrubFields map[string]bool
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	unmatched    []string
+}
+
+// NewRecorder returns a Recorder for cfg. In replay mode the cassette must exist.
+func NewRecorder(cfg RecorderConfig) (*Recorder, error) {
+	if strings.TrimSpace(cfg.Path) == "" {
+		return nil, errors.New("testutil: cassette path is required")
+	}
+	if cfg.Transport == nil {
+		cfg.Transport = http.DefaultTransport
+	}
+	if len(cfg.Matchers) == 0 {
+		cfg.Matchers = DefaultMatchers
+	}
+	r := &Recorder{cfg: cfg, scrub: make(map[string]bool), scrubQuery: make(map[string]bool), scrubFields: make(map[string]bool)}
+	for _, h := range append(append([]string(nil), DefaultScrubHeaders...), cfg.ScrubHeaders...) {
+		r.scrub[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, q := range append(append([]string(nil), DefaultScrubQueryParams...), cfg.ScrubQueryParams...) {
+		r.scrubQuery[q] = true
+	}
+	for _, f := range append(append([]string(nil), DefaultScrubFields...), cfg.ScrubFields...) {
+		r.scrubFields[f] = true
+	}
+	switch cfg.Mode {
+	case CassetteRecord:
+	case CassetteReplay:
+		data, err := os.ReadFile(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("testutil: read cassette: %w", err)
+		}
+		var cassette Cassette
+		if err := json.Unmarshal(data, &cassette); err != nil {
+			return nil, fmt.Errorf("testutil: decode cassette %s: %w", cfg.Path, err)
+		}
+		for i := range cassette.Interactions {
+			r.interactions = append(r.interactions, &cassette.Interactions[i])
+		}
+		r.used = make([]bool, len(r.interactions))
+	default:
+		return nil, fmt.Errorf("testutil: unknown cassette mode %q", cfg.Mode)
+	}
+	return r, nil
+}
+
+// Client returns an *http.Client that sends requests through the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.cfg.Mode == CassetteReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+	}
+	resp, err := r.cfg.Transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	in := &Interaction{
+		Request:  r.scrubbedRequest(req, body),
+		Response: CassetteResponse{Status: resp.StatusCode, Headers: r.scrubbed(resp.Header)},
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.mu.Unlock()
+	resp.Body = &recordingBody{
+		rc:        resp.Body,
+		mu:        &r.mu,
+		resp:      &in.Response,
+		streaming: isStreaming(resp.Header),
+		last:      time.Now(),
+	}
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	// Match against the live request as it would have been recorded, so
+	// scrubbed headers, query parameters and body fields compare equal.
+	live := req.Clone(req.Context())
+	live.URL = r.scrubbedURL(req.URL)
+	live.Header = r.scrubbed(req.Header)
+	liveBody := []byte(r.scrubbedBody(string(body)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || !r.matches(live, liveBody, in.Request) {
+			continue
+		}
+		r.used[i] = true
+		return r.response(req, in.Response), nil
+	}
+	desc := fmt.Sprintf("%s %s", req.Method, live.URL.RequestURI())
+	r.unmatched = append(r.unmatched, desc)
+	err := fmt.Errorf("testutil: no cassette interaction in %s matches %s (body %q)", r.cfg.Path, desc, truncate(string(liveBody), 200))
+	if r.cfg.T != nil {
+		r.cfg.T.Helper()
+		r.cfg.T.Errorf("%v", err)
+	}
+	return nil, err
+}
+
+func (r *Recorder) matches(req *http.Request, body []byte, recorded CassetteRequest) bool {
+	for _, m := range r.cfg.Matchers {
+		if !m(req, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) response(req *http.Request, rec CassetteResponse) *http.Response {
+	resp := &http.Response{
+		StatusCode: rec.Status,
+		Status:     fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     rec.Headers.Clone(),
+		Request:    req,
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	if len(rec.Chunks) > 0 {
+		resp.ContentLength = -1
+		resp.Body = &replayBody{req: req, chunks: rec.Chunks, realTiming: r.cfg.RealTiming}
+		return resp
+	}
+	resp.ContentLength = int64(len(rec.Body))
+	resp.Body = io.NopCloser(strings.NewReader(rec.Body))
+	return resp
+}
+
+// scrubbedRequest returns the recorded form of req with secrets redacted.
+func (r *Recorder) scrubbedRequest(req *http.Request, body []byte) CassetteRequest {
+	return CassetteRequest{
+		Method:  req.Method,
+		URL:     r.scrubbedURL(req.URL).String(),
+		Headers: r.scrubbed(req.Header),
+		Body:    r.scrubbedBody(string(body)),
+	}
+}
+
+func (r *Recorder) scrubbedURL(u *url.URL) *url.URL {
+	out := *u
+	q := out.Query()
+	redacted := false
+	for key, values := range q {
+		if r.scrubQuery[key] {
+			for i := range values {
+				values[i] = Redacted
+			}
+			redacted = true
+		}
+	}
+	if redacted {
+		out.RawQuery = q.Encode()
+	}
+	return &out
+}
+
+// scrubbedResponse returns resp with secrets redacted from the body and chunks.
+// Chunks that end mid-line are merged with the following ones so an event
+// split across reads is still scrubbed as a whole.
+func (r *Recorder) scrubbedResponse(resp CassetteResponse) CassetteResponse {
+	resp.Body = r.scrubbedBody(resp.Body)
+	if len(resp.Chunks) == 0 {
+		return resp
+	}
+	chunks := make([]CassetteChunk, 0, len(resp.Chunks))
+	var pending CassetteChunk
+	for i, chunk := range resp.Chunks {
+		pending.DelayMS += chunk.DelayMS
+		pending.Data += chunk.Data
+		if !strings.HasSuffix(pending.Data, "\n") && i < len(resp.Chunks)-1 {
+			continue
+		}
+		pending.Data = r.scrubbedBody(pending.Data)
+		chunks = append(chunks, pending)
+		pending = CassetteChunk{}
+	}
+	resp.Chunks = chunks
+	return resp
+}
+
+// scrubbedBody redacts DefaultScrubFields and ScrubFields from a JSON body or
+// from each JSON line of an NDJSON or SSE body, then applies ScrubBody.
+func (r *Recorder) scrubbedBody(body string) string {
+	if out, ok := r.scrubbedJSON(body); ok {
+		body = out
+	} else if strings.Contains(body, "\n") {
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			payload := strings.TrimLeft(strings.TrimPrefix(line, "data:"), " ")
+			if out, ok := r.scrubbedJSON(payload); ok {
+				lines[i] = line[:len(line)-len(payload)] + out
+			}
+		}
+		body = strings.Join(lines, "\n")
+	}
+	if r.cfg.ScrubBody != nil {
+		body = r.cfg.ScrubBody(body)
+	}
+	return body
+}
+
+// scrubbedJSON reports whether data is a single JSON value containing a
+// scrubbed field and, if so, returns it re-encoded with those fields redacted.
+// Trailing whitespace is preserved.
+func (r *Recorder) scrubbedJSON(data string) (string, bool) {
+	trimmed := strings.TrimRight(data, " \t\r\n")
+	if trimmed == "" {
+		return "", false
+	}
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return "", false
+	}
+	if !r.redactFields(v) {
+		return "", false
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n") + data[len(trimmed):], true
+}
+
+func (r *Recorder) redactFields(v any) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, ok := field.(string); ok && r.scrubFields[key] {
+				v[key] = Redacted
+				redacted = true
+				continue
+			}
+			redacted = r.redactFields(field) || redacted
+		}
+	case []any:
+		for _, item := range v {
+			redacted = r.redactFields(item) || redacted
+		}
+	}
+	return redacted
+}
+
+func (r *Recorder) scrubbed(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for key := range out {
+		if r.scrub[http.CanonicalHeaderKey(key)] {
+			out[key] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+// Unmatched returns the requests that found no interaction in replay mode.
+func (r *Recorder) Unmatched() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.unmatched...)
+}
+
+// Unused returns recorded interactions that were never replayed.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Interaction
+	for i, in := range r.interactions {
+		if !r.used[i] {
+			out = append(out, *in)
+		}
+	}
+	return out
+}
+
+// Stop writes the cassette in record mode. Response bodies that were not
+// fully read are recorded as far as they were consumed. In replay mode Stop
+// reports requests that did not match any interaction.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cfg.Mode == CassetteReplay {
+		if len(r.unmatched) > 0 {
+			return fmt.Errorf("testutil: %d unmatched request(s): %s", len(r.unmatched), strings.Join(r.unmatched, ", "))
+		}
+		return nil
+	}
+	cassette := Cassette{Interactions: make([]Interaction, 0, len(r.interactions))}
+	for _, in := range r.interactions {
+		cassette.Interactions = append(cassette.Interactions, Interaction{Request: in.Request, Response: r.scrubbedResponse(in.Response)})
+	}
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("testutil: create cassette directory: %w", err)
+	}
+	return os.WriteFile(r.cfg.Path, append(data, '\n'), 0o644)
+}
+
+// recordingBody copies a live response body into the cassette as it is read.
+type recordingBody struct {
+	rc        io.ReadCloser
+	mu        *sync.Mutex
+	resp      *CassetteResponse
+	streaming bool
+	last      time.Time
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		now := time.Now()
+		b.mu.Lock()
+		if b.streaming {
+			b.resp.Chunks = append(b.resp.Chunks, CassetteChunk{DelayMS: now.Sub(b.last).Milliseconds(), Data: string(p[:n])})
+		} else {
+			b.resp.Body += string(p[:n])
+		}
+		b.mu.Unlock()
+		b.last = now
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	return b.rc.Close()
+}
+
+// replayBody serves recorded chunks, optionally with their recorded delays.
+type replayBody struct {
+	req        *http.Request
+	chunks     []CassetteChunk
+	realTiming bool
+	pending    []byte
+	closed     bool
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, errors.New("testutil: read on closed body")
+	}
+	if len(b.pending) == 0 {
+		if len(b.chunks) == 0 {
+			return 0, io.EOF
+		}
+		chunk := b.chunks[0]
+		b.chunks = b.chunks[1:]
+		if b.realTiming && chunk.DelayMS > 0 {
+			timer := time.NewTimer(time.Duration(chunk.DelayMS) * time.Millisecond)
+			select {
+			case <-b.req.Context().Done():
+				timer.Stop()
+				return 0, b.req.Context().Err()
+			case <-timer.C:
+			}
+		}
+		b.pending = []byte(chunk.Data)
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *replayBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func isStreaming(h http.Header) bool {
+	ct := strings.ToLower(h.Get("Content-Type"))
+	return strings.Contains(ct, "ndjson") || strings.Contains(ct, "text/event-stream")
+}
+
+func recordedRequestURI(raw string) string {
+	req, err := http.NewRequest(http.MethodGet, raw, nil)
+	if err != nil {
+		return raw
+	}
+	return req.URL.RequestURI()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
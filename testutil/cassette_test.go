@@ -0,0 +1,203 @@
+package testutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelrelay/modelrelay/sdk/go"
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+	"github.com/modelrelay/modelrelay/sdk/go/testutil"
+)
+
+func mustSecretKey(t *testing.T, raw string) sdk.SecretKey {
+	t.Helper()
+	key, err := sdk.ParseSecretKey(raw)
+	if err != nil {
+		t.Fatalf("parse secret key: %v", err)
+	}
+	return key
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != routes.Responses {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if strings.Contains(r.Header.Get("Accept"), "ndjson") {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			flusher, _ := w.(http.Flusher)
+			_, _ = w.Write([]byte(`{"type":"start","request_id":"resp_s","model":"demo"}` + "\n"))
+			flusher.Flush()
+			_, _ = w.Write([]byte(`{"type":"update","delta":"streamed"}` + "\n"))
+			flusher.Flush()
+			_, _ = w.Write([]byte(`{"type":"completion","content":"streamed"}` + "\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","model":"demo","output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"recorded"}]}],"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}`))
+	}))
+	path := filepath.Join(t.TempDir(), "responses.json")
+
+	run := func(rec *testutil.Recorder, baseURL string) (string, string) {
+		t.Helper()
+		client, err := sdk.NewClientWithKey(mustSecretKey(t, "mr_sk_secret"), sdk.WithBaseURL(baseURL), sdk.WithHTTPClient(rec.Client()))
+		if err != nil {
+			t.Fatalf("client: %v", err)
+		}
+		ctx := context.Background()
+		text, err := client.Responses.Text(ctx, sdk.NewModelID("demo"), "sys", "hi")
+		if err != nil {
+			t.Fatalf("text: %v", err)
+		}
+		req, opts, _ := client.Responses.New().Model(sdk.NewModelID("demo")).User("stream").Build()
+		stream, err := client.Responses.Stream(ctx, req, opts...)
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		resp, err := stream.Collect(ctx)
+		if err != nil {
+			t.Fatalf("collect: %v", err)
+		}
+		return text, resp.AssistantText()
+	}
+
+	recorder, err := testutil.NewRecorder(testutil.RecorderConfig{Path: path, Mode: testutil.CassetteRecord})
+	if err != nil {
+		t.Fatalf("recorder: %v", err)
+	}
+	text, streamed := run(recorder, srv.URL)
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "mr_sk_secret") || !strings.Contains(string(data), testutil.Redacted) {
+		t.Fatalf("expected api key to be scrubbed:\n%s", data)
+	}
+
+	replayer, err := testutil.NewRecorder(testutil.RecorderConfig{Path: path, Mode: testutil.CassetteReplay, T: t})
+	if err != nil {
+		t.Fatalf("replayer: %v", err)
+	}
+	gotText, gotStreamed := run(replayer, "http://replay.invalid")
+	if gotText != text || gotStreamed != streamed || text != "recorded" || streamed != "streamed" {
+		t.Fatalf("replay mismatch: text %q/%q streamed %q/%q", gotText, text, gotStreamed, streamed)
+	}
+	if err := replayer.Stop(); err != nil {
+		t.Fatalf("stop replay: %v", err)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Fatalf("expected all interactions replayed, %d unused", len(unused))
+	}
+}
+
+func TestCassetteReplayUnmatched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(path, []byte(`{"interactions":[]}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	replayer, err := testutil.NewRecorder(testutil.RecorderConfig{Path: path, Mode: testutil.CassetteReplay})
+	if err != nil {
+		t.Fatalf("replayer: %v", err)
+	}
+	client, err := sdk.NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		sdk.WithBaseURL("http://replay.invalid"),
+		sdk.WithHTTPClient(replayer.Client()),
+		sdk.WithRetryConfig(sdk.RetryConfig{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	if _, err := client.Responses.Text(context.Background(), sdk.NewModelID("demo"), "sys", "hi"); err == nil || !strings.Contains(err.Error(), "no cassette interaction") {
+		t.Fatalf("expected unmatched request error, got %v", err)
+	}
+	if err := replayer.Stop(); err == nil || !strings.Contains(err.Error(), "POST /responses") {
+		t.Fatalf("expected Stop to report unmatched request, got %v", err)
+	}
+}
+
+func TestCassetteScrubsTokensFromBodiesAndQueries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case routes.AuthCustomerToken:
+			_, _ = w.Write([]byte(`{"token":"cust_tok_secret","token_type":"Bearer","expires_in":3600,"expires_at":"2030-01-01T00:00:00Z","project_id":"11111111-1111-1111-1111-111111111111","customer_external_id":"customer_123"}`))
+		case "/lookup":
+			_, _ = w.Write([]byte(`{"email":"jane@example.com"}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	scrubEmail := func(body string) string { return strings.ReplaceAll(body, "jane@example.com", "user@example.com") }
+
+	run := func(rec *testutil.Recorder, baseURL string) sdk.CustomerToken {
+		t.Helper()
+		client, err := sdk.NewClientWithKey(mustSecretKey(t, "mr_sk_secret"), sdk.WithBaseURL(baseURL), sdk.WithHTTPClient(rec.Client()))
+		if err != nil {
+			t.Fatalf("client: %v", err)
+		}
+		token, err := client.Auth.CustomerToken(context.Background(), sdk.NewCustomerTokenRequestForExternalID(sdk.NewCustomerExternalID("customer_123")))
+		if err != nil {
+			t.Fatalf("customer token: %v", err)
+		}
+		resp, err := rec.Client().Get(baseURL + "/lookup?access_token=query_secret&page=2")
+		if err != nil {
+			t.Fatalf("lookup: %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return token
+	}
+
+	recorder, err := testutil.NewRecorder(testutil.RecorderConfig{Path: path, Mode: testutil.CassetteRecord, ScrubBody: scrubEmail})
+	if err != nil {
+		t.Fatalf("recorder: %v", err)
+	}
+	if token := run(recorder, srv.URL); token.Token != "cust_tok_secret" {
+		t.Fatalf("expected live token, got %q", token.Token)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	for _, secret := range []string{"cust_tok_secret", "query_secret", "jane@example.com", "mr_sk_secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("cassette leaks %q:\n%s", secret, data)
+		}
+	}
+	var cassette testutil.Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil || len(cassette.Interactions) != 2 {
+		t.Fatalf("decode cassette: %v", err)
+	}
+	if body := cassette.Interactions[0].Response.Body; !strings.Contains(body, `"token":"[REDACTED]"`) || !strings.Contains(body, `"token_type":"Bearer"`) {
+		t.Fatalf("expected only the token to be redacted, got %s", body)
+	}
+
+	replayer, err := testutil.NewRecorder(testutil.RecorderConfig{Path: path, Mode: testutil.CassetteReplay, T: t})
+	if err != nil {
+		t.Fatalf("replayer: %v", err)
+	}
+	if token := run(replayer, "http://replay.invalid"); token.Token != testutil.Redacted {
+		t.Fatalf("expected redacted token on replay, got %q", token.Token)
+	}
+	if err := replayer.Stop(); err != nil {
+		t.Fatalf("stop replay: %v", err)
+	}
+}
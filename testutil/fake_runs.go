@@ -0,0 +1,829 @@
+package testutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/modelrelay/modelrelay/sdk/go/headers"
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+// fakeRun is the in-memory state of a workflow run. Execution is synchronous:
+// nodes run in dependency order inside the request that creates the run (or
+// submits tool results), and readers of /events are woken through changed.
+type fakeRun struct {
+	mu         sync.Mutex
+	id         workflow.RunID
+	planHash   workflow.PlanHash
+	spec       workflowintent.Spec
+	inputs     map[string]any
+	customerID string
+	stream     bool
+	modelFor   func(node workflowintent.Node) string
+
+	status  workflow.StatusV0
+	events  []workflow.EventV0Envelope
+	changed chan struct{}
+
+	nodes   map[string]*workflow.NodeResult
+	values  map[string]any
+	texts   map[string]string
+	pending *fakePendingNode
+	outputs map[workflow.OutputName]json.RawMessage
+	cost    map[string]*workflow.CostLineItemV0
+}
+
+// fakePendingNode is an llm node waiting on client tool results.
+type fakePendingNode struct {
+	nodeID    string
+	step      int64
+	requestID string
+	toolCalls []llm.ToolCall
+	req       FakeModelRequest
+}
+
+type fakeRunCreateRequest struct {
+	Spec           json.RawMessage   `json:"spec,omitempty"`
+	PlanHash       workflow.PlanHash `json:"plan_hash,omitempty"`
+	Input          map[string]any    `json:"input,omitempty"`
+	ModelOverride  *string           `json:"model_override,omitempty"`
+	ModelOverrides *struct {
+		Nodes map[string]string `json:"nodes,omitempty"`
+	} `json:"model_overrides,omitempty"`
+	Stream *bool `json:"stream,omitempty"`
+}
+
+// Compile.
+
+func (s *FakeServer) handleWorkflowsCompile(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if !decodeFakeBody(w, r, &raw) {
+		return
+	}
+	_, hash, planJSON, issues := s.compile(raw)
+	if len(issues) > 0 {
+		writeFakeJSON(w, http.StatusBadRequest, workflow.ValidationError{Issues: issues})
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]any{"plan_json": planJSON, "plan_hash": hash})
+}
+
+// compile validates an intent spec and stores its plan. The plan JSON is the
+// re-encoded spec and the plan hash is its sha256.
+func (s *FakeServer) compile(raw json.RawMessage) (workflowintent.Spec, workflow.PlanHash, json.RawMessage, []workflow.Issue) {
+	var spec workflowintent.Spec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return spec, "", nil, []workflow.Issue{{Code: "invalid_json", Path: "$", Message: err.Error()}}
+	}
+	if issues := validateFakeSpec(spec); len(issues) > 0 {
+		return spec, "", nil, issues
+	}
+	planJSON, err := json.Marshal(spec)
+	if err != nil {
+		return spec, "", nil, []workflow.Issue{{Code: "invalid_spec", Path: "$", Message: err.Error()}}
+	}
+	sum := sha256.Sum256(planJSON)
+	hash := workflow.PlanHash(hex.EncodeToString(sum[:]))
+	s.mu.Lock()
+	s.plans[hash] = planJSON
+	s.mu.Unlock()
+	return spec, hash, planJSON, nil
+}
+
+func validateFakeSpec(spec workflowintent.Spec) []workflow.Issue {
+	var issues []workflow.Issue
+	add := func(code, path, msg string) {
+		issues = append(issues, workflow.Issue{Code: code, Path: path, Message: msg})
+	}
+	if !spec.Kind.Valid() {
+		add("invalid_kind", "$.kind", fmt.Sprintf("unsupported kind %q (the fake server only runs %q specs)", spec.Kind, workflowintent.KindWorkflow))
+	}
+	if len(spec.Nodes) == 0 {
+		add("missing_nodes", "$.nodes", "at least one node is required")
+	}
+	ids := make(map[string]bool, len(spec.Nodes))
+	for i, n := range spec.Nodes {
+		path := fmt.Sprintf("$.nodes[%d]", i)
+		switch {
+		case strings.TrimSpace(n.ID) == "":
+			add("missing_node_id", path+".id", "node id is required")
+		case ids[n.ID]:
+			add("duplicate_node_id", path+".id", "duplicate node id "+n.ID)
+		}
+		ids[n.ID] = true
+		if !n.Type.Valid() {
+			add("invalid_node_type", path+".type", fmt.Sprintf("unsupported node type %q", n.Type))
+		}
+	}
+	for i, n := range spec.Nodes {
+		for j, dep := range n.DependsOn {
+			if !ids[dep] {
+				add("unknown_dependency", fmt.Sprintf("$.nodes[%d].depends_on[%d]", i, j), "unknown node "+dep)
+			}
+		}
+	}
+	if len(spec.Outputs) == 0 {
+		add("missing_outputs", "$.outputs", "at least one output is required")
+	}
+	for i, o := range spec.Outputs {
+		if strings.TrimSpace(o.Name) == "" {
+			add("missing_output_name", fmt.Sprintf("$.outputs[%d].name", i), "output name is required")
+		}
+		if !ids[o.From] {
+			add("unknown_output_source", fmt.Sprintf("$.outputs[%d].from", i), "unknown node "+o.From)
+		}
+	}
+	if len(issues) == 0 {
+		if _, cyclic := fakeTopoOrder(spec.Nodes); cyclic {
+			add("cycle", "$.nodes", "workflow graph contains a cycle")
+		}
+	}
+	return issues
+}
+
+// fakeTopoOrder returns node indexes in a dependency-respecting order, keeping
+// spec order where possible.
+func fakeTopoOrder(nodes []workflowintent.Node) ([]int, bool) {
+	done := make(map[string]bool, len(nodes))
+	order := make([]int, 0, len(nodes))
+	for len(order) < len(nodes) {
+		progressed := false
+		for i, n := range nodes {
+			if done[n.ID] {
+				continue
+			}
+			ready := true
+			for _, dep := range n.DependsOn {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				done[n.ID] = true
+				order = append(order, i)
+				progressed = true
+			}
+		}
+		if !progressed {
+			return order, true
+		}
+	}
+	return order, false
+}
+
+// Runs.
+
+func (s *FakeServer) handleRunCreate(w http.ResponseWriter, r *http.Request) {
+	var req fakeRunCreateRequest
+	if !decodeFakeBody(w, r, &req) {
+		return
+	}
+	var spec workflowintent.Spec
+	var hash workflow.PlanHash
+	switch {
+	case len(req.Spec) > 0:
+		var issues []workflow.Issue
+		spec, hash, _, issues = s.compile(req.Spec)
+		if len(issues) > 0 {
+			writeFakeJSON(w, http.StatusBadRequest, workflow.ValidationError{Issues: issues})
+			return
+		}
+	case req.PlanHash != "":
+		s.mu.Lock()
+		plan, ok := s.plans[req.PlanHash]
+		s.mu.Unlock()
+		if !ok {
+			writeFakeError(w, FakeError{Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "plan not found"})
+			return
+		}
+		_ = json.Unmarshal(plan, &spec)
+		hash = req.PlanHash
+	default:
+		writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "VALIDATION_ERROR", Message: "spec or plan_hash is required"})
+		return
+	}
+
+	run := &fakeRun{
+		id:         workflow.NewRunID(),
+		planHash:   hash,
+		spec:       spec,
+		inputs:     req.Input,
+		customerID: r.Header.Get(headers.CustomerID),
+		stream:     req.Stream != nil && *req.Stream,
+		status:     workflow.StatusRunning,
+		changed:    make(chan struct{}),
+		nodes:      make(map[string]*workflow.NodeResult, len(spec.Nodes)),
+		values:     make(map[string]any, len(spec.Nodes)),
+		texts:      make(map[string]string, len(spec.Nodes)),
+		cost:       make(map[string]*workflow.CostLineItemV0),
+	}
+	run.modelFor = func(node workflowintent.Node) string {
+		if req.ModelOverrides != nil {
+			if m := req.ModelOverrides.Nodes[node.ID]; m != "" {
+				return m
+			}
+		}
+		if req.ModelOverride != nil && *req.ModelOverride != "" {
+			return *req.ModelOverride
+		}
+		if node.Model != "" {
+			return node.Model
+		}
+		return spec.Model
+	}
+	for _, n := range spec.Nodes {
+		run.nodes[n.ID] = &workflow.NodeResult{ID: workflow.NodeID(n.ID), Type: workflow.NodeTypeV1(n.Type), Status: workflow.NodeStatusPending}
+	}
+
+	s.mu.Lock()
+	s.runs[run.id] = run
+	s.mu.Unlock()
+
+	run.mu.Lock()
+	run.emitRun(workflow.EventRunCompiled, nil)
+	run.emitRun(workflow.EventRunStarted, nil)
+	s.advance(run)
+	status := run.status
+	run.mu.Unlock()
+
+	writeFakeJSON(w, http.StatusCreated, map[string]any{"run_id": run.id, "status": status, "plan_hash": hash})
+}
+
+func (s *FakeServer) handleRunGet(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.run(w, r)
+	if !ok {
+		return
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	nodes := make([]workflow.NodeResult, 0, len(run.spec.Nodes))
+	for _, n := range run.spec.Nodes {
+		nodes = append(nodes, *run.nodes[n.ID])
+	}
+	cost := workflow.CostSummaryV0{LineItems: []workflow.CostLineItemV0{}}
+	for _, item := range run.cost {
+		cost.LineItems = append(cost.LineItems, *item)
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]any{
+		"run_id":       run.id,
+		"status":       run.status,
+		"plan_hash":    run.planHash,
+		"cost_summary": cost,
+		"nodes":        nodes,
+		"outputs":      run.outputs,
+	})
+}
+
+func (s *FakeServer) handleRunEvents(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.run(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	afterSeq, _ := strconv.ParseInt(q.Get("after_seq"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	wait := q.Get("wait") != "0"
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	sent := 0
+	for {
+		run.mu.Lock()
+		var batch []workflow.EventV0Envelope
+		for _, ev := range run.events {
+			if ev.Seq > afterSeq {
+				batch = append(batch, ev)
+			}
+		}
+		terminal := run.terminal()
+		changed := run.changed
+		run.mu.Unlock()
+
+		for _, ev := range batch {
+			line, _ := json.Marshal(ev)
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return
+			}
+			afterSeq = ev.Seq
+			sent++
+			if limit > 0 && sent >= limit {
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if !wait || terminal {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *FakeServer) handleRunPendingTools(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.run(w, r)
+	if !ok {
+		return
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	pending := []map[string]any{}
+	if p := run.pending; p != nil {
+		calls := make([]workflow.PendingToolCall, 0, len(p.toolCalls))
+		for _, tc := range p.toolCalls {
+			calls = append(calls, workflow.PendingToolCall{ToolCall: fakeToolCallWithArgs(tc)})
+		}
+		pending = append(pending, map[string]any{
+			"node_id":    p.nodeID,
+			"step":       p.step,
+			"request_id": p.requestID,
+			"tool_calls": calls,
+		})
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]any{"run_id": run.id, "pending": pending})
+}
+
+type fakeToolResultsRequest struct {
+	NodeID    string `json:"node_id"`
+	Step      int64  `json:"step"`
+	RequestID string `json:"request_id"`
+	Results   []struct {
+		ToolCall workflow.ToolCall `json:"tool_call"`
+		Output   string            `json:"output"`
+	} `json:"results"`
+}
+
+func (s *FakeServer) handleRunToolResults(w http.ResponseWriter, r *http.Request) {
+	var req fakeToolResultsRequest
+	if !decodeFakeBody(w, r, &req) {
+		return
+	}
+	run, ok := s.run(w, r)
+	if !ok {
+		return
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	p := run.pending
+	if p == nil || p.nodeID != req.NodeID || p.step != req.Step || p.requestID != req.RequestID {
+		writeFakeError(w, FakeError{Status: http.StatusConflict, Code: "CONFLICT", Message: "no pending tool calls match node_id/step/request_id"})
+		return
+	}
+	outputs := make(map[llm.ToolCallID]string, len(req.Results))
+	for _, res := range req.Results {
+		outputs[res.ToolCall.ID] = res.Output
+	}
+	for _, tc := range p.toolCalls {
+		if _, ok := outputs[tc.ID]; !ok {
+			writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "VALIDATION_ERROR", Message: "missing result for tool call " + tc.ID.String()})
+			return
+		}
+	}
+
+	run.pending = nil
+	run.status = workflow.StatusRunning
+	run.nodes[p.nodeID].Status = workflow.NodeStatusRunning
+	p.req.Input = append(p.req.Input, llm.InputItem{Type: llm.InputItemTypeMessage, Role: llm.RoleAssistant, ToolCalls: p.toolCalls})
+	for _, tc := range p.toolCalls {
+		ref := fakeToolCallWithArgs(tc)
+		run.emitNode(workflow.EventNodeToolResult, p.nodeID, func(ev *workflow.EventV0Envelope) {
+			ev.ToolResult = &workflow.NodeToolResult{
+				Step:      p.step,
+				RequestID: p.requestID,
+				ToolCall:  workflow.ToolCall{ID: ref.ID, Name: ref.Name, Arguments: ref.Arguments},
+				Output:    outputs[tc.ID],
+			}
+		})
+		p.req.Input = append(p.req.Input, llm.NewToolResultText(tc.ID, outputs[tc.ID]))
+	}
+	node := run.node(p.nodeID)
+	if s.callLLM(run, node, p.req, p.step+1) {
+		s.advance(run)
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]any{"accepted": len(req.Results), "status": run.status})
+}
+
+func (s *FakeServer) run(w http.ResponseWriter, r *http.Request) (*fakeRun, bool) {
+	id, err := workflow.ParseRunID(r.PathValue("run_id"))
+	if err != nil {
+		writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "INVALID_INPUT", Message: err.Error()})
+		return nil, false
+	}
+	s.mu.Lock()
+	run, ok := s.runs[id]
+	s.mu.Unlock()
+	if !ok {
+		writeFakeError(w, FakeError{Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "run not found"})
+		return nil, false
+	}
+	return run, true
+}
+
+// Execution. All methods below are called with run.mu held.
+
+// advance runs every node whose dependencies have succeeded until the run
+// completes, fails or waits on client tools.
+func (s *FakeServer) advance(run *fakeRun) {
+	order, _ := fakeTopoOrder(run.spec.Nodes)
+	for _, i := range order {
+		if run.status != workflow.StatusRunning {
+			return
+		}
+		node := run.spec.Nodes[i]
+		if run.nodes[node.ID].Status != workflow.NodeStatusPending {
+			continue
+		}
+		run.nodes[node.ID].Status = workflow.NodeStatusRunning
+		run.nodes[node.ID].StartedAt = time.Now().UTC()
+		run.emitNode(workflow.EventNodeStarted, node.ID, nil)
+
+		if node.Type == workflowintent.NodeTypeLLM {
+			if !s.callLLM(run, node, run.llmRequest(node), 1) {
+				return
+			}
+			continue
+		}
+		value, err := run.evalNode(node)
+		if err != nil {
+			run.failNode(node.ID, &workflow.NodeError{Code: "unsupported", Message: err.Error()})
+			return
+		}
+		run.succeedNode(node.ID, value, "")
+	}
+	if run.status == workflow.StatusRunning {
+		run.complete()
+	}
+}
+
+// callLLM performs one model step for an llm node. It reports whether the
+// node finished successfully; otherwise the run is failed or waiting.
+func (s *FakeServer) callLLM(run *fakeRun, node workflowintent.Node, req FakeModelRequest, step int64) bool {
+	resp := s.callModel(req)
+	out := newFakeResponse(req, resp)
+	requestID := "req_" + uuid.NewString()
+	run.emitNode(workflow.EventNodeLLMCall, node.ID, func(ev *workflow.EventV0Envelope) {
+		ev.LLMCall = &workflow.NodeLLMCall{
+			Step:       step,
+			RequestID:  requestID,
+			Provider:   req.Provider,
+			Model:      out.Model,
+			ResponseID: out.ID,
+			StopReason: out.StopReason,
+			Usage:      workflow.TokenUsage{InputTokens: out.Usage.InputTokens, OutputTokens: out.Usage.OutputTokens, TotalTokens: out.Usage.TotalTokens},
+		}
+	})
+	if resp.Err != nil {
+		run.failNode(node.ID, &workflow.NodeError{Code: resp.Err.Code, Message: resp.Err.Message})
+		return false
+	}
+	item := run.cost[out.Model]
+	if item == nil {
+		item = &workflow.CostLineItemV0{ProviderID: workflow.ProviderID(req.Provider), Model: workflow.ModelID(out.Model)}
+		run.cost[out.Model] = item
+	}
+	item.Requests++
+	item.InputTokens += out.Usage.InputTokens
+	item.OutputTokens += out.Usage.OutputTokens
+
+	if len(resp.ToolCalls) > 0 {
+		if node.ToolExecution == nil || node.ToolExecution.Mode != workflowintent.ToolExecutionModeClient {
+			run.failNode(node.ID, &workflow.NodeError{Code: "unsupported", Message: "fake server only executes tool calls in client mode"})
+			return false
+		}
+		for _, tc := range resp.ToolCalls {
+			call := fakeToolCallWithArgs(tc)
+			run.emitNode(workflow.EventNodeToolCall, node.ID, func(ev *workflow.EventV0Envelope) {
+				ev.ToolCall = &workflow.NodeToolCall{Step: step, RequestID: requestID, ToolCall: call}
+			})
+		}
+		run.pending = &fakePendingNode{nodeID: node.ID, step: step, requestID: requestID, toolCalls: resp.ToolCalls, req: req}
+		run.emitNode(workflow.EventNodeWaiting, node.ID, func(ev *workflow.EventV0Envelope) {
+			pending := make([]workflow.PendingToolCall, 0, len(resp.ToolCalls))
+			for _, tc := range resp.ToolCalls {
+				pending = append(pending, workflow.PendingToolCall{ToolCall: fakeToolCallWithArgs(tc)})
+			}
+			ev.Waiting = &workflow.NodeWaiting{Step: step, RequestID: requestID, PendingToolCalls: pending, Reason: "client_tool_execution"}
+		})
+		run.nodes[node.ID].Status = workflow.NodeStatusWaiting
+		run.status = workflow.StatusWaiting
+		return false
+	}
+
+	if run.stream || (node.Stream != nil && *node.Stream) {
+		run.emitNode(workflow.EventNodeOutputDelta, node.ID, func(ev *workflow.EventV0Envelope) {
+			ev.Delta = &workflow.NodeOutputDelta{Kind: workflow.StreamEventKindMessageStart, ResponseID: out.ID, Model: out.Model}
+		})
+		for _, word := range strings.SplitAfter(resp.Text, " ") {
+			if word == "" {
+				continue
+			}
+			run.emitNode(workflow.EventNodeOutputDelta, node.ID, func(ev *workflow.EventV0Envelope) {
+				ev.Delta = &workflow.NodeOutputDelta{Kind: workflow.StreamEventKindMessageDelta, TextDelta: word}
+			})
+		}
+		run.emitNode(workflow.EventNodeOutputDelta, node.ID, func(ev *workflow.EventV0Envelope) {
+			ev.Delta = &workflow.NodeOutputDelta{Kind: workflow.StreamEventKindMessageStop}
+		})
+	}
+
+	var value any
+	raw, _ := json.Marshal(out)
+	_ = json.Unmarshal(raw, &value)
+	run.succeedNode(node.ID, value, resp.Text)
+	return true
+}
+
+func (run *fakeRun) llmRequest(node workflowintent.Node) FakeModelRequest {
+	req := FakeModelRequest{
+		Model:        run.modelFor(node),
+		CustomerID:   run.customerID,
+		OutputFormat: node.OutputFormat,
+		Stop:         node.Stop,
+	}
+	if node.MaxOutputTokens != nil {
+		req.MaxOutputTokens = *node.MaxOutputTokens
+	}
+	for _, ref := range node.Tools {
+		req.Tools = append(req.Tools, ref.Tool)
+	}
+	if len(node.Input) > 0 {
+		for _, item := range node.Input {
+			parts := make([]llm.ContentPart, len(item.Content))
+			for i, part := range item.Content {
+				if part.Type == llm.ContentPartTypeText {
+					part.Text = run.substitute(part.Text)
+				}
+				parts[i] = part
+			}
+			item.Content = parts
+			req.Input = append(req.Input, item)
+		}
+		return req
+	}
+	if node.System != "" {
+		req.Input = append(req.Input, llm.NewSystemText(run.substitute(node.System)))
+	}
+	req.Input = append(req.Input, llm.NewUserText(run.substitute(node.User)))
+	return req
+}
+
+// substitute replaces {{name}} placeholders with run inputs or, failing that,
+// the text output of a completed node with that id.
+func (run *fakeRun) substitute(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start:], "}}")
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSpace(text[start+2 : start+end])
+		b.WriteString(text[:start])
+		if v, ok := run.inputs[name]; ok {
+			if str, isString := v.(string); isString {
+				b.WriteString(str)
+			} else {
+				raw, _ := json.Marshal(v)
+				b.Write(raw)
+			}
+		} else if t, ok := run.texts[name]; ok {
+			b.WriteString(t)
+		} else {
+			b.WriteString(text[start : start+end+2])
+		}
+		text = text[start+end+2:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+func (run *fakeRun) evalNode(node workflowintent.Node) (any, error) {
+	switch node.Type {
+	case workflowintent.NodeTypeJoinAll:
+		out := make(map[string]any, len(node.DependsOn))
+		for _, dep := range node.DependsOn {
+			out[dep] = run.values[dep]
+		}
+		return out, nil
+	case workflowintent.NodeTypeJoinAny:
+		for _, dep := range node.DependsOn {
+			if v, ok := run.values[dep]; ok {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("join.any %s: no dependency produced output", node.ID)
+	case workflowintent.NodeTypeJoinCollect:
+		out := make([]any, 0, len(node.DependsOn))
+		for _, dep := range node.DependsOn {
+			out = append(out, run.values[dep])
+		}
+		if node.Limit != nil && int64(len(out)) > *node.Limit {
+			out = out[:*node.Limit]
+		}
+		return out, nil
+	case workflowintent.NodeTypeTransformJSON:
+		if len(node.Object) > 0 {
+			out := make(map[string]any, len(node.Object))
+			for key, ref := range node.Object {
+				v, err := run.resolve(ref.From, ref.Pointer)
+				if err != nil {
+					return nil, err
+				}
+				out[key] = v
+			}
+			return out, nil
+		}
+		out := map[string]any{}
+		for _, ref := range node.Merge {
+			v, err := run.resolve(ref.From, ref.Pointer)
+			if err != nil {
+				return nil, err
+			}
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("transform.json %s: merge source %s is not an object", node.ID, ref.From)
+			}
+			for k, val := range obj {
+				out[k] = val
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("fake server does not execute %s nodes", node.Type)
+	}
+}
+
+func (run *fakeRun) resolve(from, pointer string) (any, error) {
+	doc, ok := run.values[from]
+	if !ok {
+		if v, isInput := run.inputs[from]; isInput {
+			doc = v
+		} else {
+			return nil, fmt.Errorf("no output for %q", from)
+		}
+	}
+	v, ok := fakeResolvePointer(doc, pointer)
+	if !ok {
+		return nil, fmt.Errorf("pointer %q not found in output of %q", pointer, from)
+	}
+	return v, nil
+}
+
+func (run *fakeRun) succeedNode(id string, value any, text string) {
+	raw, _ := json.Marshal(value)
+	run.values[id] = value
+	run.texts[id] = text
+	result := run.nodes[id]
+	result.Output = raw
+	result.Status = workflow.NodeStatusSucceeded
+	result.EndedAt = time.Now().UTC()
+	run.emitNode(workflow.EventNodeOutput, id, func(ev *workflow.EventV0Envelope) {
+		ev.Output = fakeArtifact(workflow.ArtifactKeyNodeOutputV0, raw)
+	})
+	run.emitNode(workflow.EventNodeSucceeded, id, nil)
+}
+
+func (run *fakeRun) failNode(id string, nodeErr *workflow.NodeError) {
+	result := run.nodes[id]
+	result.Status = workflow.NodeStatusFailed
+	result.Error = nodeErr
+	result.EndedAt = time.Now().UTC()
+	run.emitNode(workflow.EventNodeFailed, id, func(ev *workflow.EventV0Envelope) { ev.Error = nodeErr })
+	run.status = workflow.StatusFailed
+	run.emitRun(workflow.EventRunFailed, func(ev *workflow.EventV0Envelope) {
+		ev.Error = &workflow.NodeError{Code: nodeErr.Code, Message: "node " + id + " failed: " + nodeErr.Message}
+	})
+}
+
+func (run *fakeRun) complete() {
+	outputs := make(map[workflow.OutputName]json.RawMessage, len(run.spec.Outputs))
+	for _, ref := range run.spec.Outputs {
+		v, err := run.resolve(ref.From, ref.Pointer)
+		if err != nil {
+			run.status = workflow.StatusFailed
+			run.emitRun(workflow.EventRunFailed, func(ev *workflow.EventV0Envelope) {
+				ev.Error = &workflow.NodeError{Code: "output_error", Message: "output " + ref.Name + ": " + err.Error()}
+			})
+			return
+		}
+		raw, _ := json.Marshal(v)
+		outputs[workflow.OutputName(ref.Name)] = raw
+	}
+	run.outputs = outputs
+	run.status = workflow.StatusSucceeded
+	raw, _ := json.Marshal(outputs)
+	run.emitRun(workflow.EventRunCompleted, func(ev *workflow.EventV0Envelope) {
+		ev.Outputs = fakeArtifact(workflow.ArtifactKeyRunOutputsV0, raw)
+	})
+}
+
+func (run *fakeRun) node(id string) workflowintent.Node {
+	for _, n := range run.spec.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return workflowintent.Node{ID: id}
+}
+
+func (run *fakeRun) terminal() bool {
+	switch run.status {
+	case workflow.StatusSucceeded, workflow.StatusFailed, workflow.StatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (run *fakeRun) emitRun(typ workflow.EventTypeV0, fill func(*workflow.EventV0Envelope)) {
+	hash := run.planHash
+	run.emit(workflow.EventV0Envelope{Type: typ, PlanHash: &hash}, fill)
+}
+
+func (run *fakeRun) emitNode(typ workflow.EventTypeV0, nodeID string, fill func(*workflow.EventV0Envelope)) {
+	run.emit(workflow.EventV0Envelope{Type: typ, NodeID: workflow.NodeID(nodeID)}, fill)
+}
+
+func (run *fakeRun) emit(ev workflow.EventV0Envelope, fill func(*workflow.EventV0Envelope)) {
+	ev.EnvelopeVersion = workflow.EventEnvelopeVersionV0
+	ev.RunID = run.id
+	ev.Seq = int64(len(run.events) + 1)
+	ev.TS = time.Now().UTC()
+	if fill != nil {
+		fill(&ev)
+	}
+	run.events = append(run.events, ev)
+	close(run.changed)
+	run.changed = make(chan struct{})
+}
+
+func fakeArtifact(key string, payload []byte) *workflow.PayloadArtifact {
+	sum := sha256.Sum256(payload)
+	return &workflow.PayloadArtifact{
+		ArtifactKey: key,
+		Info:        workflow.PayloadInfo{Bytes: int64(len(payload)), SHA256: hex.EncodeToString(sum[:])},
+	}
+}
+
+func fakeToolCallWithArgs(tc llm.ToolCall) workflow.ToolCallWithArguments {
+	out := workflow.ToolCallWithArguments{ID: tc.ID}
+	if tc.Function != nil {
+		out.Name = tc.Function.Name
+		out.Arguments = tc.Function.Arguments
+	}
+	if out.Arguments == "" {
+		out.Arguments = "{}"
+	}
+	return out
+}
+
+// fakeResolvePointer resolves an RFC 6901 JSON pointer against decoded JSON.
+func fakeResolvePointer(doc any, pointer string) (any, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	cur := doc
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[tok]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
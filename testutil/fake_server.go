@@ -0,0 +1,618 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/modelrelay/modelrelay/sdk/go/generated"
+	"github.com/modelrelay/modelrelay/sdk/go/headers"
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/routes"
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+)
+
+// FakeModelRequest is a model call as seen by a FakeModel. It is built from
+// /responses requests, batch items and llm workflow nodes.
+type FakeModelRequest struct {
+	Provider        string
+	Model           string
+	CustomerID      string
+	Input           []llm.InputItem
+	Tools           []llm.Tool
+	ToolChoice      *llm.ToolChoice
+	OutputFormat    *llm.OutputFormat
+	MaxOutputTokens int64
+	Temperature     *float64
+	Stop            []string
+}
+
+// LastUserText returns the text of the last user message in the input.
+func (r FakeModelRequest) LastUserText() string {
+	for i := len(r.Input) - 1; i >= 0; i-- {
+		if r.Input[i].Role == llm.RoleUser {
+			return itemText(r.Input[i])
+		}
+	}
+	return ""
+}
+
+// FakeModelResponse is one scripted model turn.
+type FakeModelResponse struct {
+	Text      string
+	ToolCalls []llm.ToolCall
+	// StopReason defaults to "tool_calls" when ToolCalls is set and "end_turn" otherwise.
+	StopReason string
+	// InputTokens and OutputTokens default to rough word counts.
+	InputTokens  int64
+	OutputTokens int64
+	// Err makes the call fail with an API error.
+	Err *FakeError
+}
+
+// FakeError is an API error returned by the fake server.
+type FakeError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// FakeModel produces the model's reply for a request.
+type FakeModel func(FakeModelRequest) FakeModelResponse
+
+// EchoModel replies with the last user message prefixed by "echo: ".
+func EchoModel(req FakeModelRequest) FakeModelResponse {
+	return FakeModelResponse{Text: "echo: " + req.LastUserText()}
+}
+
+// ScriptedModel replies with turns in order and repeats the last one once the
+// script is exhausted. With no turns it behaves like EchoModel.
+func ScriptedModel(turns ...FakeModelResponse) FakeModel {
+	if len(turns) == 0 {
+		return EchoModel
+	}
+	var mu sync.Mutex
+	next := 0
+	return func(FakeModelRequest) FakeModelResponse {
+		mu.Lock()
+		defer mu.Unlock()
+		turn := turns[min(next, len(turns)-1)]
+		next++
+		return turn
+	}
+}
+
+// FakeServerOption configures NewFakeServer.
+type FakeServerOption func(*FakeServer)
+
+// WithFakeModel sets the model used for responses and llm workflow nodes.
+// The default is EchoModel.
+func WithFakeModel(model FakeModel) FakeServerOption {
+	return func(s *FakeServer) {
+		if model != nil {
+			s.model = model
+		}
+	}
+}
+
+// FakeServer is an in-process stand-in for the ModelRelay API. It serves
+// /responses (blocking and NDJSON streaming), /responses/batch, /sessions,
+// /state-handles, /workflows/compile and /runs (events, pending tools and
+// tool results) from memory, so applications can run against
+// sdk.WithBaseURL(fake.URL) without network access.
+//
+// Requests must carry an API key or bearer token, but credentials are not checked.
+type FakeServer struct {
+	*httptest.Server
+
+	projectID uuid.UUID
+
+	mu           sync.Mutex
+	model        FakeModel
+	requests     []string
+	sessions     map[uuid.UUID]*generated.SessionWithMessagesResponse
+	stateHandles map[uuid.UUID]generated.StateHandleResponse
+	plans        map[workflow.PlanHash]json.RawMessage
+	runs         map[workflow.RunID]*fakeRun
+}
+
+// NewFakeServer starts a FakeServer. Call Close when done.
+func NewFakeServer(opts ...FakeServerOption) *FakeServer {
+	s := &FakeServer{
+		projectID:    uuid.New(),
+		model:        EchoModel,
+		sessions:     make(map[uuid.UUID]*generated.SessionWithMessagesResponse),
+		stateHandles: make(map[uuid.UUID]generated.StateHandleResponse),
+		plans:        make(map[workflow.PlanHash]json.RawMessage),
+		runs:         make(map[workflow.RunID]*fakeRun),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+routes.Responses, s.handleResponses)
+	mux.HandleFunc("POST "+routes.ResponsesBatch, s.handleResponsesBatch)
+	mux.HandleFunc("POST /sessions", s.handleSessionCreate)
+	mux.HandleFunc("GET /sessions", s.handleSessionList)
+	mux.HandleFunc("GET /sessions/{id}", s.handleSessionGet)
+	mux.HandleFunc("DELETE /sessions/{id}", s.handleSessionDelete)
+	mux.HandleFunc("POST /sessions/{id}/messages", s.handleSessionMessage)
+	mux.HandleFunc("POST /state-handles", s.handleStateHandleCreate)
+	mux.HandleFunc("GET /state-handles", s.handleStateHandleList)
+	mux.HandleFunc("DELETE /state-handles/{id}", s.handleStateHandleDelete)
+	mux.HandleFunc("POST "+routes.WorkflowsCompile, s.handleWorkflowsCompile)
+	mux.HandleFunc("POST "+routes.Runs, s.handleRunCreate)
+	mux.HandleFunc("GET "+routes.RunsByID, s.handleRunGet)
+	mux.HandleFunc("GET "+routes.RunsEvents, s.handleRunEvents)
+	mux.HandleFunc("GET "+routes.RunsPendingTools, s.handleRunPendingTools)
+	mux.HandleFunc("POST "+routes.RunsToolResults, s.handleRunToolResults)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeError(w, FakeError{Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "fake server: no route for " + r.Method + " " + r.URL.Path})
+	})
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		if r.Header.Get(headers.APIKey) == "" && r.Header.Get("Authorization") == "" {
+			writeFakeError(w, FakeError{Status: http.StatusUnauthorized, Code: "UNAUTHORIZED", Message: "missing credentials"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// SetModel replaces the model for subsequent calls.
+func (s *FakeServer) SetModel(model FakeModel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.model = model
+}
+
+// Requests returns the "METHOD /path" of every request received so far.
+func (s *FakeServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *FakeServer) callModel(req FakeModelRequest) FakeModelResponse {
+	s.mu.Lock()
+	model := s.model
+	s.mu.Unlock()
+	resp := model(req)
+	if resp.StopReason == "" {
+		resp.StopReason = "end_turn"
+		if len(resp.ToolCalls) > 0 {
+			resp.StopReason = "tool_calls"
+		}
+	}
+	if resp.InputTokens == 0 {
+		for _, item := range req.Input {
+			resp.InputTokens += int64(len(strings.Fields(itemText(item))))
+		}
+	}
+	if resp.OutputTokens == 0 {
+		resp.OutputTokens = int64(len(strings.Fields(resp.Text)))
+	}
+	return resp
+}
+
+// Wire formats.
+
+type fakeResponsesRequest struct {
+	Provider        string            `json:"provider,omitempty"`
+	Model           string            `json:"model,omitempty"`
+	Input           []llm.InputItem   `json:"input"`
+	OutputFormat    *llm.OutputFormat `json:"output_format,omitempty"`
+	MaxOutputTokens int64             `json:"max_output_tokens,omitempty"`
+	Temperature     *float64          `json:"temperature,omitempty"`
+	Stop            []string          `json:"stop,omitempty"`
+	Tools           []llm.Tool        `json:"tools,omitempty"`
+	ToolChoice      *llm.ToolChoice   `json:"tool_choice,omitempty"`
+}
+
+func (p fakeResponsesRequest) modelRequest(customerID string) FakeModelRequest {
+	return FakeModelRequest{
+		Provider:        p.Provider,
+		Model:           p.Model,
+		CustomerID:      customerID,
+		Input:           p.Input,
+		Tools:           p.Tools,
+		ToolChoice:      p.ToolChoice,
+		OutputFormat:    p.OutputFormat,
+		MaxOutputTokens: p.MaxOutputTokens,
+		Temperature:     p.Temperature,
+		Stop:            p.Stop,
+	}
+}
+
+type fakeUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+type fakeResponse struct {
+	ID         string           `json:"id"`
+	Provider   string           `json:"provider,omitempty"`
+	Model      string           `json:"model"`
+	Output     []llm.OutputItem `json:"output"`
+	StopReason string           `json:"stop_reason,omitempty"`
+	Usage      fakeUsage        `json:"usage"`
+}
+
+func newFakeResponse(req FakeModelRequest, resp FakeModelResponse) fakeResponse {
+	model := req.Model
+	if model == "" {
+		model = "fake-model"
+	}
+	item := llm.OutputItem{Type: llm.OutputItemTypeMessage, Role: llm.RoleAssistant, ToolCalls: resp.ToolCalls}
+	if resp.Text != "" {
+		item.Content = []llm.ContentPart{llm.TextPart(resp.Text)}
+	}
+	return fakeResponse{
+		ID:         "resp_" + uuid.NewString(),
+		Provider:   req.Provider,
+		Model:      model,
+		Output:     []llm.OutputItem{item},
+		StopReason: resp.StopReason,
+		Usage: fakeUsage{
+			InputTokens:  resp.InputTokens,
+			OutputTokens: resp.OutputTokens,
+			TotalTokens:  resp.InputTokens + resp.OutputTokens,
+		},
+	}
+}
+
+// Responses.
+
+func (s *FakeServer) handleResponses(w http.ResponseWriter, r *http.Request) {
+	var payload fakeResponsesRequest
+	if !decodeFakeBody(w, r, &payload) {
+		return
+	}
+	if len(payload.Input) == 0 {
+		writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "VALIDATION_ERROR", Message: "input is required"})
+		return
+	}
+	req := payload.modelRequest(r.Header.Get(headers.CustomerID))
+	resp := s.callModel(req)
+	requestID := r.Header.Get(headers.RequestID)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	w.Header().Set(headers.RequestID, requestID)
+	out := newFakeResponse(req, resp)
+
+	if !strings.Contains(r.Header.Get("Accept"), "ndjson") {
+		if resp.Err != nil {
+			writeFakeError(w, *resp.Err)
+			return
+		}
+		writeFakeJSON(w, http.StatusOK, out)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	emit := func(record map[string]any) {
+		line, _ := json.Marshal(record)
+		_, _ = w.Write(append(line, '\n'))
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	emit(map[string]any{"type": "start", "request_id": out.ID, "model": out.Model})
+	if resp.Err != nil {
+		emit(map[string]any{"type": "error", "code": resp.Err.Code, "message": resp.Err.Message, "status": resp.Err.Status})
+		return
+	}
+	for _, word := range strings.SplitAfter(resp.Text, " ") {
+		if word == "" {
+			continue
+		}
+		emit(map[string]any{"type": "update", "delta": word})
+	}
+	completion := map[string]any{
+		"type":        "completion",
+		"content":     resp.Text,
+		"stop_reason": out.StopReason,
+		"usage":       out.Usage,
+	}
+	if len(resp.ToolCalls) > 0 {
+		completion["tool_calls"] = resp.ToolCalls
+	}
+	emit(completion)
+}
+
+type fakeBatchRequest struct {
+	Requests []struct {
+		ID string `json:"id"`
+		fakeResponsesRequest
+	} `json:"requests"`
+}
+
+type fakeBatchResult struct {
+	ID       string        `json:"id"`
+	Status   string        `json:"status"`
+	Response *fakeResponse `json:"response,omitempty"`
+	Error    *struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+		Code    string `json:"code,omitempty"`
+	} `json:"error,omitempty"`
+}
+
+func (s *FakeServer) handleResponsesBatch(w http.ResponseWriter, r *http.Request) {
+	var payload fakeBatchRequest
+	if !decodeFakeBody(w, r, &payload) {
+		return
+	}
+	customerID := r.Header.Get(headers.CustomerID)
+	var usage struct {
+		TotalInputTokens   int64 `json:"total_input_tokens"`
+		TotalOutputTokens  int64 `json:"total_output_tokens"`
+		TotalRequests      int   `json:"total_requests"`
+		SuccessfulRequests int   `json:"successful_requests"`
+		FailedRequests     int   `json:"failed_requests"`
+	}
+	results := make([]fakeBatchResult, 0, len(payload.Requests))
+	for _, item := range payload.Requests {
+		req := item.modelRequest(customerID)
+		resp := s.callModel(req)
+		usage.TotalRequests++
+		result := fakeBatchResult{ID: item.ID}
+		if resp.Err != nil {
+			usage.FailedRequests++
+			result.Status = "error"
+			result.Error = &struct {
+				Status  int    `json:"status"`
+				Message string `json:"message"`
+				Code    string `json:"code,omitempty"`
+			}{Status: resp.Err.Status, Message: resp.Err.Message, Code: resp.Err.Code}
+		} else {
+			usage.SuccessfulRequests++
+			out := newFakeResponse(req, resp)
+			usage.TotalInputTokens += out.Usage.InputTokens
+			usage.TotalOutputTokens += out.Usage.OutputTokens
+			result.Status = "success"
+			result.Response = &out
+		}
+		results = append(results, result)
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]any{
+		"id":      "batch_" + uuid.NewString(),
+		"results": results,
+		"usage":   usage,
+	})
+}
+
+// Sessions.
+
+func (s *FakeServer) handleSessionCreate(w http.ResponseWriter, r *http.Request) {
+	var req generated.SessionCreateRequest
+	if !decodeFakeBody(w, r, &req) {
+		return
+	}
+	now := time.Now().UTC()
+	session := &generated.SessionWithMessagesResponse{
+		Id:         uuid.New(),
+		ProjectId:  s.projectID,
+		CustomerId: req.CustomerId,
+		Metadata:   map[string]interface{}{},
+		Messages:   []generated.SessionMessageResponse{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if req.Metadata != nil {
+		session.Metadata = *req.Metadata
+	}
+	s.mu.Lock()
+	s.sessions[session.Id] = session
+	out := sessionSummary(session)
+	s.mu.Unlock()
+	writeFakeJSON(w, http.StatusCreated, out)
+}
+
+func (s *FakeServer) handleSessionList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	list := make([]generated.SessionResponse, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if c := q.Get("customer_id"); c != "" && (session.CustomerId == nil || session.CustomerId.String() != c) {
+			continue
+		}
+		list = append(list, sessionSummary(session))
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	list = paginate(list, q.Get("offset"), q.Get("limit"))
+	writeFakeJSON(w, http.StatusOK, generated.SessionListResponse{Sessions: list})
+}
+
+func (s *FakeServer) handleSessionGet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, session)
+}
+
+func (s *FakeServer) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	delete(s.sessions, session.Id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *FakeServer) handleSessionMessage(w http.ResponseWriter, r *http.Request) {
+	var req generated.SessionMessageCreateRequest
+	if !decodeFakeBody(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	msg := generated.SessionMessageResponse{
+		Id:        uuid.New(),
+		Role:      req.Role,
+		Content:   req.Content,
+		RunId:     req.RunId,
+		Seq:       int32(len(session.Messages) + 1),
+		CreatedAt: now,
+	}
+	session.Messages = append(session.Messages, msg)
+	session.MessageCount = int64(len(session.Messages))
+	session.UpdatedAt = now
+	writeFakeJSON(w, http.StatusCreated, msg)
+}
+
+// session resolves the {id} path value. Callers hold s.mu.
+func (s *FakeServer) session(w http.ResponseWriter, r *http.Request) (*generated.SessionWithMessagesResponse, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "INVALID_INPUT", Message: "invalid session id"})
+		return nil, false
+	}
+	session, ok := s.sessions[id]
+	if !ok {
+		writeFakeError(w, FakeError{Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "session not found"})
+		return nil, false
+	}
+	return session, true
+}
+
+func sessionSummary(session *generated.SessionWithMessagesResponse) generated.SessionResponse {
+	return generated.SessionResponse{
+		Id:           session.Id,
+		ProjectId:    session.ProjectId,
+		CustomerId:   session.CustomerId,
+		Metadata:     session.Metadata,
+		MessageCount: session.MessageCount,
+		CreatedAt:    session.CreatedAt,
+		UpdatedAt:    session.UpdatedAt,
+	}
+}
+
+// State handles.
+
+func (s *FakeServer) handleStateHandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req generated.StateHandleCreateRequest
+	if !decodeFakeBody(w, r, &req) {
+		return
+	}
+	now := time.Now().UTC()
+	handle := generated.StateHandleResponse{
+		Id:        uuid.New(),
+		ProjectId: s.projectID,
+		CreatedAt: now.Format(time.RFC3339Nano),
+	}
+	if req.TtlSeconds != nil {
+		expires := now.Add(time.Duration(*req.TtlSeconds) * time.Second).Format(time.RFC3339Nano)
+		handle.ExpiresAt = &expires
+	}
+	s.mu.Lock()
+	s.stateHandles[handle.Id] = handle
+	s.mu.Unlock()
+	writeFakeJSON(w, http.StatusCreated, handle)
+}
+
+func (s *FakeServer) handleStateHandleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	list := make([]generated.StateHandleResponse, 0, len(s.stateHandles))
+	for _, handle := range s.stateHandles {
+		list = append(list, handle)
+	}
+	s.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt < list[j].CreatedAt })
+	q := r.URL.Query()
+	list = paginate(list, q.Get("offset"), q.Get("limit"))
+	writeFakeJSON(w, http.StatusOK, generated.StateHandleListResponse{StateHandles: list})
+}
+
+func (s *FakeServer) handleStateHandleDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "INVALID_INPUT", Message: "invalid state handle id"})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.stateHandles[id]; !ok {
+		writeFakeError(w, FakeError{Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "state handle not found"})
+		return
+	}
+	delete(s.stateHandles, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Helpers.
+
+func decodeFakeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeFakeError(w, FakeError{Status: http.StatusBadRequest, Code: "INVALID_INPUT", Message: fmt.Sprintf("invalid JSON body: %v", err)})
+		return false
+	}
+	return true
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, e FakeError) {
+	if e.Status == 0 {
+		e.Status = http.StatusInternalServerError
+	}
+	writeFakeJSON(w, e.Status, map[string]string{
+		"error":   http.StatusText(e.Status),
+		"code":    e.Code,
+		"message": e.Message,
+	})
+}
+
+func paginate[T any](items []T, offset, limit string) []T {
+	if n, err := strconv.Atoi(offset); err == nil && n > 0 {
+		items = items[min(n, len(items)):]
+	}
+	if n, err := strconv.Atoi(limit); err == nil && n > 0 && n < len(items) {
+		items = items[:n]
+	}
+	return items
+}
+
+func itemText(item llm.InputItem) string {
+	var parts []string
+	for _, part := range item.Content {
+		if part.Type == llm.ContentPartTypeText {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -142,3 +142,11 @@ func (p *CustomerTokenProvider) mint(ctx context.Context) (CustomerToken, error)
 	}
 	return tok, nil
 }
+
+// rateLimitCustomer keys customer rate limiting for clients built on this provider.
+func (p *CustomerTokenProvider) rateLimitCustomer() string {
+	if p.request.CustomerID != nil {
+		return p.request.CustomerID.String()
+	}
+	return p.request.CustomerExternalID.String()
+}
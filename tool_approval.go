@@ -0,0 +1,247 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// ToolApprovalAction is the outcome of a tool approval check.
+type ToolApprovalAction string
+
+const (
+	// ToolApprovalApprove executes the tool call as requested.
+	ToolApprovalApprove ToolApprovalAction = "approve"
+	// ToolApprovalDeny skips execution and feeds Message back to the model.
+	ToolApprovalDeny ToolApprovalAction = "deny"
+	// ToolApprovalRewrite executes the tool call with replacement Arguments.
+	ToolApprovalRewrite ToolApprovalAction = "rewrite"
+)
+
+// ToolApproval is the decision returned by a ToolApprovalPolicy.
+type ToolApproval struct {
+	Action ToolApprovalAction
+	// Message explains a denial. It is sent to the model as the tool result.
+	Message string
+	// Arguments replaces the call's JSON arguments when Action is
+	// ToolApprovalRewrite. The rewritten call is the one recorded in the
+	// conversation history.
+	Arguments string
+}
+
+// ApproveToolCall approves a tool call unchanged.
+func ApproveToolCall() ToolApproval {
+	return ToolApproval{Action: ToolApprovalApprove}
+}
+
+// DenyToolCall denies a tool call; message is returned to the model.
+func DenyToolCall(message string) ToolApproval {
+	return ToolApproval{Action: ToolApprovalDeny, Message: message}
+}
+
+// RewriteToolCall approves a tool call but executes it with the given JSON arguments.
+func RewriteToolCall(arguments string) ToolApproval {
+	return ToolApproval{Action: ToolApprovalRewrite, Arguments: arguments}
+}
+
+// ToolApprovalPolicy is consulted before each tool call an agent executes.
+//
+// Returning an error, or a ToolApproval without an Action, aborts the agent
+// run.
+type ToolApprovalPolicy interface {
+	Approve(ctx context.Context, call llm.ToolCall) (ToolApproval, error)
+}
+
+// ToolApprovalFunc adapts a function to ToolApprovalPolicy.
+type ToolApprovalFunc func(ctx context.Context, call llm.ToolCall) (ToolApproval, error)
+
+// Approve implements ToolApprovalPolicy.
+func (f ToolApprovalFunc) Approve(ctx context.Context, call llm.ToolCall) (ToolApproval, error) {
+	return f(ctx, call)
+}
+
+// DeniedToolCall records a tool call rejected by a ToolApprovalPolicy.
+type DeniedToolCall struct {
+	ToolCall llm.ToolCall
+	Message  string
+}
+
+// ToolCallDeniedError is the execution error reported for denied tool calls.
+type ToolCallDeniedError struct {
+	ToolName ToolName
+	Message  string
+}
+
+func (e *ToolCallDeniedError) Error() string {
+	msg := strings.TrimSpace(e.Message)
+	if msg == "" {
+		msg = "no reason given"
+	}
+	return "tool call '" + e.ToolName.String() + "' denied: " + msg
+}
+
+// AllowToolsPolicy approves calls to the named tools and denies everything else.
+func AllowToolsPolicy(names ...ToolName) ToolApprovalPolicy {
+	allowed := make(map[ToolName]struct{}, len(names))
+	for _, name := range names {
+		allowed[name] = struct{}{}
+	}
+	return ToolApprovalFunc(func(_ context.Context, call llm.ToolCall) (ToolApproval, error) {
+		if _, ok := allowed[GetToolName(call)]; ok {
+			return ApproveToolCall(), nil
+		}
+		return DenyToolCall("tool is not in the allowlist"), nil
+	})
+}
+
+// DefaultMutatingTools are the built-in tools that can modify the local machine.
+var DefaultMutatingTools = []ToolName{ToolNameWriteFile, ToolNameFSEdit, ToolNameBash}
+
+// AskForMutatingToolsPolicy approves read-only calls and delegates calls to
+// mutating tools to ask (for example TerminalApprovalPolicy). When mutating is
+// empty, DefaultMutatingTools is used.
+func AskForMutatingToolsPolicy(ask ToolApprovalPolicy, mutating ...ToolName) ToolApprovalPolicy {
+	if len(mutating) == 0 {
+		mutating = DefaultMutatingTools
+	}
+	set := make(map[ToolName]struct{}, len(mutating))
+	for _, name := range mutating {
+		set[name] = struct{}{}
+	}
+	return ToolApprovalFunc(func(ctx context.Context, call llm.ToolCall) (ToolApproval, error) {
+		if _, ok := set[GetToolName(call)]; !ok {
+			return ApproveToolCall(), nil
+		}
+		if ask == nil {
+			return DenyToolCall("mutating tools require approval"), nil
+		}
+		return ask.Approve(ctx, call)
+	})
+}
+
+// TerminalApprovalPolicy asks a human on a terminal before each call.
+//
+// The prompt is written to out and a single line is read from in: "y"/"yes"
+// approves, anything else denies (the typed text, if any, becomes the denial
+// message sent to the model).
+func TerminalApprovalPolicy(in io.Reader, out io.Writer) ToolApprovalPolicy {
+	var mu sync.Mutex
+	reader := bufio.NewReader(in)
+	return ToolApprovalFunc(func(ctx context.Context, call llm.ToolCall) (ToolApproval, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return ToolApproval{}, err
+		}
+
+		args := GetToolArgsRaw(call)
+		var pretty json.RawMessage
+		if json.Unmarshal([]byte(args), &pretty) == nil {
+			if b, err := json.MarshalIndent(pretty, "  ", "  "); err == nil {
+				args = string(b)
+			}
+		}
+		if _, err := fmt.Fprintf(out, "Allow tool %q with arguments:\n  %s\n[y/N or reason]: ", GetToolName(call).String(), args); err != nil {
+			return ToolApproval{}, err
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return ToolApproval{}, fmt.Errorf("tool approval: read answer: %w", err)
+		}
+		answer := strings.TrimSpace(line)
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return ApproveToolCall(), nil
+		case "", "n", "no":
+			return DenyToolCall("the user declined this tool call"), nil
+		default:
+			return DenyToolCall(answer), nil
+		}
+	})
+}
+
+// agentToolPlan is the outcome of approving a batch of tool calls.
+type agentToolPlan struct {
+	// calls holds the calls to record in history, with rewritten arguments
+	// applied, in input order.
+	calls []llm.ToolCall
+	// results holds the denial result of each denied call.
+	results []ToolExecutionResult
+	denied  []DeniedToolCall
+}
+
+// approveAgentToolCalls consults policy for each call. A decision without an
+// action is an error rather than an implicit approval.
+func approveAgentToolCalls(ctx context.Context, policy ToolApprovalPolicy, calls []llm.ToolCall) (agentToolPlan, error) {
+	plan := agentToolPlan{
+		calls:   append([]llm.ToolCall(nil), calls...),
+		results: make([]ToolExecutionResult, len(calls)),
+	}
+	if policy == nil {
+		return plan, nil
+	}
+	for i, call := range calls {
+		decision, err := policy.Approve(ctx, call)
+		if err != nil {
+			return agentToolPlan{}, err
+		}
+		switch decision.Action {
+		case ToolApprovalApprove:
+		case ToolApprovalRewrite:
+			fn := llm.FunctionCall{Name: GetToolName(call), Arguments: decision.Arguments}
+			plan.calls[i].Function = &fn
+		case ToolApprovalDeny:
+			plan.denied = append(plan.denied, DeniedToolCall{ToolCall: call, Message: decision.Message})
+			plan.results[i] = ToolExecutionResult{
+				ToolCallID: call.ID,
+				ToolName:   GetToolName(call),
+				Error:      &ToolCallDeniedError{ToolName: GetToolName(call), Message: decision.Message},
+			}
+		case "":
+			return agentToolPlan{}, fmt.Errorf("tool approval: no action for tool call %q", call.ID)
+		default:
+			return agentToolPlan{}, fmt.Errorf("tool approval: unknown action %q", decision.Action)
+		}
+	}
+	return plan, nil
+}
+
+// execute runs the calls that were not denied. Results are returned in input
+// order; denied calls report a ToolCallDeniedError.
+func (p agentToolPlan) execute(ctx context.Context, registry *ToolRegistry) []ToolExecutionResult {
+	if len(p.denied) == 0 {
+		return registry.ExecuteAllContext(ctx, p.calls)
+	}
+	results := append([]ToolExecutionResult(nil), p.results...)
+	var approved []llm.ToolCall
+	var approvedIdx []int
+	for i, call := range p.calls {
+		if results[i].Error == nil {
+			approved = append(approved, call)
+			approvedIdx = append(approvedIdx, i)
+		}
+	}
+	for j, res := range registry.ExecuteAllContext(ctx, approved) {
+		results[approvedIdx[j]] = res
+	}
+	return results
+}
+
+// executeAgentToolCalls applies the approval policy and executes the approved
+// calls. It is used where the calls are already recorded, so rewritten
+// arguments only affect execution.
+func executeAgentToolCalls(ctx context.Context, registry *ToolRegistry, policy ToolApprovalPolicy, calls []llm.ToolCall) ([]ToolExecutionResult, []DeniedToolCall, error) {
+	plan, err := approveAgentToolCalls(ctx, policy, calls)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan.execute(ctx, registry), plan.denied, nil
+}
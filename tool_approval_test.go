@@ -0,0 +1,214 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
+)
+
+// newAgentJSONServer serves the given /responses bodies in order (repeating the
+// last one) and records the decoded request payloads.
+func newAgentJSONServer(t *testing.T, bodies []string) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		_ = json.Unmarshal(raw, &payload)
+		mu.Lock()
+		idx := len(requests)
+		requests = append(requests, payload)
+		mu.Unlock()
+		if idx >= len(bodies) {
+			idx = len(bodies) - 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bodies[idx]))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestExecuteAgentToolCallsApproval(t *testing.T) {
+	var executed []string
+	registry := NewToolRegistry().
+		Register("read", func(args map[string]any, call llm.ToolCall) (any, error) {
+			executed = append(executed, "read:"+call.Function.Arguments)
+			return "read-ok", nil
+		}).
+		Register("write", func(args map[string]any, call llm.ToolCall) (any, error) {
+			executed = append(executed, "write")
+			return "write-ok", nil
+		})
+
+	policy := ToolApprovalFunc(func(_ context.Context, call llm.ToolCall) (ToolApproval, error) {
+		switch GetToolName(call) {
+		case "write":
+			return DenyToolCall("writes are disabled"), nil
+		case "read":
+			return RewriteToolCall(`{"path":"safe.txt"}`), nil
+		}
+		return ApproveToolCall(), nil
+	})
+
+	results, denied, err := executeAgentToolCalls(context.Background(), registry, policy, []llm.ToolCall{
+		NewToolCall("c1", "write", `{}`),
+		NewToolCall("c2", "read", `{"path":"/etc/passwd"}`),
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(executed) != 1 || executed[0] != `read:{"path":"safe.txt"}` {
+		t.Fatalf("unexpected executions %v", executed)
+	}
+	var deniedErr *ToolCallDeniedError
+	if !errors.As(results[0].Error, &deniedErr) || deniedErr.Message != "writes are disabled" {
+		t.Fatalf("expected denial for first call, got %+v", results[0])
+	}
+	if results[1].ToolCallID != "c2" || results[1].Result != "read-ok" {
+		t.Fatalf("unexpected second result %+v", results[1])
+	}
+	if len(denied) != 1 || denied[0].ToolCall.ID != "c1" {
+		t.Fatalf("unexpected denied calls %+v", denied)
+	}
+
+	empty := ToolApprovalFunc(func(context.Context, llm.ToolCall) (ToolApproval, error) { return ToolApproval{}, nil })
+	if _, _, err := executeAgentToolCalls(context.Background(), registry, empty, []llm.ToolCall{NewToolCall("c3", "write", `{}`)}); err == nil {
+		t.Fatal("expected an approval without an action to fail")
+	}
+	if len(executed) != 1 {
+		t.Fatalf("expected no execution without an action, got %v", executed)
+	}
+}
+
+func TestToolApprovalPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("allowlist", func(t *testing.T) {
+		policy := AllowToolsPolicy("read")
+		if d, _ := policy.Approve(ctx, NewToolCall("1", "read", "{}")); d.Action != ToolApprovalApprove {
+			t.Fatalf("expected approve, got %+v", d)
+		}
+		if d, _ := policy.Approve(ctx, NewToolCall("2", "write", "{}")); d.Action != ToolApprovalDeny {
+			t.Fatalf("expected deny, got %+v", d)
+		}
+	})
+
+	t.Run("ask for mutating tools", func(t *testing.T) {
+		asked := 0
+		ask := ToolApprovalFunc(func(context.Context, llm.ToolCall) (ToolApproval, error) {
+			asked++
+			return DenyToolCall("no"), nil
+		})
+		policy := AskForMutatingToolsPolicy(ask)
+		if d, _ := policy.Approve(ctx, NewToolCall("1", ToolNameFSReadFile, "{}")); d.Action != ToolApprovalApprove {
+			t.Fatalf("expected approve for read-only tool, got %+v", d)
+		}
+		if d, _ := policy.Approve(ctx, NewToolCall("2", ToolNameWriteFile, "{}")); d.Action != ToolApprovalDeny {
+			t.Fatalf("expected deny for write_file, got %+v", d)
+		}
+		if asked != 1 {
+			t.Fatalf("expected ask once, got %d", asked)
+		}
+	})
+
+	t.Run("terminal prompt", func(t *testing.T) {
+		var out bytes.Buffer
+		policy := TerminalApprovalPolicy(strings.NewReader("y\nuse a relative path\n"), &out)
+		d, err := policy.Approve(ctx, NewToolCall("1", "bash", `{"command":"ls"}`))
+		if err != nil || d.Action != ToolApprovalApprove {
+			t.Fatalf("expected approve, got %+v err=%v", d, err)
+		}
+		d, err = policy.Approve(ctx, NewToolCall("2", "bash", `{"command":"rm -rf /"}`))
+		if err != nil || d.Action != ToolApprovalDeny || d.Message != "use a relative path" {
+			t.Fatalf("expected deny with reason, got %+v err=%v", d, err)
+		}
+		if !strings.Contains(out.String(), `Allow tool "bash"`) {
+			t.Fatalf("unexpected prompt %q", out.String())
+		}
+	})
+}
+
+func TestAgentRecordsDeniedToolCalls(t *testing.T) {
+	srv, requests := newAgentJSONServer(t, []string{
+		`{"id":"resp_1","model":"demo","usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2},
+		  "output":[{"type":"message","role":"assistant","content":[],"tool_calls":[{"id":"call_1","type":"function","function":{"name":"write","arguments":"{}"}}]}]}`,
+		`{"id":"resp_2","model":"demo","usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2},
+		  "output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"ok, skipped"}]}]}`,
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	executed := false
+	tools := NewToolBuilder().Add("write", "Write", nil, func(args map[string]any, call llm.ToolCall) (any, error) {
+		executed = true
+		return "written", nil
+	})
+
+	result, err := client.Agent(context.Background(), "demo", AgentOptions{
+		Tools:    tools,
+		Prompt:   "write something",
+		Approval: AllowToolsPolicy(),
+	})
+	if err != nil {
+		t.Fatalf("agent: %v", err)
+	}
+	if executed {
+		t.Fatal("expected denied tool not to execute")
+	}
+	if result.Output != "ok, skipped" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	if len(result.DeniedToolCalls) != 1 || result.DeniedToolCalls[0].ToolCall.ID != "call_1" {
+		t.Fatalf("unexpected denied calls %+v", result.DeniedToolCalls)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+	raw, _ := json.Marshal((*requests)[1]["input"])
+	if !strings.Contains(string(raw), "denied") {
+		t.Fatalf("expected denial fed back to model, got %s", raw)
+	}
+}
+
+func TestAgentRecordsRewrittenToolCalls(t *testing.T) {
+	srv, requests := newAgentJSONServer(t, []string{
+		`{"id":"resp_1","model":"demo","usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2},
+		  "output":[{"type":"message","role":"assistant","content":[],"tool_calls":[{"id":"call_1","type":"function","function":{"name":"read","arguments":"{\"path\":\"/etc/passwd\"}"}}]}]}`,
+		`{"id":"resp_2","model":"demo","usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2},
+		  "output":[{"type":"message","role":"assistant","content":[{"type":"text","text":"done"}]}]}`,
+	})
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	var got string
+	tools := NewToolBuilder().Add("read", "Read", nil, func(args map[string]any, call llm.ToolCall) (any, error) {
+		got = call.Function.Arguments
+		return "contents", nil
+	})
+	_, err := client.Agent(context.Background(), "demo", AgentOptions{
+		Tools:  tools,
+		Prompt: "read a file",
+		Approval: ToolApprovalFunc(func(context.Context, llm.ToolCall) (ToolApproval, error) {
+			return RewriteToolCall(`{"path":"safe.txt"}`), nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("agent: %v", err)
+	}
+	if got != `{"path":"safe.txt"}` {
+		t.Fatalf("expected rewritten arguments to execute, got %s", got)
+	}
+	raw, _ := json.Marshal((*requests)[1]["input"])
+	if strings.Contains(string(raw), "passwd") || !strings.Contains(string(raw), "safe.txt") {
+		t.Fatalf("expected rewritten call in the next request, got %s", raw)
+	}
+}
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -43,13 +44,14 @@ import (
 //	defs, registry := tools.Build()
 type ToolBuilder struct {
 	entries []toolEntry
+	serial  []ToolName
 }
 
 type toolEntry struct {
 	name        ToolName
 	description string
 	tool        llm.Tool
-	handler     ToolHandler
+	handler     ToolHandlerContext
 }
 
 // NewToolBuilder creates a new empty ToolBuilder.
@@ -77,60 +79,9 @@ func NewToolBuilder() *ToolBuilder {
 //		return performSearch(args.Query, args.MaxResults), nil
 //	})
 func AddFunc[T any](b *ToolBuilder, name ToolName, description string, handler func(T) (any, error)) *ToolBuilder {
-	tool, err := FunctionToolFromType[T](name, description)
-	if err != nil {
-		// This should rarely happen since we're generating from a valid Go type.
-		// If it does, we'll panic since this is a programming error.
-		panic(fmt.Sprintf("failed to create tool schema for %s: %v", name, err))
-	}
-
-	// Wrap the typed handler to match ToolHandler signature
-	wrappedHandler := func(args map[string]any, call llm.ToolCall) (any, error) {
-		// Parse and validate arguments into the typed struct
-		var typedArgs T
-		argsJSON, err := json.Marshal(args)
-		if err != nil {
-			return nil, &ToolArgsError{
-				Message:      fmt.Sprintf("failed to marshal args: %v", err),
-				ToolCallID:   call.ID,
-				ToolName:     name,
-				RawArguments: call.Function.Arguments,
-				Cause:        err,
-			}
-		}
-		if err := json.Unmarshal(argsJSON, &typedArgs); err != nil {
-			return nil, &ToolArgsError{
-				Message:      fmt.Sprintf("failed to parse args: %v", err),
-				ToolCallID:   call.ID,
-				ToolName:     name,
-				RawArguments: call.Function.Arguments,
-				Cause:        err,
-			}
-		}
-
-		// Validate if the type implements Validator
-		if v, ok := any(&typedArgs).(Validator); ok {
-			if err := v.Validate(); err != nil {
-				return nil, &ToolArgsError{
-					Message:      fmt.Sprintf("validation failed: %v", err),
-					ToolCallID:   call.ID,
-					ToolName:     name,
-					RawArguments: call.Function.Arguments,
-					Cause:        err,
-				}
-			}
-		}
-
-		return handler(typedArgs)
-	}
-
-	b.entries = append(b.entries, toolEntry{
-		name:        name,
-		description: description,
-		tool:        tool,
-		handler:     wrappedHandler,
+	return addTypedFunc(b, name, description, func(_ context.Context, args T, _ llm.ToolCall) (any, error) {
+		return handler(args)
 	})
-	return b
 }
 
 // AddFuncWithCall adds a tool with a typed handler that also receives the ToolCall.
@@ -144,6 +95,38 @@ func AddFunc[T any](b *ToolBuilder, name ToolName, description string, handler f
 //		return process(args), nil
 //	})
 func AddFuncWithCall[T any](b *ToolBuilder, name ToolName, description string, handler func(T, llm.ToolCall) (any, error)) *ToolBuilder {
+	return addTypedFunc(b, name, description, func(_ context.Context, args T, call llm.ToolCall) (any, error) {
+		return handler(args, call)
+	})
+}
+
+// AddFuncContext adds a tool with a typed handler that receives the caller's context.
+//
+// The context is the one passed to ToolRegistry.ExecuteContext (for example the
+// ctx given to Client.Agent), so handlers can stop in-flight work on cancellation.
+//
+// Example:
+//
+//	sdk.AddFuncContext(builder, "fetch", "Fetch a URL", func(ctx context.Context, args FetchArgs) (any, error) {
+//		req, err := http.NewRequestWithContext(ctx, http.MethodGet, args.URL, nil)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return doFetch(req)
+//	})
+func AddFuncContext[T any](b *ToolBuilder, name ToolName, description string, handler func(context.Context, T) (any, error)) *ToolBuilder {
+	return addTypedFunc(b, name, description, func(ctx context.Context, args T, _ llm.ToolCall) (any, error) {
+		return handler(ctx, args)
+	})
+}
+
+// AddFuncContextWithCall adds a tool with a typed handler that receives the
+// caller's context and the ToolCall.
+func AddFuncContextWithCall[T any](b *ToolBuilder, name ToolName, description string, handler func(context.Context, T, llm.ToolCall) (any, error)) *ToolBuilder {
+	return addTypedFunc(b, name, description, handler)
+}
+
+func addTypedFunc[T any](b *ToolBuilder, name ToolName, description string, handler func(context.Context, T, llm.ToolCall) (any, error)) *ToolBuilder {
 	tool, err := FunctionToolFromType[T](name, description)
 	if err != nil {
 		// This should rarely happen since we're generating from a valid Go type.
@@ -151,44 +134,13 @@ func AddFuncWithCall[T any](b *ToolBuilder, name ToolName, description string, h
 		panic(fmt.Sprintf("failed to create tool schema for %s: %v", name, err))
 	}
 
-	// Wrap the typed handler to match ToolHandler signature
-	wrappedHandler := func(args map[string]any, call llm.ToolCall) (any, error) {
-		// Parse and validate arguments into the typed struct
-		var typedArgs T
-		argsJSON, err := json.Marshal(args)
+	// Wrap the typed handler to match ToolHandlerContext signature
+	wrappedHandler := func(ctx context.Context, args map[string]any, call llm.ToolCall) (any, error) {
+		typedArgs, err := decodeTypedToolArgs[T](name, args, call)
 		if err != nil {
-			return nil, &ToolArgsError{
-				Message:      fmt.Sprintf("failed to marshal args: %v", err),
-				ToolCallID:   call.ID,
-				ToolName:     name,
-				RawArguments: call.Function.Arguments,
-				Cause:        err,
-			}
-		}
-		if err := json.Unmarshal(argsJSON, &typedArgs); err != nil {
-			return nil, &ToolArgsError{
-				Message:      fmt.Sprintf("failed to parse args: %v", err),
-				ToolCallID:   call.ID,
-				ToolName:     name,
-				RawArguments: call.Function.Arguments,
-				Cause:        err,
-			}
-		}
-
-		// Validate if the type implements Validator
-		if v, ok := any(&typedArgs).(Validator); ok {
-			if err := v.Validate(); err != nil {
-				return nil, &ToolArgsError{
-					Message:      fmt.Sprintf("validation failed: %v", err),
-					ToolCallID:   call.ID,
-					ToolName:     name,
-					RawArguments: call.Function.Arguments,
-					Cause:        err,
-				}
-			}
+			return nil, err
 		}
-
-		return handler(typedArgs, call)
+		return handler(ctx, typedArgs, call)
 	}
 
 	b.entries = append(b.entries, toolEntry{
@@ -200,6 +152,44 @@ func AddFuncWithCall[T any](b *ToolBuilder, name ToolName, description string, h
 	return b
 }
 
+// decodeTypedToolArgs parses and validates arguments into the typed struct.
+func decodeTypedToolArgs[T any](name ToolName, args map[string]any, call llm.ToolCall) (T, error) {
+	var typedArgs T
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return typedArgs, &ToolArgsError{
+			Message:      fmt.Sprintf("failed to marshal args: %v", err),
+			ToolCallID:   call.ID,
+			ToolName:     name,
+			RawArguments: call.Function.Arguments,
+			Cause:        err,
+		}
+	}
+	if err := json.Unmarshal(argsJSON, &typedArgs); err != nil {
+		return typedArgs, &ToolArgsError{
+			Message:      fmt.Sprintf("failed to parse args: %v", err),
+			ToolCallID:   call.ID,
+			ToolName:     name,
+			RawArguments: call.Function.Arguments,
+			Cause:        err,
+		}
+	}
+
+	// Validate if the type implements Validator
+	if v, ok := any(&typedArgs).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return typedArgs, &ToolArgsError{
+				Message:      fmt.Sprintf("validation failed: %v", err),
+				ToolCallID:   call.ID,
+				ToolName:     name,
+				RawArguments: call.Function.Arguments,
+				Cause:        err,
+			}
+		}
+	}
+	return typedArgs, nil
+}
+
 // Add adds a tool with a raw ToolHandler.
 //
 // Use this when you don't want automatic argument parsing, or when you
@@ -211,6 +201,24 @@ func AddFuncWithCall[T any](b *ToolBuilder, name ToolName, description string, h
 //		return args["message"], nil
 //	})
 func (b *ToolBuilder) Add(name ToolName, description string, schema json.RawMessage, handler ToolHandler) *ToolBuilder {
+	return b.AddContext(name, description, schema, func(_ context.Context, args map[string]any, call llm.ToolCall) (any, error) {
+		return handler(args, call)
+	})
+}
+
+// AddContext adds a tool with a raw ToolHandlerContext.
+//
+// Example:
+//
+//	builder.AddContext("sleep", "Sleep briefly", schema, func(ctx context.Context, args map[string]any, call llm.ToolCall) (any, error) {
+//		select {
+//		case <-time.After(time.Second):
+//			return "done", nil
+//		case <-ctx.Done():
+//			return nil, ctx.Err()
+//		}
+//	})
+func (b *ToolBuilder) AddContext(name ToolName, description string, schema json.RawMessage, handler ToolHandlerContext) *ToolBuilder {
 	b.entries = append(b.entries, toolEntry{
 		name:        name,
 		description: description,
@@ -227,6 +235,17 @@ func (b *ToolBuilder) Add(name ToolName, description string, schema json.RawMess
 	return b
 }
 
+// Serial marks tools that must not run concurrently with other tool calls
+// when the registry executes calls in parallel. See ToolRegistry.MarkSerial.
+//
+// Example:
+//
+//	builder.Serial("write_file", "delete_file")
+func (b *ToolBuilder) Serial(names ...ToolName) *ToolBuilder {
+	b.serial = append(b.serial, names...)
+	return b
+}
+
 // Definitions returns the tool definitions for use with ResponseBuilder.Tools().
 //
 // Example:
@@ -253,8 +272,9 @@ func (b *ToolBuilder) Definitions() []llm.Tool {
 func (b *ToolBuilder) Registry() *ToolRegistry {
 	reg := NewToolRegistry()
 	for _, e := range b.entries {
-		reg.Register(e.name, e.handler)
+		reg.RegisterContext(e.name, e.handler)
 	}
+	reg.MarkSerial(b.serial...)
 	return reg
 }
 
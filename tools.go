@@ -1,9 +1,13 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"runtime/debug"
 	"strings"
+	"sync"
 
 	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
 )
@@ -1014,6 +1018,11 @@ func ParseAndValidateToolArgs(call llm.ToolCall, target any) error {
 // Returns a result (any JSON-serializable value) or an error.
 type ToolHandler func(args map[string]any, call llm.ToolCall) (any, error)
 
+// ToolHandlerContext is a ToolHandler that also receives the caller's context.
+// Handlers should stop in-flight work (subprocesses, queries, network calls)
+// when ctx is canceled or its deadline expires.
+type ToolHandlerContext func(ctx context.Context, args map[string]any, call llm.ToolCall) (any, error)
+
 // ToolExecutionResult contains the result of executing a tool call.
 type ToolExecutionResult struct {
 	ToolCallID ToolCallID
@@ -1042,19 +1051,64 @@ type ToolExecutionResult struct {
 //	results := registry.ExecuteAll(response.ToolCalls)
 //	messages := registry.ResultsToMessages(results)
 type ToolRegistry struct {
-	handlers map[ToolName]ToolHandler
+	handlers       map[ToolName]ToolHandlerContext
+	serial         map[ToolName]struct{}
+	maxConcurrency int
 }
 
 // NewToolRegistry creates a new tool registry.
 func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{
-		handlers: make(map[ToolName]ToolHandler),
+		handlers: make(map[ToolName]ToolHandlerContext),
+		serial:   make(map[ToolName]struct{}),
 	}
 }
 
+// SetMaxConcurrency sets how many tool calls ExecuteAll/ExecuteAllContext may run
+// at the same time. Values <= 1 (the default) execute calls sequentially.
+// Returns the registry for method chaining.
+//
+// Handlers must be safe for concurrent use when concurrency is enabled.
+func (r *ToolRegistry) SetMaxConcurrency(n int) *ToolRegistry {
+	r.maxConcurrency = n
+	return r
+}
+
+// MaxConcurrency returns the configured concurrency limit for ExecuteAll.
+func (r *ToolRegistry) MaxConcurrency() int {
+	return r.maxConcurrency
+}
+
+// MarkSerial marks tools that must never run concurrently with other tool calls
+// (for example tools that mutate the filesystem). During parallel execution,
+// a serial call waits for all earlier calls to finish and runs alone.
+// Returns the registry for method chaining.
+func (r *ToolRegistry) MarkSerial(names ...ToolName) *ToolRegistry {
+	for _, name := range names {
+		r.serial[name] = struct{}{}
+	}
+	return r
+}
+
+// IsSerial returns true if the tool was marked with MarkSerial.
+func (r *ToolRegistry) IsSerial(name ToolName) bool {
+	_, ok := r.serial[name]
+	return ok
+}
+
 // Register adds a handler for the given tool name.
 // Returns the registry for method chaining.
 func (r *ToolRegistry) Register(name ToolName, handler ToolHandler) *ToolRegistry {
+	r.handlers[name] = func(_ context.Context, args map[string]any, call llm.ToolCall) (any, error) {
+		return handler(args, call)
+	}
+	return r
+}
+
+// RegisterContext adds a context-aware handler for the given tool name.
+// The context passed to ExecuteContext/ExecuteAllContext is forwarded to the handler.
+// Returns the registry for method chaining.
+func (r *ToolRegistry) RegisterContext(name ToolName, handler ToolHandlerContext) *ToolRegistry {
 	r.handlers[name] = handler
 	return r
 }
@@ -1064,6 +1118,7 @@ func (r *ToolRegistry) Register(name ToolName, handler ToolHandler) *ToolRegistr
 func (r *ToolRegistry) Unregister(name ToolName) bool {
 	if _, ok := r.handlers[name]; ok {
 		delete(r.handlers, name)
+		delete(r.serial, name)
 		return true
 	}
 	return false
@@ -1085,7 +1140,15 @@ func (r *ToolRegistry) RegisteredTools() []ToolName {
 }
 
 // Execute runs the handler for a single tool call.
+// It is equivalent to ExecuteContext with context.Background().
 func (r *ToolRegistry) Execute(call llm.ToolCall) ToolExecutionResult {
+	return r.ExecuteContext(context.Background(), call)
+}
+
+// ExecuteContext runs the handler for a single tool call, passing ctx to
+// context-aware handlers. If ctx is already done, the handler is not invoked
+// and the context error is returned in the result.
+func (r *ToolRegistry) ExecuteContext(ctx context.Context, call llm.ToolCall) ToolExecutionResult {
 	var toolName ToolName
 	if call.Function != nil {
 		toolName = call.Function.Name
@@ -1100,6 +1163,14 @@ func (r *ToolRegistry) Execute(call llm.ToolCall) ToolExecutionResult {
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return ToolExecutionResult{
+			ToolCallID: call.ID,
+			ToolName:   toolName,
+			Error:      err,
+		}
+	}
+
 	// Parse arguments
 	var args map[string]any
 	if call.Function != nil && call.Function.Arguments != "" {
@@ -1117,7 +1188,7 @@ func (r *ToolRegistry) Execute(call llm.ToolCall) ToolExecutionResult {
 	}
 
 	// Execute handler
-	result, err := handler(args, call)
+	result, err := invokeToolHandler(ctx, handler, toolName, args, call)
 	if err != nil {
 		// Check if error is a ToolArgsError (validation failure) - these are retryable
 		_, isArgsError := err.(*ToolArgsError)
@@ -1139,13 +1210,56 @@ func (r *ToolRegistry) Execute(call llm.ToolCall) ToolExecutionResult {
 // ExecuteAll runs handlers for multiple tool calls.
 // Results are returned in the same order as the input calls.
 func (r *ToolRegistry) ExecuteAll(calls []llm.ToolCall) []ToolExecutionResult {
+	return r.ExecuteAllContext(context.Background(), calls)
+}
+
+// ExecuteAllContext runs handlers for multiple tool calls with the given context.
+// Results are returned in the same order as the input calls. Once ctx is done,
+// remaining calls are not started and report the context error.
+//
+// When SetMaxConcurrency is > 1, up to that many calls run in parallel. Calls to
+// tools marked with MarkSerial wait for all earlier calls and run alone.
+func (r *ToolRegistry) ExecuteAllContext(ctx context.Context, calls []llm.ToolCall) []ToolExecutionResult {
 	results := make([]ToolExecutionResult, len(calls))
-	for i, call := range calls {
-		results[i] = r.Execute(call)
+	if r.maxConcurrency <= 1 || len(calls) <= 1 {
+		for i, call := range calls {
+			results[i] = r.ExecuteContext(ctx, call)
+		}
+		return results
 	}
+
+	sem := make(chan struct{}, r.maxConcurrency)
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		if r.IsSerial(GetToolName(call)) {
+			wg.Wait()
+			results[i] = r.ExecuteContext(ctx, call)
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, call llm.ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = r.ExecuteContext(ctx, call)
+		}(i, call)
+	}
+	wg.Wait()
 	return results
 }
 
+// invokeToolHandler calls handler, converting a panic into a ToolPanicError so a
+// single misbehaving tool cannot take down the caller (or sibling goroutines).
+func invokeToolHandler(ctx context.Context, handler ToolHandlerContext, name ToolName, args map[string]any, call llm.ToolCall) (result any, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			result = nil
+			err = &ToolPanicError{ToolName: name, Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return handler(ctx, args, call)
+}
+
 // ResultsToMessages converts execution results to tool result messages.
 // Useful for appending to the conversation history.
 func (r *ToolRegistry) ResultsToMessages(results []ToolExecutionResult) []llm.InputItem {
@@ -1185,6 +1299,17 @@ func (e *UnknownToolError) Error() string {
 	return "unknown tool: '" + e.ToolName.String() + "'. Available: " + joinToolNames(e.Available, ", ")
 }
 
+// ToolPanicError is returned when a tool handler panics during execution.
+type ToolPanicError struct {
+	ToolName ToolName
+	Value    any
+	Stack    []byte
+}
+
+func (e *ToolPanicError) Error() string {
+	return fmt.Sprintf("tool '%s' panicked: %v", e.ToolName.String(), e.Value)
+}
+
 func joinToolNames(s []ToolName, sep string) string {
 	if len(s) == 0 {
 		return ""
@@ -1260,7 +1385,8 @@ type RetryOptions struct {
 
 // ExecuteWithRetry executes tool calls with automatic retry on parse/validation errors.
 //
-// This is a higher-level utility that wraps registry.ExecuteAll with retry logic.
+// This is a higher-level utility that wraps registry.ExecuteAll with retry logic,
+// so calls run in parallel when the registry has SetMaxConcurrency > 1.
 // When a retryable error occurs, it calls the OnRetry callback to get new tool calls
 // from the model and continues execution.
 //
@@ -1,9 +1,12 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	llm "github.com/modelrelay/modelrelay/sdk/go/llm"
 )
@@ -166,6 +169,170 @@ func TestToolRegistry(t *testing.T) {
 			t.Error("expected IsRetryable to be true for ToolArgsError")
 		}
 	})
+
+	t.Run("ExecuteContext passes context to handler", func(t *testing.T) {
+		type ctxKey struct{}
+		registry := NewToolRegistry().
+			RegisterContext("ctx_tool", func(ctx context.Context, args map[string]any, call llm.ToolCall) (any, error) {
+				return ctx.Value(ctxKey{}), nil
+			})
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "from-ctx")
+		result := registry.ExecuteContext(ctx, llm.ToolCall{
+			ID:       "call_ctx",
+			Type:     "function",
+			Function: &llm.FunctionCall{Name: "ctx_tool", Arguments: "{}"},
+		})
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if result.Result != "from-ctx" {
+			t.Errorf("expected handler to see context value, got %v", result.Result)
+		}
+	})
+
+	t.Run("ExecuteAllContext skips handlers after cancellation", func(t *testing.T) {
+		calls := 0
+		registry := NewToolRegistry().
+			Register("counter", func(args map[string]any, call llm.ToolCall) (any, error) {
+				calls++
+				return calls, nil
+			})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := registry.ExecuteAllContext(ctx, []llm.ToolCall{
+			{ID: "a", Type: "function", Function: &llm.FunctionCall{Name: "counter"}},
+			{ID: "b", Type: "function", Function: &llm.FunctionCall{Name: "counter"}},
+		})
+		if calls != 0 {
+			t.Errorf("expected no handler invocations, got %d", calls)
+		}
+		for _, res := range results {
+			if !errors.Is(res.Error, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", res.Error)
+			}
+		}
+	})
+
+	t.Run("ToolBuilder AddFuncContext receives context", func(t *testing.T) {
+		type echoArgs struct {
+			Message string `json:"message"`
+		}
+		builder := NewToolBuilder()
+		AddFuncContext(builder, "echo", "Echo", func(ctx context.Context, args echoArgs) (any, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return args.Message, nil
+		})
+
+		result := builder.Registry().ExecuteContext(context.Background(), llm.ToolCall{
+			ID:       "call_echo",
+			Type:     "function",
+			Function: &llm.FunctionCall{Name: "echo", Arguments: `{"message":"hi"}`},
+		})
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if result.Result != "hi" {
+			t.Errorf("expected hi, got %v", result.Result)
+		}
+	})
+}
+
+func TestToolRegistryParallel(t *testing.T) {
+	t.Run("preserves input order and bounds concurrency", func(t *testing.T) {
+		var inFlight, peak int32
+		registry := NewToolRegistry().
+			SetMaxConcurrency(2).
+			Register("slow", func(args map[string]any, call llm.ToolCall) (any, error) {
+				n := atomic.AddInt32(&inFlight, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&inFlight, -1)
+				return string(call.ID), nil
+			})
+
+		var calls []llm.ToolCall
+		for _, id := range []ToolCallID{"a", "b", "c", "d", "e"} {
+			calls = append(calls, llm.ToolCall{ID: id, Type: "function", Function: &llm.FunctionCall{Name: "slow"}})
+		}
+		results := registry.ExecuteAll(calls)
+		for i, res := range results {
+			if res.ToolCallID != calls[i].ID || res.Result != string(calls[i].ID) {
+				t.Errorf("result %d out of order: %+v", i, res)
+			}
+		}
+		if peak > 2 {
+			t.Errorf("expected at most 2 concurrent calls, saw %d", peak)
+		}
+		if peak < 2 {
+			t.Errorf("expected calls to overlap, peak concurrency %d", peak)
+		}
+	})
+
+	t.Run("serial tools run alone", func(t *testing.T) {
+		var inFlight int32
+		var overlapped atomic.Bool
+		handler := func(args map[string]any, call llm.ToolCall) (any, error) {
+			if atomic.AddInt32(&inFlight, 1) > 1 && GetToolName(call) == "write" {
+				overlapped.Store(true)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if GetToolName(call) == "write" && atomic.LoadInt32(&inFlight) > 1 {
+				overlapped.Store(true)
+			}
+			atomic.AddInt32(&inFlight, -1)
+			return nil, nil
+		}
+		registry := NewToolRegistry().
+			SetMaxConcurrency(4).
+			Register("read", handler).
+			Register("write", handler).
+			MarkSerial("write")
+
+		registry.ExecuteAll([]llm.ToolCall{
+			{ID: "1", Type: "function", Function: &llm.FunctionCall{Name: "read"}},
+			{ID: "2", Type: "function", Function: &llm.FunctionCall{Name: "read"}},
+			{ID: "3", Type: "function", Function: &llm.FunctionCall{Name: "write"}},
+			{ID: "4", Type: "function", Function: &llm.FunctionCall{Name: "read"}},
+		})
+		if overlapped.Load() {
+			t.Error("expected serial tool to run without concurrent calls")
+		}
+	})
+
+	t.Run("recovers handler panics", func(t *testing.T) {
+		registry := NewToolRegistry().
+			SetMaxConcurrency(2).
+			Register("boom", func(args map[string]any, call llm.ToolCall) (any, error) {
+				panic("kaboom")
+			}).
+			Register("ok", func(args map[string]any, call llm.ToolCall) (any, error) {
+				return "fine", nil
+			})
+
+		results := registry.ExecuteAll([]llm.ToolCall{
+			{ID: "1", Type: "function", Function: &llm.FunctionCall{Name: "boom"}},
+			{ID: "2", Type: "function", Function: &llm.FunctionCall{Name: "ok"}},
+		})
+		var panicErr *ToolPanicError
+		if !errors.As(results[0].Error, &panicErr) {
+			t.Fatalf("expected ToolPanicError, got %v", results[0].Error)
+		}
+		if panicErr.Value != "kaboom" || len(panicErr.Stack) == 0 {
+			t.Errorf("unexpected panic error: %+v", panicErr)
+		}
+		if results[1].Error != nil || results[1].Result != "fine" {
+			t.Errorf("expected sibling call to succeed, got %+v", results[1])
+		}
+	})
 }
 
 func TestFormatToolErrorForModel(t *testing.T) {
@@ -0,0 +1,299 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCircuitBreaker enables a client-wide circuit breaker. Transport errors
+// and HTTP 429/5xx responses count as failures; while the breaker is open
+// requests fail fast with CircuitOpenError instead of reaching the API.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) Option {
+	return func(o *clientOptions) {
+		o.breaker = &cfg
+		o.breakerPerRoute = false
+	}
+}
+
+// WithRouteCircuitBreakers is like WithCircuitBreaker but keeps a separate
+// breaker per API route (the first path segment, e.g. "/responses" or "/runs"),
+// so an outage of one endpoint does not block the others.
+func WithRouteCircuitBreakers(cfg CircuitBreakerConfig) Option {
+	return func(o *clientOptions) {
+		o.breaker = &cfg
+		o.breakerPerRoute = true
+	}
+}
+
+// AdaptiveConcurrencyConfig bounds the adaptive in-flight request limiter.
+//
+// The limit grows by one for every limit successful responses and halves on
+// HTTP 429 or 503. A Retry-After header on those responses pauses new requests
+// until it has elapsed.
+type AdaptiveConcurrencyConfig struct {
+	// Initial is the starting limit. Default 16.
+	Initial int
+	// Min is the lowest the limit can drop to. Default 1.
+	Min int
+	// Max is the highest the limit can grow to. Default 256.
+	Max int
+}
+
+func (c AdaptiveConcurrencyConfig) normalized() AdaptiveConcurrencyConfig {
+	if c.Min <= 0 {
+		c.Min = 1
+	}
+	if c.Max <= 0 {
+		c.Max = 256
+	}
+	if c.Max < c.Min {
+		c.Max = c.Min
+	}
+	if c.Initial <= 0 {
+		c.Initial = 16
+	}
+	c.Initial = min(max(c.Initial, c.Min), c.Max)
+	return c
+}
+
+// WithAdaptiveConcurrency limits in-flight requests per client, adapting the
+// limit to 429/503 responses. A request holds its slot until the response
+// headers arrive (streaming bodies do not hold a slot).
+func WithAdaptiveConcurrency(cfg AdaptiveConcurrencyConfig) Option {
+	return func(o *clientOptions) { o.concurrency = &cfg }
+}
+
+// CircuitOpenError is returned when a circuit breaker rejects a request.
+type CircuitOpenError struct {
+	// Route is the breaker's route, or empty for the client-wide breaker.
+	Route string
+}
+
+func (e CircuitOpenError) Error() string {
+	if e.Route == "" {
+		return "sdk: circuit breaker open"
+	}
+	return "sdk: circuit breaker open for " + e.Route
+}
+
+// transportGuard applies the optional circuit breakers and concurrency limiter
+// around each HTTP attempt. A nil *transportGuard does nothing.
+type transportGuard struct {
+	telemetry TelemetryHooks
+	basePath  string
+
+	breakerCfg *CircuitBreakerConfig
+	perRoute   bool
+	mu         sync.Mutex
+	breakers   map[string]*circuitBreaker
+
+	limiter *adaptiveLimiter
+}
+
+func newTransportGuard(baseURL string, opts clientOptions) *transportGuard {
+	if (opts.breaker == nil || !opts.breaker.enabled()) && opts.concurrency == nil {
+		return nil
+	}
+	g := &transportGuard{telemetry: opts.telemetry, breakers: make(map[string]*circuitBreaker)}
+	if u, err := url.Parse(baseURL); err == nil {
+		g.basePath = strings.TrimSuffix(u.Path, "/")
+	}
+	if opts.breaker != nil && opts.breaker.enabled() {
+		cfg := *opts.breaker
+		g.breakerCfg = &cfg
+		g.perRoute = opts.breakerPerRoute
+	}
+	if opts.concurrency != nil {
+		g.limiter = newAdaptiveLimiter(opts.concurrency.normalized())
+	}
+	return g
+}
+
+func (g *transportGuard) route(path string) string {
+	if !g.perRoute {
+		return ""
+	}
+	rel := strings.TrimPrefix(path, g.basePath)
+	rel = strings.TrimPrefix(rel, "/")
+	if i := strings.IndexByte(rel, '/'); i >= 0 {
+		rel = rel[:i]
+	}
+	return "/" + rel
+}
+
+func (g *transportGuard) breaker(route string) *circuitBreaker {
+	if g.breakerCfg == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.breakers[route]
+	if !ok {
+		b = newCircuitBreaker(*g.breakerCfg)
+		g.breakers[route] = b
+	}
+	return b
+}
+
+// acquire admits one attempt. The returned release must be called with the
+// attempt's outcome unless acquire returned an error.
+func (g *transportGuard) acquire(ctx context.Context, path string) (func(*http.Response, error), error) {
+	if g == nil {
+		return func(*http.Response, error) {}, nil
+	}
+	route := g.route(path)
+	b := g.breaker(route)
+	before := b.currentState()
+	if !b.allow() {
+		g.telemetry.metric(ctx, "sdk_circuit_breaker_rejected", 1, map[string]string{"route": route})
+		return nil, CircuitOpenError{Route: route}
+	}
+	g.reportBreaker(ctx, route, before, b)
+
+	if g.limiter != nil {
+		start := time.Now()
+		if err := g.limiter.acquire(ctx); err != nil {
+			b.release()
+			return nil, err
+		}
+		if waited := time.Since(start); waited > time.Millisecond {
+			g.telemetry.metric(ctx, "sdk_concurrency_wait_ms", float64(waited.Milliseconds()), map[string]string{"route": route})
+		}
+		limit, inFlight := g.limiter.snapshot()
+		g.telemetry.metric(ctx, "sdk_concurrency_in_flight", float64(inFlight), nil)
+		g.telemetry.metric(ctx, "sdk_concurrency_limit", float64(limit), nil)
+	}
+
+	return func(resp *http.Response, err error) {
+		before := b.currentState()
+		switch {
+		case err != nil && (errors.Is(err, context.Canceled) || ctx.Err() != nil):
+			b.release()
+		case err != nil, resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500):
+			b.failure()
+		default:
+			b.success()
+		}
+		g.reportBreaker(ctx, route, before, b)
+
+		if g.limiter != nil {
+			prev, _ := g.limiter.snapshot()
+			g.limiter.release(resp, err)
+			if limit, _ := g.limiter.snapshot(); limit != prev {
+				g.telemetry.metric(ctx, "sdk_concurrency_limit", float64(limit), nil)
+			}
+		}
+	}, nil
+}
+
+var circuitStateValues = map[CircuitState]float64{CircuitClosed: 0, CircuitHalfOpen: 1, CircuitOpen: 2}
+
+func (g *transportGuard) reportBreaker(ctx context.Context, route string, before CircuitState, b *circuitBreaker) {
+	if b == nil {
+		return
+	}
+	if after := b.currentState(); after != before {
+		g.telemetry.metric(ctx, "sdk_circuit_breaker_state", circuitStateValues[after], map[string]string{"route": route, "state": string(after)})
+	}
+}
+
+// adaptiveLimiter is an AIMD in-flight request limiter.
+type adaptiveLimiter struct {
+	cfg AdaptiveConcurrencyConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	limit       float64
+	inFlight    int
+	pausedUntil time.Time
+	changed     chan struct{}
+}
+
+func newAdaptiveLimiter(cfg AdaptiveConcurrencyConfig) *adaptiveLimiter {
+	return &adaptiveLimiter{cfg: cfg, now: time.Now, limit: float64(cfg.Initial), changed: make(chan struct{})}
+}
+
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		wait := l.pausedUntil.Sub(l.now())
+		if wait <= 0 && l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		var timer *time.Timer
+		var fired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return ctx.Err()
+		case <-changed:
+		case <-fired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (l *adaptiveLimiter) release(resp *http.Response, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	switch {
+	case err != nil || resp == nil:
+		// Transport failures say nothing about server-side capacity.
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		l.limit = math.Max(float64(l.cfg.Min), math.Floor(l.limit/2))
+		if d := parseRetryAfter(resp.Header.Get("Retry-After"), l.now()); d > 0 {
+			if until := l.now().Add(d); until.After(l.pausedUntil) {
+				l.pausedUntil = until
+			}
+		}
+	default:
+		l.limit = math.Min(float64(l.cfg.Max), l.limit+1/l.limit)
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *adaptiveLimiter) snapshot() (limit int, inFlight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit), l.inFlight
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or HTTP-date form.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/responses" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"down","code":"SERVICE_UNAVAILABLE","message":"down"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"sessions":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var mu sync.Mutex
+	metrics := map[string]float64{}
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"),
+		WithBaseURL(srv.URL+"/api/v1"),
+		WithRetryConfig(RetryConfig{MaxAttempts: 1}),
+		WithRouteCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Hour}),
+		WithTelemetry(TelemetryHooks{OnMetric: func(_ context.Context, m Metric) {
+			mu.Lock()
+			metrics[m.Name+m.Labels["route"]] = m.Value
+			mu.Unlock()
+		}}),
+	)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+
+	req, opts, _ := client.Responses.New().Model(NewModelID("demo")).User("hi").Build()
+	for i := 0; i < 2; i++ {
+		var apiErr APIError
+		if _, err := client.Responses.Create(context.Background(), req, opts...); !errors.As(err, &apiErr) {
+			t.Fatalf("attempt %d: expected API error, got %v", i, err)
+		}
+	}
+	_, err = client.Responses.Create(context.Background(), req, opts...)
+	var openErr CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.Route != "/responses" {
+		t.Fatalf("expected open circuit for /responses, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected rejected call not to reach the server, got %d calls", calls.Load())
+	}
+
+	if _, err := client.Sessions.List(context.Background(), ListOptions{}); err != nil {
+		t.Fatalf("expected other routes unaffected, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if metrics["sdk_circuit_breaker_state/responses"] != 2 || metrics["sdk_circuit_breaker_rejected/responses"] != 1 {
+		t.Fatalf("unexpected metrics %v", metrics)
+	}
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newAdaptiveLimiter(AdaptiveConcurrencyConfig{Initial: 4, Min: 1, Max: 8}.normalized())
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if err := l.acquire(ctx); err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+	}
+	blocked, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(blocked); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected acquire to block at the limit, got %v", err)
+	}
+
+	throttled := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"2"}}}
+	l.release(throttled, nil)
+	if limit, inFlight := l.snapshot(); limit != 2 || inFlight != 3 {
+		t.Fatalf("expected limit halved to 2 with 3 in flight, got %d/%d", limit, inFlight)
+	}
+	if !l.pausedUntil.Equal(now.Add(2 * time.Second)) {
+		t.Fatalf("expected Retry-After pause, got %v", l.pausedUntil)
+	}
+
+	ok := &http.Response{StatusCode: http.StatusOK}
+	for i := 0; i < 3; i++ {
+		l.release(ok, nil)
+	}
+	if limit, inFlight := l.snapshot(); limit != 3 || inFlight != 0 {
+		t.Fatalf("expected limit to grow back to 3, got %d/%d", limit, inFlight)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d := parseRetryAfter("3", now); d != 3*time.Second {
+		t.Fatalf("seconds: got %v", d)
+	}
+	if d := parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now); d != time.Minute {
+		t.Fatalf("date: got %v", d)
+	}
+	if d := parseRetryAfter("soon", now); d != 0 {
+		t.Fatalf("invalid: got %v", d)
+	}
+}
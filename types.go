@@ -179,10 +179,39 @@ func (s *StreamHandle) CollectWithMetrics(ctx context.Context) (*Response, Respo
 type ResponseOption func(*responseCallOptions)
 
 type responseCallOptions struct {
-	headers http.Header
-	timeout *time.Duration
-	retry   *RetryConfig
-	stream  StreamTimeouts
+	headers      http.Header
+	timeout      *time.Duration
+	retry        *RetryConfig
+	stream       StreamTimeouts
+	conversation *responseConversation
+	router       *ModelRouter
+	cache        *responseCacheOptions
+}
+
+type responseConversation struct {
+	store ConversationStore
+	id    string
+}
+
+// WithModelRouter routes the call through router, which chooses the model and
+// provider (overriding the request's) and falls back across its candidates.
+func WithModelRouter(router *ModelRouter) ResponseOption {
+	return func(opts *responseCallOptions) {
+		opts.router = router
+	}
+}
+
+// WithConversation makes ResponsesClient.Create prepend the stored history of
+// conversation id to the request input and, on success, append the request's
+// non-system input and the assistant output to the store. It is not supported
+// by ResponsesClient.Stream.
+func WithConversation(store ConversationStore, id string) ResponseOption {
+	return func(opts *responseCallOptions) {
+		if store == nil {
+			return
+		}
+		opts.conversation = &responseConversation{store: store, id: id}
+	}
 }
 
 // WithRequestID sets the X-ModelRelay-Request-Id header for the request.
@@ -55,6 +55,8 @@ type Issue struct {
 	Code    string `json:"code"`
 	Path    string `json:"path"`
 	Message string `json:"message"`
+	// NodeID is the node the issue belongs to, when it is node-scoped.
+	NodeID NodeID `json:"node_id,omitempty"`
 }
 
 // ValidationError is returned by workflow compilation/validation endpoints.
@@ -0,0 +1,395 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+type workflowGraphOptions struct {
+	events []RunEvent
+}
+
+// WorkflowGraphOption configures WorkflowDOT and WorkflowMermaid rendering.
+type WorkflowGraphOption func(*workflowGraphOptions)
+
+// WithWorkflowGraphRun overlays node statuses and latencies taken from a
+// run's events, as returned by RunsClient.ListEvents. Nodes are colored by
+// their last status and labeled with the time between node_started and
+// node_succeeded or node_failed.
+func WithWorkflowGraphRun(events []RunEvent) WorkflowGraphOption {
+	return func(o *workflowGraphOptions) { o.events = events }
+}
+
+// WorkflowDOT renders an intent spec as a Graphviz DOT digraph showing node
+// types, dependencies, join predicates, fanout subnodes and outputs.
+//
+// Example:
+//
+//	events, _ := client.Runs.ListEvents(ctx, runID)
+//	dot := sdk.WorkflowDOT(spec, sdk.WithWorkflowGraphRun(events))
+func WorkflowDOT(spec WorkflowSpec, opts ...WorkflowGraphOption) string {
+	return workflowGraphFromIntent(spec).dot(buildWorkflowGraphOptions(opts))
+}
+
+// WorkflowDOTV1 renders a workflow.v1 spec as a Graphviz DOT digraph. Edge
+// conditions are shown as edge labels.
+func WorkflowDOTV1(spec workflow.SpecV1, opts ...WorkflowGraphOption) string {
+	return workflowGraphFromV1(spec).dot(buildWorkflowGraphOptions(opts))
+}
+
+// WorkflowMermaid renders an intent spec as a Mermaid flowchart.
+func WorkflowMermaid(spec WorkflowSpec, opts ...WorkflowGraphOption) string {
+	return workflowGraphFromIntent(spec).mermaid(buildWorkflowGraphOptions(opts))
+}
+
+// WorkflowMermaidV1 renders a workflow.v1 spec as a Mermaid flowchart.
+func WorkflowMermaidV1(spec workflow.SpecV1, opts ...WorkflowGraphOption) string {
+	return workflowGraphFromV1(spec).mermaid(buildWorkflowGraphOptions(opts))
+}
+
+func buildWorkflowGraphOptions(opts []WorkflowGraphOption) workflowGraphOptions {
+	var out workflowGraphOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&out)
+		}
+	}
+	return out
+}
+
+type workflowGraph struct {
+	name    string
+	nodes   []workflowGraphNode
+	edges   []workflowGraphEdge
+	outputs []workflowintent.OutputRef
+}
+
+type workflowGraphNode struct {
+	id     string
+	typ    string
+	detail string
+	sub    *workflowGraphNode
+}
+
+type workflowGraphEdge struct {
+	from  string
+	to    string
+	label string
+}
+
+func workflowGraphFromIntent(spec WorkflowSpec) workflowGraph {
+	g := workflowGraph{name: spec.Name, outputs: spec.Outputs}
+	for _, n := range spec.Nodes {
+		node := workflowGraphNode{id: n.ID, typ: string(n.Type)}
+		if p := n.Predicate; p != nil {
+			node.detail = "when " + workflowConditionLabel(string(p.Source), string(p.Op), p.Path, p.Value)
+		}
+		if n.SubNode != nil {
+			node.sub = &workflowGraphNode{id: n.SubNode.ID, typ: string(n.SubNode.Type)}
+		}
+		g.nodes = append(g.nodes, node)
+		for _, dep := range n.DependsOn {
+			g.edges = append(g.edges, workflowGraphEdge{from: dep, to: n.ID})
+		}
+		if n.ItemsFrom != "" && !containsString(n.DependsOn, n.ItemsFrom) {
+			g.edges = append(g.edges, workflowGraphEdge{from: n.ItemsFrom, to: n.ID, label: "items"})
+		}
+	}
+	return g
+}
+
+func workflowGraphFromV1(spec workflow.SpecV1) workflowGraph {
+	g := workflowGraph{name: spec.Name}
+	for _, n := range spec.Nodes {
+		node := workflowGraphNode{id: string(n.ID), typ: string(n.Type)}
+		switch n.Type {
+		case workflow.NodeTypeV1MapFanout:
+			var in workflow.MapFanoutNodeInputV1
+			if json.Unmarshal(n.Input, &in) == nil && in.SubNode.ID != "" {
+				node.sub = &workflowGraphNode{id: string(in.SubNode.ID), typ: string(in.SubNode.Type)}
+			}
+		case workflow.NodeTypeV1JoinAny, workflow.NodeTypeV1JoinCollect:
+			var in workflow.JoinCollectNodeInputV1
+			if json.Unmarshal(n.Input, &in) == nil && in.Predicate != nil {
+				p := in.Predicate
+				node.detail = "when " + workflowConditionLabel(string(p.Source), string(p.Op), string(p.Path), p.Value)
+			}
+		}
+		g.nodes = append(g.nodes, node)
+	}
+	for _, e := range spec.Edges {
+		edge := workflowGraphEdge{from: string(e.From), to: string(e.To)}
+		if c := e.When; c != nil {
+			edge.label = workflowConditionLabel(string(c.Source), string(c.Op), string(c.Path), c.Value)
+		}
+		g.edges = append(g.edges, edge)
+	}
+	for _, o := range spec.Outputs {
+		g.outputs = append(g.outputs, workflowintent.OutputRef{Name: string(o.Name), From: string(o.From), Pointer: string(o.Pointer)})
+	}
+	return g
+}
+
+// workflowConditionLabel renders a condition compactly, e.g.
+// `$.route == "billing"` or `status $ matches /fail/`.
+func workflowConditionLabel(source, op, path string, value json.RawMessage) string {
+	if path == "" {
+		path = "$"
+	}
+	if source == string(workflow.ConditionSourceNodeStatus) {
+		path = "status " + path
+	}
+	switch op {
+	case string(workflow.ConditionOpEquals):
+		return path + " == " + string(value)
+	case string(workflow.ConditionOpMatches):
+		var pattern string
+		if json.Unmarshal(value, &pattern) != nil {
+			pattern = string(value)
+		}
+		return path + " matches /" + pattern + "/"
+	case string(workflow.ConditionOpExists):
+		return path + " exists"
+	default:
+		return path + " " + op + " " + string(value)
+	}
+}
+
+// workflowNodeRun is the overlay state of one node.
+type workflowNodeRun struct {
+	status  NodeStatus
+	started time.Time
+	ended   time.Time
+}
+
+func (r workflowNodeRun) label() string {
+	if r.ended.IsZero() || r.started.IsZero() {
+		return string(r.status)
+	}
+	return string(r.status) + " · " + r.ended.Sub(r.started).Round(time.Millisecond).String()
+}
+
+func workflowGraphRuns(events []RunEvent) map[string]*workflowNodeRun {
+	if len(events) == 0 {
+		return nil
+	}
+	runs := make(map[string]*workflowNodeRun)
+	get := func(id NodeID) *workflowNodeRun {
+		r := runs[id.String()]
+		if r == nil {
+			r = &workflowNodeRun{}
+			runs[id.String()] = r
+		}
+		return r
+	}
+	for _, ev := range events {
+		switch e := ev.(type) {
+		case RunEventNodeStartedV0:
+			r := get(e.NodeID)
+			r.status, r.started = NodeStatusRunning, e.TS
+		case RunEventNodeWaitingV0:
+			get(e.NodeID).status = NodeStatusWaiting
+		case RunEventNodeUserAskV0:
+			get(e.NodeID).status = NodeStatusWaiting
+		case RunEventNodeSucceededV0:
+			r := get(e.NodeID)
+			r.status, r.ended = NodeStatusSucceeded, e.TS
+		case RunEventNodeFailedV0:
+			r := get(e.NodeID)
+			r.status, r.ended = NodeStatusFailed, e.TS
+		case RunEventRunCanceledV0:
+			for _, r := range runs {
+				if r.status == NodeStatusRunning || r.status == NodeStatusWaiting {
+					r.status, r.ended = NodeStatusCanceled, e.TS
+				}
+			}
+		}
+	}
+	return runs
+}
+
+var workflowGraphColors = map[NodeStatus]string{
+	NodeStatusSucceeded: "#d4edda",
+	NodeStatusFailed:    "#f8d7da",
+	NodeStatusRunning:   "#fff3cd",
+	NodeStatusWaiting:   "#cce5ff",
+	NodeStatusCanceled:  "#e2e3e5",
+}
+
+func (n workflowGraphNode) label(run *workflowNodeRun) []string {
+	lines := []string{n.id, n.typ}
+	if n.detail != "" {
+		lines = append(lines, n.detail)
+	}
+	if run != nil {
+		lines = append(lines, run.label())
+	}
+	return lines
+}
+
+func (g workflowGraph) dot(opts workflowGraphOptions) string {
+	runs := workflowGraphRuns(opts.events)
+	var b strings.Builder
+	b.WriteString("digraph " + dotQuote(g.name) + " {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	for _, n := range g.nodes {
+		attrs := []string{"label=" + dotQuote(strings.Join(n.label(runs[n.id]), "\n"))}
+		if shape := dotShape(n.typ); shape != "" {
+			attrs = append(attrs, "shape="+shape)
+		}
+		if run := runs[n.id]; run != nil {
+			attrs = append(attrs, `style="rounded,filled"`, "fillcolor="+dotQuote(workflowGraphColors[run.status]))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.id), strings.Join(attrs, ", "))
+		if n.sub != nil {
+			subID := n.id + "/" + n.sub.id
+			fmt.Fprintf(&b, "  subgraph %s {\n    style=dashed;\n    label=%s;\n    %s [label=%s];\n  }\n",
+				dotQuote("cluster_"+n.id), dotQuote("each item"), dotQuote(subID), dotQuote(n.sub.id+"\n"+n.sub.typ))
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed];\n", dotQuote(n.id), dotQuote(subID))
+		}
+	}
+	for _, e := range g.edges {
+		if e.label == "" {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e.from), dotQuote(e.to))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.from), dotQuote(e.to), dotQuote(e.label))
+		}
+	}
+	for _, o := range g.outputs {
+		id := "output:" + o.Name
+		fmt.Fprintf(&b, "  %s [label=%s, shape=ellipse, style=dashed];\n", dotQuote(id), dotQuote(o.Name))
+		if o.Pointer == "" {
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed];\n", dotQuote(o.From), dotQuote(id))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed, label=%s];\n", dotQuote(o.From), dotQuote(id), dotQuote(o.Pointer))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotShape(typ string) string {
+	switch {
+	case typ == string(workflow.NodeTypeV1RouteSwitch):
+		return "diamond"
+	case strings.HasPrefix(typ, "join."):
+		return "invtrapezium"
+	case typ == string(workflow.NodeTypeV1MapFanout):
+		return "trapezium"
+	default:
+		return ""
+	}
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func (g workflowGraph) mermaid(opts workflowGraphOptions) string {
+	runs := workflowGraphRuns(opts.events)
+	// Mermaid ids are restricted, so nodes get positional ids and keep their
+	// workflow id in the label.
+	ids := make(map[string]string, len(g.nodes))
+	for i, n := range g.nodes {
+		ids[n.id] = fmt.Sprintf("n%d", i)
+	}
+	ref := func(id string) string {
+		if mid, ok := ids[id]; ok {
+			return mid
+		}
+		mid := fmt.Sprintf("n%d", len(ids))
+		ids[id] = mid
+		return mid + mermaidShape("", []string{id})
+	}
+
+	var b strings.Builder
+	if g.name != "" {
+		b.WriteString("---\ntitle: " + g.name + "\n---\n")
+	}
+	b.WriteString("flowchart LR\n")
+	classes := make(map[NodeStatus][]string)
+	for _, n := range g.nodes {
+		id := ids[n.id]
+		fmt.Fprintf(&b, "  %s%s\n", id, mermaidShape(n.typ, n.label(runs[n.id])))
+		if run := runs[n.id]; run != nil {
+			classes[run.status] = append(classes[run.status], id)
+		}
+		if n.sub != nil {
+			fmt.Fprintf(&b, "  subgraph %s_each [each item]\n    %s_sub%s\n  end\n", id, id, mermaidShape(n.sub.typ, []string{n.sub.id, n.sub.typ}))
+			fmt.Fprintf(&b, "  %s -.-> %s_sub\n", id, id)
+		}
+	}
+	for _, e := range g.edges {
+		from, to := ref(e.from), ref(e.to)
+		if e.label == "" {
+			fmt.Fprintf(&b, "  %s --> %s\n", from, to)
+		} else {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", from, mermaidEscape(e.label), to)
+		}
+	}
+	for i, o := range g.outputs {
+		out := fmt.Sprintf("o%d", i)
+		fmt.Fprintf(&b, "  %s([%s])\n", out, mermaidQuote([]string{o.Name}))
+		if o.Pointer == "" {
+			fmt.Fprintf(&b, "  %s -.-> %s\n", ref(o.From), out)
+		} else {
+			fmt.Fprintf(&b, "  %s -.->|%s| %s\n", ref(o.From), mermaidEscape(o.Pointer), out)
+		}
+	}
+	statuses := make([]string, 0, len(classes))
+	for status := range classes {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", status, workflowGraphColors[NodeStatus(status)])
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(classes[NodeStatus(status)], ","), status)
+	}
+	return b.String()
+}
+
+func mermaidShape(typ string, lines []string) string {
+	label := mermaidQuote(lines)
+	switch {
+	case typ == string(workflow.NodeTypeV1RouteSwitch):
+		return "{" + label + "}"
+	case strings.HasPrefix(typ, "join."):
+		return "[\\" + label + "/]"
+	case typ == string(workflow.NodeTypeV1MapFanout):
+		return "[/" + label + "\\]"
+	default:
+		return "[" + label + "]"
+	}
+}
+
+func mermaidQuote(lines []string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = mermaidEscape(line)
+	}
+	return `"` + strings.Join(escaped, "<br/>") + `"`
+}
+
+func mermaidEscape(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "|", "#124;", "<", "#lt;", ">", "#gt;")
+	return r.Replace(s)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
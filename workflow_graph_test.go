@@ -0,0 +1,87 @@
+package sdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+func TestWorkflowDOTWithRunOverlay(t *testing.T) {
+	spec, err := Parallel([]workflowintent.Node{
+		LLM("cost", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("cost") }),
+		LLM("risk", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("risk") }),
+	}, ParallelOptions{Name: "review"}).OutputWithPointer("cost", "join", "/cost").Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := func(seq int64, offset time.Duration) RunEventBase {
+		return RunEventBase{Seq: seq, TS: start.Add(offset)}
+	}
+	events := []RunEvent{
+		RunEventNodeStartedV0{RunEventBase: base(1, 0), NodeID: "cost"},
+		RunEventNodeStartedV0{RunEventBase: base(2, 0), NodeID: "risk"},
+		RunEventNodeSucceededV0{RunEventBase: base(3, 1500*time.Millisecond), NodeID: "cost"},
+		RunEventNodeFailedV0{RunEventBase: base(4, 2*time.Second), NodeID: "risk"},
+	}
+
+	dot := WorkflowDOT(spec, WithWorkflowGraphRun(events))
+	for _, want := range []string{
+		`digraph "review" {`,
+		`"cost" [label="cost\nllm\nsucceeded · 1.5s", style="rounded,filled", fillcolor="#d4edda"];`,
+		`"risk" [label="risk\nllm\nfailed · 2s", style="rounded,filled", fillcolor="#f8d7da"];`,
+		`"join" [label="join\njoin.all", shape=invtrapezium];`,
+		`"cost" -> "join";`,
+		`"join" -> "output:cost" [style=dashed, label="/cost"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("missing %q in:\n%s", want, dot)
+		}
+	}
+}
+
+func TestWorkflowMermaidV1(t *testing.T) {
+	fanout, _ := json.Marshal(workflow.MapFanoutNodeInputV1{
+		Items:   workflow.MapFanoutItemsFromInput("tickets", ""),
+		SubNode: workflow.MapFanoutSubNodeV1{ID: "summarize", Type: workflow.NodeTypeV1LLMResponses},
+	})
+	spec := workflow.SpecV1{
+		Kind: workflow.KindV1,
+		Nodes: []workflow.NodeV1{
+			{ID: "route", Type: workflow.NodeTypeV1RouteSwitch},
+			{ID: "billing", Type: workflow.NodeTypeV1LLMResponses},
+			{ID: "tickets", Type: workflow.NodeTypeV1MapFanout, Input: fanout},
+		},
+		Edges: []workflow.EdgeV1{
+			{From: "route", To: "billing", When: &workflow.ConditionV1{
+				Source: workflow.ConditionSourceNodeOutput, Op: workflow.ConditionOpEquals, Path: "$.route", Value: json.RawMessage(`"billing"`),
+			}},
+			{From: "route", To: "tickets", When: &workflow.ConditionV1{
+				Source: workflow.ConditionSourceNodeStatus, Op: workflow.ConditionOpMatches, Value: json.RawMessage(`"succ"`),
+			}},
+		},
+		Outputs: []workflow.OutputRefV1{{Name: "answer", From: "billing"}},
+	}
+
+	got := WorkflowMermaidV1(spec)
+	want := `flowchart LR
+  n0{"route<br/>route.switch"}
+  n1["billing<br/>llm.responses"]
+  n2[/"tickets<br/>map.fanout"\]
+  subgraph n2_each [each item]
+    n2_sub["summarize<br/>llm.responses"]
+  end
+  n2 -.-> n2_sub
+  n0 -->|$.route == #quot;billing#quot;| n1
+  n0 -->|status $ matches /succ/| n2
+  o0(["answer"])
+  n1 -.-> o0
+`
+	if got != want {
+		t.Fatalf("unexpected mermaid:\n%s\nwant:\n%s", got, want)
+	}
+}
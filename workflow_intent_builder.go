@@ -1,10 +1,12 @@
 package sdk
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
 	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
 )
 
@@ -21,6 +23,7 @@ type WorkflowIntentBuilder struct {
 	inputs         []workflowintent.InputDecl
 	nodes          []workflowintent.Node
 	edges          []workflowIntentEdge
+	switches       []workflowSwitch
 	outputs        []workflowintent.OutputRef
 }
 
@@ -115,6 +118,41 @@ func (b WorkflowIntentBuilder) MapFanoutFromInput(id string, inputName string, i
 	})
 }
 
+// Switch adds a route.switch node: an LLM call whose output decides which
+// downstream nodes run. Each route becomes a conditional edge from the switch
+// to its target. Workflow intent specs cannot carry conditional edges, so a
+// workflow with switches must be built with BuildV1.
+//
+// Example:
+//
+//	spec, _ := sdk.Workflow().
+//	    Model("claude-sonnet-4-5").
+//	    Switch("triage", func(s sdk.SwitchNodeBuilder) sdk.SwitchNodeBuilder {
+//	        return s.System(`Reply with {"route": "billing"} or {"route": "support"}.`).
+//	            User("{{ticket}}").
+//	            Route("billing", sdk.WhenOutput("$.route").Equals("billing")).
+//	            Route("support", sdk.WhenOutput("$.route").Equals("support"))
+//	    }).
+//	    LLM("billing", func(n sdk.LLMNodeBuilder) sdk.LLMNodeBuilder { return n.User("Refund {{ticket}}") }).
+//	    LLM("support", func(n sdk.LLMNodeBuilder) sdk.LLMNodeBuilder { return n.User("Help with {{ticket}}") }).
+//	    JoinAny("answer").
+//	    Edge("billing", "answer").
+//	    Edge("support", "answer").
+//	    Output("answer", "answer").
+//	    BuildV1()
+func (b WorkflowIntentBuilder) Switch(id string, configure func(SwitchNodeBuilder) SwitchNodeBuilder) WorkflowIntentBuilder {
+	node := SwitchNodeBuilder{node: NewLLMNode(id).Build()}
+	if configure != nil {
+		node = configure(node)
+	}
+	b = b.Node(node.node)
+	next := make([]workflowSwitch, len(b.switches)+1)
+	copy(next, b.switches)
+	next[len(b.switches)] = workflowSwitch{id: node.node.ID, routes: node.routes}
+	b.switches = next
+	return b
+}
+
 func (b WorkflowIntentBuilder) Edge(from string, to string) WorkflowIntentBuilder {
 	b.edges = append(b.edges, workflowIntentEdge{
 		From: strings.TrimSpace(from),
@@ -137,6 +175,14 @@ func (b WorkflowIntentBuilder) OutputWithPointer(name string, from string, point
 }
 
 func (b WorkflowIntentBuilder) Build() (workflowintent.Spec, error) {
+	if len(b.switches) > 0 {
+		return workflowintent.Spec{}, fmt.Errorf("route.switch node %q needs conditional edges; use BuildV1", b.switches[0].id)
+	}
+	return b.spec()
+}
+
+// spec assembles the intent spec, wiring Edge calls into depends_on.
+func (b WorkflowIntentBuilder) spec() (workflowintent.Spec, error) {
 	spec := workflowintent.Spec{
 		Kind:    workflowintent.KindWorkflow,
 		Name:    strings.TrimSpace(b.name),
@@ -294,6 +340,99 @@ func (b LLMNodeBuilder) Build() workflowintent.Node {
 	return b.node
 }
 
+// SwitchNodeBuilder configures a route.switch node added with
+// WorkflowIntentBuilder.Switch.
+type SwitchNodeBuilder struct {
+	node   workflowintent.Node
+	routes []workflowSwitchRoute
+}
+
+func (b SwitchNodeBuilder) Model(model string) SwitchNodeBuilder {
+	b.node.Model = strings.TrimSpace(model)
+	return b
+}
+
+func (b SwitchNodeBuilder) System(text string) SwitchNodeBuilder {
+	b.node.System = text
+	return b
+}
+
+func (b SwitchNodeBuilder) User(text string) SwitchNodeBuilder {
+	b.node.User = text
+	return b
+}
+
+func (b SwitchNodeBuilder) Input(items []llm.InputItem) SwitchNodeBuilder {
+	b.node.Input = append([]llm.InputItem{}, items...)
+	return b
+}
+
+func (b SwitchNodeBuilder) MaxOutputTokens(tokens int64) SwitchNodeBuilder {
+	b.node.MaxOutputTokens = &tokens
+	return b
+}
+
+func (b SwitchNodeBuilder) OutputFormat(format llm.OutputFormat) SwitchNodeBuilder {
+	b.node.OutputFormat = &format
+	return b
+}
+
+// Route runs the node to when the condition holds for the switch. A node
+// whose routes are all untaken is skipped along with everything that
+// requires it.
+func (b SwitchNodeBuilder) Route(to string, when RouteCondition) SwitchNodeBuilder {
+	next := make([]workflowSwitchRoute, len(b.routes)+1)
+	copy(next, b.routes)
+	next[len(b.routes)] = workflowSwitchRoute{to: strings.TrimSpace(to), when: when}
+	b.routes = next
+	return b
+}
+
+// RouteCondition is a condition built by ConditionBuilder. An invalid
+// condition is reported by WorkflowIntentBuilder.BuildV1.
+type RouteCondition struct {
+	cond workflow.ConditionV1
+	err  error
+}
+
+// ConditionBuilder builds typed route conditions. Start with WhenOutput or
+// WhenStatus.
+type ConditionBuilder struct {
+	source workflow.ConditionSourceV1
+	path   workflow.JSONPath
+}
+
+// WhenOutput tests the switch node's output at a JSONPath such as "$.route".
+func WhenOutput(path string) ConditionBuilder {
+	return ConditionBuilder{source: workflow.ConditionSourceNodeOutput, path: workflow.JSONPath(strings.TrimSpace(path))}
+}
+
+// WhenStatus tests the switch node's status, e.g. "succeeded".
+func WhenStatus() ConditionBuilder {
+	return ConditionBuilder{source: workflow.ConditionSourceNodeStatus, path: "$"}
+}
+
+// Equals matches when the selected value equals value. If value cannot be
+// encoded as JSON, BuildV1 returns the encoding error.
+func (c ConditionBuilder) Equals(value any) RouteCondition {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return RouteCondition{err: fmt.Errorf("condition value: %w", err)}
+	}
+	return RouteCondition{cond: workflow.ConditionV1{Source: c.source, Op: workflow.ConditionOpEquals, Path: c.path, Value: raw}}
+}
+
+// Matches matches when the selected value matches the regular expression.
+func (c ConditionBuilder) Matches(pattern string) RouteCondition {
+	raw, _ := json.Marshal(pattern)
+	return RouteCondition{cond: workflow.ConditionV1{Source: c.source, Op: workflow.ConditionOpMatches, Path: c.path, Value: raw}}
+}
+
+// Exists matches when the selected value is present and not null.
+func (c ConditionBuilder) Exists() RouteCondition {
+	return RouteCondition{cond: workflow.ConditionV1{Source: c.source, Op: workflow.ConditionOpExists, Path: c.path}}
+}
+
 // Workflow is an alias for WorkflowIntent with a cleaner name.
 func Workflow() WorkflowIntentBuilder {
 	return WorkflowIntentBuilder{}
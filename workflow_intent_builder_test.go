@@ -0,0 +1,146 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+func routingSpec(route func(SwitchNodeBuilder) SwitchNodeBuilder) WorkflowIntentBuilder {
+	return Workflow().
+		Model("demo").
+		Switch("triage", func(s SwitchNodeBuilder) SwitchNodeBuilder {
+			return route(s.System(`Reply with {"route": "billing"} or {"route": "support"}.`).User("{{ticket}}"))
+		}).
+		LLM("billing", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("billing") }).
+		LLM("support", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("support") }).
+		JoinAny("answer").
+		Edge("billing", "answer").
+		Edge("support", "answer").
+		Output("answer", "answer")
+}
+
+func billingSupportRoutes(s SwitchNodeBuilder) SwitchNodeBuilder {
+	return s.Route("billing", WhenOutput("$.route").Equals("billing")).Route("support", WhenOutput("$.route").Equals("support"))
+}
+
+func TestWorkflowIntentBuilderSwitch(t *testing.T) {
+	if _, err := routingSpec(billingSupportRoutes).Build(); err == nil || !strings.Contains(err.Error(), "BuildV1") {
+		t.Fatalf("expected intent build to reject switches, got %v", err)
+	}
+
+	spec, err := routingSpec(billingSupportRoutes).BuildV1()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := ValidateWorkflowV1(spec); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if spec.Nodes[0].Type != workflow.NodeTypeV1RouteSwitch || spec.Nodes[1].Type != workflow.NodeTypeV1LLMResponses {
+		t.Fatalf("unexpected node types: %+v", spec.Nodes)
+	}
+	var in workflowLLMInputV1
+	if err := json.Unmarshal(spec.Nodes[0].Input, &in); err != nil || in.Request.Model != "demo" || len(in.Bindings) != 1 || in.Bindings[0].FromInput != "ticket" || in.Bindings[0].ToPlaceholder != "ticket" {
+		t.Fatalf("unexpected switch input: %s (%v)", spec.Nodes[0].Input, err)
+	}
+	raw, _ := json.Marshal(spec.Edges)
+	want := `[{"from":"billing","to":"answer"},{"from":"support","to":"answer"},` +
+		`{"from":"triage","to":"billing","when":{"source":"node_output","op":"equals","path":"$.route","value":"billing"}},` +
+		`{"from":"triage","to":"support","when":{"source":"node_output","op":"equals","path":"$.route","value":"support"}}]`
+	if string(raw) != want {
+		t.Fatalf("unexpected edges:\n%s", raw)
+	}
+	if c := WhenStatus().Matches("fail").cond; c.Source != workflow.ConditionSourceNodeStatus || c.Path != "$" || string(c.Value) != `"fail"` {
+		t.Fatalf("unexpected status condition: %+v", c)
+	}
+
+	for name, route := range map[string]func(SwitchNodeBuilder) SwitchNodeBuilder{
+		"no routes": func(s SwitchNodeBuilder) SwitchNodeBuilder { return s },
+		"unknown":   func(s SwitchNodeBuilder) SwitchNodeBuilder { return s.Route("nope", WhenOutput("$.x").Exists()) },
+		"self":      func(s SwitchNodeBuilder) SwitchNodeBuilder { return s.Route("triage", WhenOutput("$.x").Exists()) },
+		"dead end": func(s SwitchNodeBuilder) SwitchNodeBuilder {
+			return billingSupportRoutes(s).Route("orphan", WhenOutput("$.x").Exists())
+		},
+	} {
+		b := routingSpec(route)
+		if name == "dead end" {
+			b = b.LLM("orphan", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("orphan") })
+		}
+		if _, err := b.BuildV1(); err == nil {
+			t.Fatalf("%s: expected build error", name)
+		}
+	}
+	badValue := func(s SwitchNodeBuilder) SwitchNodeBuilder {
+		return s.Route("billing", WhenOutput("$.route").Equals(func() {})).Route("support", WhenOutput("$.route").Equals("support"))
+	}
+	if _, err := routingSpec(badValue).BuildV1(); err == nil || !strings.Contains(err.Error(), "condition value") {
+		t.Fatalf("expected condition value error, got %v", err)
+	}
+	if _, err := routingSpec(billingSupportRoutes).Model("").BuildV1(); err == nil || !strings.Contains(err.Error(), "model") {
+		t.Fatalf("expected missing model error, got %v", err)
+	}
+}
+
+func TestWorkflowIntentBuilderBuildV1LowersNodes(t *testing.T) {
+	spec, err := Workflow().
+		Model("demo").
+		Inputs([]workflowintent.InputDecl{{Name: "topic", Type: "string"}}).
+		LLM("ideas", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("List ideas about {{topic}}") }).
+		MapFanout("expand", "ideas", "/ideas", LLM("expand_one", func(n LLMNodeBuilder) LLMNodeBuilder { return n.User("Expand {{item}}") })).
+		TransformJSON("result", map[string]workflowintent.TransformValue{"all": {From: "expand"}}).
+		Output("result", "result").
+		BuildV1()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := ValidateWorkflowV1(spec); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	var fanout workflow.MapFanoutNodeInputV1
+	if err := json.Unmarshal(spec.Nodes[1].Input, &fanout); err != nil {
+		t.Fatalf("decode fanout: %v", err)
+	}
+	if fanout.Items.From != "ideas" || fanout.Items.Pointer != LLMTextOutputPointer || fanout.Items.Path != "/ideas" ||
+		len(fanout.ItemBindings) != 1 || fanout.ItemBindings[0].ToPlaceholder != "item" {
+		t.Fatalf("unexpected fanout input: %s", spec.Nodes[1].Input)
+	}
+	raw, _ := json.Marshal(spec.Edges)
+	if string(raw) != `[{"from":"ideas","to":"expand"},{"from":"expand","to":"result"}]` {
+		t.Fatalf("unexpected edges: %s", raw)
+	}
+}
+
+func TestLocalRunnerSwitchRoutes(t *testing.T) {
+	spec, err := routingSpec(billingSupportRoutes).BuildV1()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for ticket, want := range map[string]string{"refund": "billing", "login": "support"} {
+		responses := ResponsesCreatorFunc(func(_ context.Context, req ResponseRequest) (*Response, error) {
+			switch text := localLastUserText(req); text {
+			case "refund":
+				return localTextResponse(`{"route":"billing"}`), nil
+			case "login":
+				return localTextResponse(`{"route":"support"}`), nil
+			default:
+				return localTextResponse("handled " + text), nil
+			}
+		})
+		run, err := NewLocalRunner(responses).RunV1(context.Background(), spec, WithLocalRunInputs(map[string]any{"ticket": ticket}))
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if got := string(run.Outputs["answer"]); run.Status != RunStatusSucceeded || !strings.Contains(got, "handled "+want) {
+			t.Fatalf("%s: expected %s branch, got status %s output %s", ticket, want, run.Status, got)
+		}
+	}
+
+	dot := WorkflowDOTV1(spec)
+	if !strings.Contains(dot, `"triage" -> "billing" [label="$.route == \"billing\""];`) {
+		t.Fatalf("unexpected dot:\n%s", dot)
+	}
+}
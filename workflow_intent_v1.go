@@ -0,0 +1,329 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelrelay/modelrelay/sdk/go/llm"
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+// workflowSwitch is a route.switch node added with Switch. Its node fields
+// live in the builder's node list; its routes become conditional edges.
+type workflowSwitch struct {
+	id     string
+	routes []workflowSwitchRoute
+}
+
+type workflowSwitchRoute struct {
+	to   string
+	when RouteCondition
+}
+
+// workflowLLMInputV1 is the input of llm.responses and route.switch nodes.
+type workflowLLMInputV1 struct {
+	Request       responseRequestPayload           `json:"request"`
+	Bindings      []workflow.LLMResponsesBindingV1 `json:"bindings,omitempty"`
+	Stream        *bool                            `json:"stream,omitempty"`
+	ToolExecution *workflow.ToolExecutionV1        `json:"tool_execution,omitempty"`
+	Retry         *workflow.RetryConfigV1          `json:"retry,omitempty"`
+}
+
+// BuildV1 builds the workflow as a workflow.v1 spec, the format that carries
+// route.switch nodes and conditional edges. Send the result with
+// RunsClient.CreateV1.
+//
+// Intent nodes are lowered to their workflow.v1 equivalents: depends_on and
+// node references become edges, {{placeholders}} become bindings from
+// upstream nodes or run inputs, and {{item}} in a map.fanout subnode binds
+// the current item. Every llm and switch node needs a model, set on the node
+// or with Model. BuildV1 also checks that every route reaches a declared
+// output.
+func (b WorkflowIntentBuilder) BuildV1() (workflow.SpecV1, error) {
+	spec, err := b.spec()
+	if err != nil {
+		return workflow.SpecV1{}, err
+	}
+	l := workflowLowering{spec: spec, types: make(map[string]workflowintent.NodeType, len(spec.Nodes)), switches: make(map[string]bool)}
+	for _, n := range spec.Nodes {
+		l.types[n.ID] = n.Type
+	}
+	// routed maps a route target to the switches that route to it.
+	routed := make(map[string][]string)
+	for _, sw := range b.switches {
+		l.switches[sw.id] = true
+		if len(sw.routes) == 0 {
+			return workflow.SpecV1{}, fmt.Errorf("route.switch node %q needs at least one route", sw.id)
+		}
+		for _, r := range sw.routes {
+			switch {
+			case r.to == sw.id:
+				return workflow.SpecV1{}, fmt.Errorf("route.switch node %q cannot route to itself", sw.id)
+			case l.types[r.to] == "":
+				return workflow.SpecV1{}, fmt.Errorf("route from %q to unknown node %q", sw.id, r.to)
+			case r.when.err != nil:
+				return workflow.SpecV1{}, fmt.Errorf("route from %q to %q: %w", sw.id, r.to, r.when.err)
+			case !r.when.cond.Source.Valid() || !r.when.cond.Op.Valid():
+				return workflow.SpecV1{}, fmt.Errorf("route from %q to %q has an invalid condition", sw.id, r.to)
+			case containsString(routed[r.to], sw.id):
+				return workflow.SpecV1{}, fmt.Errorf("route.switch node %q routes to %q more than once", sw.id, r.to)
+			}
+			routed[r.to] = append(routed[r.to], sw.id)
+		}
+	}
+
+	out := workflow.SpecV1{Kind: workflow.KindV1, Name: spec.Name}
+	if spec.MaxParallelism != nil {
+		out.Execution = &workflow.ExecutionV1{MaxParallelism: spec.MaxParallelism}
+	}
+	for _, in := range spec.Inputs {
+		out.Inputs = append(out.Inputs, workflow.InputDeclV1{
+			Name:        workflow.InputName(in.Name),
+			Type:        in.Type,
+			Required:    in.Required,
+			Description: in.Description,
+			Default:     in.Default,
+		})
+	}
+	for _, n := range spec.Nodes {
+		node, refs, err := l.node(n, false)
+		if err != nil {
+			return workflow.SpecV1{}, err
+		}
+		out.Nodes = append(out.Nodes, node)
+		for _, from := range append(append([]string{}, n.DependsOn...), refs...) {
+			if containsString(routed[n.ID], from) || workflowHasEdge(out.Edges, from, n.ID) {
+				continue
+			}
+			out.Edges = append(out.Edges, workflow.EdgeV1{From: workflow.NodeID(from), To: workflow.NodeID(n.ID)})
+		}
+	}
+	for _, sw := range b.switches {
+		for _, r := range sw.routes {
+			when := r.when.cond
+			out.Edges = append(out.Edges, workflow.EdgeV1{From: workflow.NodeID(sw.id), To: workflow.NodeID(r.to), When: &when})
+		}
+	}
+	for _, o := range spec.Outputs {
+		out.Outputs = append(out.Outputs, workflow.OutputRefV1{
+			Name:    workflow.OutputName(o.Name),
+			From:    workflow.NodeID(o.From),
+			Pointer: workflow.JSONPointer(o.Pointer),
+		})
+	}
+
+	reach := workflowV1OutputAncestors(out)
+	for _, sw := range b.switches {
+		for _, r := range sw.routes {
+			if !reach[r.to] {
+				return workflow.SpecV1{}, fmt.Errorf("route from %q to %q does not reach a declared output", sw.id, r.to)
+			}
+		}
+	}
+	return out, nil
+}
+
+// workflowLowering converts intent nodes to workflow.v1 nodes.
+type workflowLowering struct {
+	spec     workflowintent.Spec
+	types    map[string]workflowintent.NodeType
+	switches map[string]bool
+}
+
+// node lowers n and returns the upstream nodes its input references.
+func (l workflowLowering) node(n workflowintent.Node, subnode bool) (workflow.NodeV1, []string, error) {
+	node := workflow.NodeV1{ID: workflow.NodeID(n.ID), Type: workflow.NodeTypeV1(n.Type)}
+	var input any
+	var refs []string
+	switch n.Type {
+	case workflowintent.NodeTypeLLM:
+		node.Type = workflow.NodeTypeV1LLMResponses
+		if l.switches[n.ID] {
+			node.Type = workflow.NodeTypeV1RouteSwitch
+		}
+		in, err := l.llmInput(n, subnode)
+		if err != nil {
+			return workflow.NodeV1{}, nil, err
+		}
+		for _, b := range in.Bindings {
+			if b.From != "" {
+				refs = append(refs, string(b.From))
+			}
+		}
+		input = in
+	case workflowintent.NodeTypeJoinAll:
+	case workflowintent.NodeTypeJoinAny:
+		if n.Predicate != nil {
+			input = workflow.JoinAnyNodeInputV1{Predicate: workflowConditionV1(n.Predicate)}
+		}
+	case workflowintent.NodeTypeJoinCollect:
+		input = workflow.JoinCollectNodeInputV1{Predicate: workflowConditionV1(n.Predicate), Limit: n.Limit, TimeoutMS: n.TimeoutMS}
+	case workflowintent.NodeTypeTransformJSON:
+		in := workflowV1NodeInput{}
+		if len(n.Object) > 0 {
+			in.Object = make(map[string]workflowV1RefInput, len(n.Object))
+			for key, v := range n.Object {
+				in.Object[key] = workflowV1RefInput{From: NodeID(v.From), Pointer: JSONPointer(v.Pointer)}
+				refs = appendUnique(refs, v.From)
+			}
+		}
+		for _, v := range n.Merge {
+			in.Merge = append(in.Merge, workflowV1RefInput{From: NodeID(v.From), Pointer: JSONPointer(v.Pointer)})
+			refs = appendUnique(refs, v.From)
+		}
+		input = in
+	case workflowintent.NodeTypeMapFanout:
+		if n.SubNode == nil {
+			return workflow.NodeV1{}, nil, fmt.Errorf("map.fanout node %q needs a subnode", n.ID)
+		}
+		sub, subRefs, err := l.node(*n.SubNode, true)
+		if err != nil {
+			return workflow.NodeV1{}, nil, err
+		}
+		in := workflow.MapFanoutNodeInputV1{
+			Items: workflow.MapFanoutItemsV1{
+				From:      workflow.NodeID(n.ItemsFrom),
+				FromInput: workflow.InputName(n.ItemsFromInput),
+				Pointer:   workflow.JSONPointer(n.ItemsPointer),
+				Path:      workflow.JSONPointer(n.ItemsPath),
+			},
+			SubNode:        workflow.MapFanoutSubNodeV1{ID: sub.ID, Type: sub.Type, Input: sub.Input},
+			MaxParallelism: n.MaxParallelism,
+		}
+		if in.Items.From != "" && in.Items.Pointer == "" && l.types[n.ItemsFrom] == workflowintent.NodeTypeLLM {
+			in.Items.Pointer = LLMTextOutputPointer
+		}
+		if containsString(workflowIntentPlaceholders(*n.SubNode), "item") {
+			in.ItemBindings = []workflow.MapFanoutItemBindingV1{{ToPlaceholder: "item"}}
+		}
+		if n.ItemsFrom != "" {
+			refs = append(refs, n.ItemsFrom)
+		}
+		refs = append(refs, subRefs...)
+		input = in
+	default:
+		return workflow.NodeV1{}, nil, fmt.Errorf("node %q has unsupported type %q", n.ID, n.Type)
+	}
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return workflow.NodeV1{}, nil, fmt.Errorf("node %q: %w", n.ID, err)
+		}
+		node.Input = raw
+	}
+	return node, refs, nil
+}
+
+func (l workflowLowering) llmInput(n workflowintent.Node, subnode bool) (workflowLLMInputV1, error) {
+	model := n.Model
+	if model == "" {
+		model = l.spec.Model
+	}
+	if model == "" {
+		return workflowLLMInputV1{}, fmt.Errorf("node %q needs a model", n.ID)
+	}
+	in := workflowLLMInputV1{
+		Request: responseRequestPayload{Model: model, Input: n.Input, OutputFormat: n.OutputFormat, Stop: n.Stop},
+		Stream:  n.Stream,
+	}
+	if len(in.Request.Input) == 0 {
+		if n.System != "" {
+			in.Request.Input = append(in.Request.Input, llm.NewSystemText(n.System))
+		}
+		in.Request.Input = append(in.Request.Input, llm.NewUserText(n.User))
+	}
+	if n.MaxOutputTokens != nil {
+		in.Request.MaxOutputTokens = *n.MaxOutputTokens
+	}
+	for _, ref := range n.Tools {
+		in.Request.Tools = append(in.Request.Tools, ref.Tool)
+	}
+	if n.ToolExecution != nil {
+		in.ToolExecution = &workflow.ToolExecutionV1{Mode: workflow.ToolExecutionModeV1(n.ToolExecution.Mode)}
+	}
+	if r := n.Retry; r != nil {
+		in.Retry = &workflow.RetryConfigV1{MaxAttempts: r.MaxAttempts, RetryableErrors: r.RetryableErrors, BackoffMS: r.BackoffMS}
+	}
+	for _, name := range workflowIntentPlaceholders(n) {
+		binding := workflow.LLMResponsesBindingV1{ToPlaceholder: workflow.PlaceholderName(name)}
+		switch typ, ok := l.types[name]; {
+		case ok:
+			binding.From = workflow.NodeID(name)
+			if typ == workflowintent.NodeTypeLLM {
+				binding.Pointer = LLMTextOutputPointer
+			}
+		case subnode && name == "item":
+			continue
+		default:
+			binding.FromInput = workflow.InputName(name)
+		}
+		in.Bindings = append(in.Bindings, binding)
+	}
+	return in, nil
+}
+
+// workflowIntentPlaceholders returns the {{placeholder}} names used by the
+// prompts of n, in order of first use.
+func workflowIntentPlaceholders(n workflowintent.Node) []string {
+	texts := []string{n.System, n.User}
+	for _, item := range n.Input {
+		for _, part := range item.Content {
+			if part.Type == llm.ContentPartTypeText {
+				texts = append(texts, part.Text)
+			}
+		}
+	}
+	var names []string
+	for _, text := range texts {
+		for _, m := range workflowPlaceholderRe.FindAllStringSubmatch(text, -1) {
+			names = appendUnique(names, strings.TrimSpace(m[1]))
+		}
+	}
+	return names
+}
+
+func workflowConditionV1(c *workflowintent.Condition) *workflow.ConditionV1 {
+	if c == nil {
+		return nil
+	}
+	return &workflow.ConditionV1{
+		Source: workflow.ConditionSourceV1(c.Source),
+		Op:     workflow.ConditionOpV1(c.Op),
+		Path:   workflow.JSONPath(c.Path),
+		Value:  c.Value,
+	}
+}
+
+func workflowHasEdge(edges []workflow.EdgeV1, from, to string) bool {
+	for _, e := range edges {
+		if string(e.From) == from && string(e.To) == to {
+			return true
+		}
+	}
+	return false
+}
+
+// workflowV1OutputAncestors returns the nodes that feed a declared output.
+func workflowV1OutputAncestors(spec workflow.SpecV1) map[string]bool {
+	upstream := make(map[string][]string, len(spec.Nodes))
+	for _, e := range spec.Edges {
+		upstream[string(e.To)] = append(upstream[string(e.To)], string(e.From))
+	}
+	reach := make(map[string]bool, len(spec.Nodes))
+	var visit func(id string)
+	visit = func(id string) {
+		if reach[id] {
+			return
+		}
+		reach[id] = true
+		for _, from := range upstream[id] {
+			visit(from)
+		}
+	}
+	for _, o := range spec.Outputs {
+		visit(string(o.From))
+	}
+	return reach
+}
@@ -0,0 +1,613 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+// ValidateWorkflow checks a workflow spec locally, without calling the API.
+// It reports unknown node references, dependency cycles, outputs that can
+// never be produced, malformed JSON pointers (including join.all member
+// pointers built with JoinOutput), invalid conditions, map.fanout bindings,
+// prompt placeholders and input declarations.
+//
+// It returns nil or a WorkflowValidationError whose issues carry the offending
+// node ID. Passing ValidateWorkflow does not guarantee that Compile succeeds:
+// the server additionally resolves models, tools and provider limits.
+func ValidateWorkflow(spec WorkflowSpec) error {
+	v := newWorkflowValidator()
+	if !spec.Kind.Valid() {
+		v.add("invalid_kind", "$.kind", "", fmt.Sprintf("unsupported kind %q", spec.Kind))
+	}
+	decls := make([]workflowInputDecl, 0, len(spec.Inputs))
+	for _, in := range spec.Inputs {
+		decls = append(decls, workflowInputDecl{Name: in.Name, Type: in.Type, Default: in.Default})
+	}
+	v.inputs(decls)
+
+	if len(spec.Nodes) == 0 {
+		v.add("missing_nodes", "$.nodes", "", "at least one node is required")
+	}
+	for i, n := range spec.Nodes {
+		v.node(fmt.Sprintf("$.nodes[%d]", i), n.ID, n.Type.Valid(), string(n.Type), n.Type == workflowintent.NodeTypeJoinAll)
+	}
+	for i, n := range spec.Nodes {
+		if v.ids[n.ID] {
+			for j, dep := range n.DependsOn {
+				v.dependency(fmt.Sprintf("$.nodes[%d].depends_on[%d]", i, j), n.ID, dep)
+			}
+		}
+	}
+	for i, n := range spec.Nodes {
+		v.intentNode(fmt.Sprintf("$.nodes[%d]", i), n)
+	}
+
+	for i, o := range spec.Outputs {
+		v.output(fmt.Sprintf("$.outputs[%d]", i), o.Name, o.From, o.Pointer)
+	}
+	if len(spec.Outputs) == 0 {
+		v.add("missing_outputs", "$.outputs", "", "at least one output is required")
+	}
+	v.graph()
+	return v.err()
+}
+
+// ValidateWorkflowV1 is ValidateWorkflow for workflow.v1 specs. Edges with a
+// when condition must leave a route.switch node.
+func ValidateWorkflowV1(spec workflow.SpecV1) error {
+	v := newWorkflowValidator()
+	if spec.Kind != workflow.KindV1 {
+		v.add("invalid_kind", "$.kind", "", fmt.Sprintf("unsupported kind %q", spec.Kind))
+	}
+	decls := make([]workflowInputDecl, 0, len(spec.Inputs))
+	for _, in := range spec.Inputs {
+		decls = append(decls, workflowInputDecl{Name: string(in.Name), Type: in.Type, Default: in.Default})
+	}
+	v.inputs(decls)
+
+	if len(spec.Nodes) == 0 {
+		v.add("missing_nodes", "$.nodes", "", "at least one node is required")
+	}
+	types := make(map[NodeID]workflow.NodeTypeV1, len(spec.Nodes))
+	for i, n := range spec.Nodes {
+		v.node(fmt.Sprintf("$.nodes[%d]", i), string(n.ID), n.Type.Valid(), string(n.Type), n.Type == workflow.NodeTypeV1JoinAll)
+		types[n.ID] = n.Type
+	}
+	for i, e := range spec.Edges {
+		path := fmt.Sprintf("$.edges[%d]", i)
+		from, to := string(e.From), string(e.To)
+		switch {
+		case !v.ids[from]:
+			v.add("unknown_edge_source", path+".from", "", "edge from unknown node "+quoteID(from))
+		case !v.ids[to]:
+			v.add("unknown_edge_target", path+".to", from, "edge to unknown node "+quoteID(to))
+		default:
+			v.dependency(path, to, from)
+		}
+		if e.When != nil {
+			if types[e.From] != workflow.NodeTypeV1RouteSwitch {
+				v.add("condition_requires_route_switch", path+".when", from, "conditional edges must leave a route.switch node")
+			}
+			v.condition(path+".when", from, e.When.Source.Valid(), e.When.Op.Valid(), string(e.When.Source), string(e.When.Op), string(e.When.Path), e.When.Value)
+		}
+	}
+	for i, n := range spec.Nodes {
+		v.v1Node(fmt.Sprintf("$.nodes[%d]", i), n)
+	}
+
+	for i, o := range spec.Outputs {
+		v.output(fmt.Sprintf("$.outputs[%d]", i), string(o.Name), string(o.From), string(o.Pointer))
+	}
+	if len(spec.Outputs) == 0 {
+		v.add("missing_outputs", "$.outputs", "", "at least one output is required")
+	}
+	v.graph()
+	return v.err()
+}
+
+var workflowPlaceholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+type workflowInputDecl struct {
+	Name    string
+	Type    string
+	Default json.RawMessage
+}
+
+type workflowOutputSource struct {
+	path string
+	from string
+}
+
+// workflowValidator accumulates issues for one spec. deps maps a node ID to
+// its upstream node IDs; declared is nil when the spec declares no inputs.
+type workflowValidator struct {
+	issues   []workflow.Issue
+	ids      map[string]bool
+	order    []string
+	deps     map[string][]string
+	joins    map[string]bool
+	declared map[string]bool
+	outputs  map[string]bool
+	sources  []workflowOutputSource
+}
+
+func newWorkflowValidator() *workflowValidator {
+	return &workflowValidator{
+		ids:     make(map[string]bool),
+		deps:    make(map[string][]string),
+		joins:   make(map[string]bool),
+		outputs: make(map[string]bool),
+	}
+}
+
+func (v *workflowValidator) add(code, path, nodeID, msg string) {
+	if nodeID != "" {
+		msg = "node " + quoteID(nodeID) + ": " + msg
+	}
+	v.issues = append(v.issues, workflow.Issue{Code: code, Path: path, Message: msg, NodeID: workflow.NodeID(nodeID)})
+}
+
+func (v *workflowValidator) err() error {
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return WorkflowValidationError{Issues: v.issues}
+}
+
+func (v *workflowValidator) inputs(decls []workflowInputDecl) {
+	if len(decls) == 0 {
+		return
+	}
+	v.declared = make(map[string]bool, len(decls))
+	for i, d := range decls {
+		path := fmt.Sprintf("$.inputs[%d]", i)
+		name := strings.TrimSpace(d.Name)
+		switch {
+		case name == "":
+			v.add("missing_input_name", path+".name", "", "input name is required")
+		case v.declared[name]:
+			v.add("duplicate_input", path+".name", "", "duplicate input "+quoteID(name))
+		}
+		v.declared[name] = true
+		if d.Type != "" && !validWorkflowInputType(d.Type) {
+			v.add("invalid_input_type", path+".type", "", fmt.Sprintf("unsupported input type %q", d.Type))
+			continue
+		}
+		if len(d.Default) == 0 {
+			continue
+		}
+		var def any
+		if err := json.Unmarshal(d.Default, &def); err != nil {
+			v.add("invalid_input_default", path+".default", "", "default is not valid JSON: "+err.Error())
+		} else if !workflowInputDefaultMatches(d.Type, def) {
+			v.add("invalid_input_default", path+".default", "", fmt.Sprintf("default does not match type %q", d.Type))
+		}
+	}
+}
+
+func validWorkflowInputType(t string) bool {
+	switch t {
+	case "string", "number", "object", "array", "boolean", "null", "json":
+		return true
+	default:
+		return false
+	}
+}
+
+func workflowInputDefaultMatches(t string, val any) bool {
+	var ok bool
+	switch t {
+	case "", "json":
+		ok = true
+	case "string":
+		_, ok = val.(string)
+	case "number":
+		_, ok = val.(float64)
+	case "object":
+		_, ok = val.(map[string]any)
+	case "array":
+		_, ok = val.([]any)
+	case "boolean":
+		_, ok = val.(bool)
+	case "null":
+		ok = val == nil
+	}
+	return ok
+}
+
+func (v *workflowValidator) node(path, id string, typeValid bool, typ string, join bool) {
+	switch {
+	case strings.TrimSpace(id) == "":
+		v.add("missing_node_id", path+".id", "", "node id is required")
+		return
+	case v.ids[id]:
+		v.add("duplicate_node_id", path+".id", id, "duplicate node id")
+		return
+	}
+	v.ids[id] = true
+	v.order = append(v.order, id)
+	v.joins[id] = join
+	if !typeValid {
+		v.add("invalid_node_type", path+".type", id, fmt.Sprintf("unsupported node type %q", typ))
+	}
+}
+
+// dependency records that node runs after dep.
+func (v *workflowValidator) dependency(path, node, dep string) {
+	switch {
+	case dep == node:
+		v.add("self_dependency", path, node, "node cannot depend on itself")
+	case !v.ids[dep]:
+		v.add("unknown_dependency", path, node, "unknown node "+quoteID(dep))
+	default:
+		v.deps[node] = append(v.deps[node], dep)
+	}
+}
+
+// ref checks a reference to another node's output. Pointers into a join.all
+// output must start with one of the join's upstream node IDs.
+func (v *workflowValidator) ref(fromPath, pointerPath, node, from, pointer string) {
+	if strings.TrimSpace(from) == "" {
+		v.add("missing_reference", fromPath, node, "source node is required")
+		return
+	}
+	if !v.ids[from] {
+		v.add("unknown_reference", fromPath, node, "unknown node "+quoteID(from))
+		return
+	}
+	if !v.pointer(pointerPath, node, pointer) || pointer == "" || !v.joins[from] {
+		return
+	}
+	member, _, _ := strings.Cut(strings.TrimPrefix(pointer, "/"), "/")
+	member = strings.ReplaceAll(strings.ReplaceAll(member, "~1", "/"), "~0", "~")
+	for _, dep := range v.deps[from] {
+		if dep == member {
+			return
+		}
+	}
+	v.add("unknown_join_member", pointerPath, node, fmt.Sprintf("join.all node %s has no upstream node %s", quoteID(from), quoteID(member)))
+}
+
+// pointer reports whether p is an RFC 6901 JSON pointer.
+func (v *workflowValidator) pointer(path, node, p string) bool {
+	if validJSONPointer(p) {
+		return true
+	}
+	v.add("invalid_pointer", path, node, fmt.Sprintf("invalid JSON pointer %q", p))
+	return false
+}
+
+func validJSONPointer(p string) bool {
+	if p == "" {
+		return true
+	}
+	if !strings.HasPrefix(p, "/") {
+		return false
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] == '~' && (i+1 == len(p) || (p[i+1] != '0' && p[i+1] != '1')) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *workflowValidator) input(path, node, name string) {
+	if v.declared != nil && !v.declared[name] {
+		v.add("undeclared_input", path, node, "input "+quoteID(name)+" is not declared")
+	}
+}
+
+func (v *workflowValidator) condition(path, node string, sourceValid, opValid bool, source, op, condPath string, value json.RawMessage) {
+	if !sourceValid {
+		v.add("invalid_condition", path+".source", node, fmt.Sprintf("unsupported condition source %q", source))
+	}
+	if !opValid {
+		v.add("invalid_condition", path+".op", node, fmt.Sprintf("unsupported condition op %q", op))
+	}
+	if !JSONPath(condPath).Valid() {
+		v.add("invalid_condition", path+".path", node, fmt.Sprintf("condition path %q must start with $", condPath))
+	}
+	switch op {
+	case string(ConditionOpEquals):
+		if len(value) == 0 {
+			v.add("invalid_condition", path+".value", node, "equals requires a value")
+		}
+	case string(ConditionOpMatches):
+		var pattern string
+		if err := json.Unmarshal(value, &pattern); err != nil {
+			v.add("invalid_condition", path+".value", node, "matches requires a string pattern")
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			v.add("invalid_condition", path+".value", node, "invalid pattern: "+err.Error())
+		}
+	}
+}
+
+func (v *workflowValidator) placeholders(path, node, text string) {
+	if v.declared == nil {
+		return
+	}
+	for _, m := range workflowPlaceholderRe.FindAllStringSubmatch(text, -1) {
+		if !v.declared[m[1]] && !v.ids[m[1]] {
+			v.add("unknown_placeholder", path, node, "placeholder {{"+m[1]+"}} is neither a declared input nor a node")
+		}
+	}
+}
+
+func (v *workflowValidator) intentNode(path string, n workflowintent.Node) {
+	if !v.ids[n.ID] {
+		return
+	}
+	switch n.Type {
+	case workflowintent.NodeTypeLLM:
+		v.placeholders(path+".system", n.ID, n.System)
+		v.placeholders(path+".user", n.ID, n.User)
+		if n.ToolExecution != nil && !n.ToolExecution.Mode.Valid() {
+			v.add("invalid_tool_execution", path+".tool_execution.mode", n.ID, fmt.Sprintf("unsupported mode %q", n.ToolExecution.Mode))
+		}
+	case workflowintent.NodeTypeJoinAll, workflowintent.NodeTypeJoinAny, workflowintent.NodeTypeJoinCollect:
+		if len(n.DependsOn) == 0 {
+			v.add("join_without_inputs", path+".depends_on", n.ID, "join nodes need at least one upstream node")
+		}
+		if n.Predicate != nil {
+			p := n.Predicate
+			v.condition(path+".predicate", n.ID, p.Source.Valid(), p.Op.Valid(), string(p.Source), string(p.Op), p.Path, p.Value)
+		}
+		if n.Limit != nil && *n.Limit <= 0 {
+			v.add("invalid_limit", path+".limit", n.ID, "limit must be positive")
+		}
+	case workflowintent.NodeTypeTransformJSON:
+		if len(n.Object) == 0 && len(n.Merge) == 0 {
+			v.add("missing_transform", path, n.ID, "transform.json needs object or merge")
+		}
+		for key, val := range n.Object {
+			p := path + ".object." + key
+			v.ref(p+".from", p+".pointer", n.ID, val.From, val.Pointer)
+		}
+		for i, val := range n.Merge {
+			p := fmt.Sprintf("%s.merge[%d]", path, i)
+			v.ref(p+".from", p+".pointer", n.ID, val.From, val.Pointer)
+		}
+	case workflowintent.NodeTypeMapFanout:
+		switch {
+		case (n.ItemsFrom == "") == (n.ItemsFromInput == ""):
+			v.add("invalid_fanout_items", path, n.ID, "exactly one of items_from or items_from_input is required")
+		case n.ItemsFrom != "":
+			v.ref(path+".items_from", path+".items_pointer", n.ID, n.ItemsFrom, n.ItemsPointer)
+		default:
+			v.input(path+".items_from_input", n.ID, n.ItemsFromInput)
+			v.pointer(path+".items_pointer", n.ID, n.ItemsPointer)
+		}
+		v.pointer(path+".items_path", n.ID, n.ItemsPath)
+		v.subnode(path+".subnode", n.ID, n.SubNode != nil, subnodeID(n.SubNode), n.SubNode != nil && n.SubNode.Type.Valid(), subnodeType(n.SubNode))
+	}
+}
+
+func subnodeID(n *workflowintent.Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.ID
+}
+
+func subnodeType(n *workflowintent.Node) string {
+	if n == nil {
+		return ""
+	}
+	return string(n.Type)
+}
+
+func (v *workflowValidator) subnode(path, node string, present bool, id string, typeValid bool, typ string) {
+	switch {
+	case !present:
+		v.add("missing_subnode", path, node, "map.fanout requires a subnode")
+		return
+	case strings.TrimSpace(id) == "":
+		v.add("missing_node_id", path+".id", node, "subnode id is required")
+	case v.ids[id]:
+		v.add("duplicate_node_id", path+".id", node, "subnode id "+quoteID(id)+" collides with a workflow node")
+	}
+	if !typeValid {
+		v.add("invalid_node_type", path+".type", node, fmt.Sprintf("unsupported subnode type %q", typ))
+	}
+}
+
+type workflowV1RefInput struct {
+	From    NodeID      `json:"from"`
+	Pointer JSONPointer `json:"pointer,omitempty"`
+}
+
+type workflowV1NodeInput struct {
+	Bindings  []workflow.LLMResponsesBindingV1 `json:"bindings,omitempty"`
+	Predicate *workflow.ConditionV1            `json:"predicate,omitempty"`
+	Limit     *int64                           `json:"limit,omitempty"`
+	Object    map[string]workflowV1RefInput    `json:"object,omitempty"`
+	Merge     []workflowV1RefInput             `json:"merge,omitempty"`
+}
+
+func (v *workflowValidator) v1Node(path string, n workflow.NodeV1) {
+	id := string(n.ID)
+	if !v.ids[id] {
+		return
+	}
+	if n.Type == workflow.NodeTypeV1JoinAll || n.Type == workflow.NodeTypeV1JoinAny || n.Type == workflow.NodeTypeV1JoinCollect {
+		if len(v.deps[id]) == 0 {
+			v.add("join_without_inputs", path, id, "join nodes need at least one incoming edge")
+		}
+	}
+	if len(n.Input) == 0 {
+		if n.Type == workflow.NodeTypeV1LLMResponses || n.Type == workflow.NodeTypeV1RouteSwitch || n.Type == workflow.NodeTypeV1MapFanout || n.Type == workflow.NodeTypeV1TransformJSON {
+			v.add("missing_node_input", path+".input", id, "input is required")
+		}
+		return
+	}
+	if n.Type == workflow.NodeTypeV1MapFanout {
+		v.v1Fanout(path+".input", id, n.Input)
+		return
+	}
+	var in workflowV1NodeInput
+	if err := json.Unmarshal(n.Input, &in); err != nil {
+		v.add("invalid_node_input", path+".input", id, err.Error())
+		return
+	}
+	for i, b := range in.Bindings {
+		p := fmt.Sprintf("%s.input.bindings[%d]", path, i)
+		switch {
+		case (b.From == "") == (b.FromInput == ""):
+			v.add("invalid_binding", p, id, "exactly one of from or from_input is required")
+		case b.From != "":
+			v.ref(p+".from", p+".pointer", id, string(b.From), string(b.Pointer))
+		default:
+			v.input(p+".from_input", id, string(b.FromInput))
+			v.pointer(p+".pointer", id, string(b.Pointer))
+		}
+		v.pointer(p+".to", id, string(b.To))
+		if b.To != "" && b.ToPlaceholder != "" {
+			v.add("invalid_binding", p, id, "to and to_placeholder are mutually exclusive")
+		}
+		if !b.Encoding.Valid() {
+			v.add("invalid_binding", p+".encoding", id, fmt.Sprintf("unsupported encoding %q", b.Encoding))
+		}
+	}
+	if p := in.Predicate; p != nil {
+		v.condition(path+".input.predicate", id, p.Source.Valid(), p.Op.Valid(), string(p.Source), string(p.Op), string(p.Path), p.Value)
+	}
+	if in.Limit != nil && *in.Limit <= 0 {
+		v.add("invalid_limit", path+".input.limit", id, "limit must be positive")
+	}
+	for key, val := range in.Object {
+		p := path + ".input.object." + key
+		v.ref(p+".from", p+".pointer", id, string(val.From), string(val.Pointer))
+	}
+	for i, val := range in.Merge {
+		p := fmt.Sprintf("%s.input.merge[%d]", path, i)
+		v.ref(p+".from", p+".pointer", id, string(val.From), string(val.Pointer))
+	}
+}
+
+func (v *workflowValidator) v1Fanout(path, id string, raw json.RawMessage) {
+	var in workflow.MapFanoutNodeInputV1
+	if err := json.Unmarshal(raw, &in); err != nil {
+		v.add("invalid_node_input", path, id, err.Error())
+		return
+	}
+	items := in.Items
+	switch {
+	case (items.From == "") == (items.FromInput == ""):
+		v.add("invalid_fanout_items", path+".items", id, "exactly one of from or from_input is required")
+	case items.From != "":
+		v.ref(path+".items.from", path+".items.pointer", id, string(items.From), string(items.Pointer))
+	default:
+		v.input(path+".items.from_input", id, string(items.FromInput))
+		v.pointer(path+".items.pointer", id, string(items.Pointer))
+	}
+	v.pointer(path+".items.path", id, string(items.Path))
+	for i, b := range in.ItemBindings {
+		p := fmt.Sprintf("%s.item_bindings[%d]", path, i)
+		v.pointer(p+".path", id, string(b.Path))
+		v.pointer(p+".to", id, string(b.To))
+		if (b.To == "") == (b.ToPlaceholder == "") {
+			v.add("invalid_binding", p, id, "exactly one of to or to_placeholder is required")
+		}
+		if !b.Encoding.Valid() {
+			v.add("invalid_binding", p+".encoding", id, fmt.Sprintf("unsupported encoding %q", b.Encoding))
+		}
+	}
+	sub := in.SubNode
+	v.subnode(path+".subnode", id, sub.ID != "" || sub.Type != "", string(sub.ID), sub.Type.Valid(), string(sub.Type))
+}
+
+func (v *workflowValidator) output(path, name, from, pointer string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		v.add("missing_output_name", path+".name", "", "output name is required")
+	case v.outputs[name]:
+		v.add("duplicate_output", path+".name", "", "duplicate output "+quoteID(name))
+	}
+	v.outputs[name] = true
+	if strings.TrimSpace(from) != "" && !v.ids[from] {
+		v.add("unknown_output_source", path+".from", "", "unknown node "+quoteID(from))
+		return
+	}
+	v.ref(path+".from", path+".pointer", "", from, pointer)
+	v.sources = append(v.sources, workflowOutputSource{path: path + ".from", from: from})
+}
+
+// graph reports dependency cycles and outputs whose source can never run
+// because it is on or downstream of a cycle.
+func (v *workflowValidator) graph() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(v.order))
+	var stack []string
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range v.deps[id] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := append([]string{dep}, reversed(stack[start+1:])...)
+				cycle = append(cycle, dep)
+				v.add("cycle", "$.nodes", dep, "dependency cycle "+strings.Join(cycle, " -> "))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+	}
+	for _, id := range v.order {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	// Nodes never released by a topological sweep sit on or behind a cycle.
+	released := make(map[string]bool, len(v.order))
+	for progress := true; progress; {
+		progress = false
+		for _, id := range v.order {
+			if released[id] {
+				continue
+			}
+			ready := true
+			for _, dep := range v.deps[id] {
+				ready = ready && released[dep]
+			}
+			if ready {
+				released[id] = true
+				progress = true
+			}
+		}
+	}
+	for _, src := range v.sources {
+		if v.ids[src.from] && !released[src.from] {
+			v.add("unreachable_output", src.path, src.from, "output can never be produced because the node depends on a cycle")
+		}
+	}
+}
+
+func reversed(ids []string) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[len(ids)-1-i] = id
+	}
+	return out
+}
+
+func quoteID(id string) string {
+	return fmt.Sprintf("%q", id)
+}
@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+func validationIssues(t *testing.T, err error) map[string]WorkflowIssue {
+	t.Helper()
+	var verr WorkflowValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected WorkflowValidationError, got %v", err)
+	}
+	out := make(map[string]WorkflowIssue, len(verr.Issues))
+	for _, issue := range verr.Issues {
+		out[issue.Code] = issue
+	}
+	return out
+}
+
+func TestValidateWorkflowAcceptsValidSpec(t *testing.T) {
+	spec := WorkflowSpec{
+		Kind:   workflowintent.KindWorkflow,
+		Model:  "demo",
+		Inputs: []workflowintent.InputDecl{{Name: "topic", Type: "string"}},
+		Nodes: []workflowintent.Node{
+			{ID: "cost", Type: workflowintent.NodeTypeLLM, User: "cost of {{topic}}"},
+			{ID: "risk", Type: workflowintent.NodeTypeLLM, User: "risk of {{topic}}"},
+			{ID: "join", Type: workflowintent.NodeTypeJoinAll, DependsOn: []string{"cost", "risk"}},
+			{ID: "summary", Type: workflowintent.NodeTypeTransformJSON, DependsOn: []string{"join"}, Object: map[string]workflowintent.TransformValue{
+				"cost": {From: "join", Pointer: JoinOutput("cost").Text().String()},
+			}},
+		},
+		Outputs: []workflowintent.OutputRef{{Name: "summary", From: "summary"}},
+	}
+	if err := ValidateWorkflow(spec); err != nil {
+		t.Fatalf("expected valid spec, got %v", err)
+	}
+}
+
+func TestValidateWorkflowReportsIssues(t *testing.T) {
+	spec := WorkflowSpec{
+		Kind:   workflowintent.KindWorkflow,
+		Inputs: []workflowintent.InputDecl{{Name: "topic", Type: "number", Default: json.RawMessage(`"x"`)}},
+		Nodes: []workflowintent.Node{
+			{ID: "a", Type: workflowintent.NodeTypeLLM, User: "{{topc}}", DependsOn: []string{"b"}},
+			{ID: "b", Type: workflowintent.NodeTypeLLM, User: "hi", DependsOn: []string{"a", "missing"}},
+			{ID: "join", Type: workflowintent.NodeTypeJoinAny, DependsOn: []string{"a"}, Predicate: &workflowintent.Condition{
+				Source: workflowintent.ConditionSourceNodeOutput, Op: workflowintent.ConditionOpMatches, Path: "status", Value: json.RawMessage(`"("`),
+			}},
+			{ID: "fan", Type: workflowintent.NodeTypeMapFanout, ItemsFromInput: "items"},
+			{ID: "pick", Type: workflowintent.NodeTypeJoinAll, DependsOn: []string{"join"}},
+		},
+		Outputs: []workflowintent.OutputRef{
+			{Name: "out", From: "a", Pointer: "output"},
+			{Name: "member", From: "pick", Pointer: JoinOutput("cost").Text().String()},
+			{Name: "gone", From: "nowhere"},
+		},
+	}
+	issues := validationIssues(t, ValidateWorkflow(spec))
+	for code, nodeID := range map[string]string{
+		"invalid_input_default": "",
+		"unknown_placeholder":   "a",
+		"unknown_dependency":    "b",
+		"cycle":                 "",
+		"invalid_condition":     "join",
+		"undeclared_input":      "fan",
+		"missing_subnode":       "fan",
+		"invalid_pointer":       "",
+		"unknown_join_member":   "",
+		"unknown_output_source": "",
+		"unreachable_output":    "",
+	} {
+		issue, ok := issues[code]
+		if !ok {
+			t.Errorf("missing %s issue; got %+v", code, issues)
+			continue
+		}
+		if nodeID != "" && issue.NodeID.String() != nodeID {
+			t.Errorf("%s: expected node %q, got %q", code, nodeID, issue.NodeID)
+		}
+	}
+}
+
+func TestValidateWorkflowV1(t *testing.T) {
+	route, _ := json.Marshal(map[string]any{"request": map[string]any{}})
+	bindings, _ := json.Marshal(map[string]any{"request": map[string]any{}, "bindings": []map[string]any{
+		{"from": "route", "pointer": "output/0", "to": "/input/0/content/0/text"},
+	}})
+	spec := workflow.SpecV1{
+		Kind: workflow.KindV1,
+		Nodes: []workflow.NodeV1{
+			{ID: "route", Type: workflow.NodeTypeV1RouteSwitch, Input: route},
+			{ID: "billing", Type: workflow.NodeTypeV1LLMResponses, Input: bindings},
+			{ID: "other", Type: workflow.NodeTypeV1LLMResponses, Input: route},
+		},
+		Edges: []workflow.EdgeV1{
+			{From: "route", To: "billing", When: &workflow.ConditionV1{
+				Source: workflow.ConditionSourceNodeOutput, Op: workflow.ConditionOpEquals, Path: "$.route", Value: json.RawMessage(`"billing"`),
+			}},
+			{From: "billing", To: "other", When: &workflow.ConditionV1{
+				Source: workflow.ConditionSourceNodeOutput, Op: workflow.ConditionOpExists, Path: "$.route",
+			}},
+			{From: "other", To: "ghost"},
+		},
+		Outputs: []workflow.OutputRefV1{{Name: "answer", From: "billing"}},
+	}
+	issues := validationIssues(t, ValidateWorkflowV1(spec))
+	if issue := issues["condition_requires_route_switch"]; issue.NodeID != "billing" || issue.Path != "$.edges[1].when" {
+		t.Fatalf("unexpected route.switch issue: %+v", issue)
+	}
+	if issue := issues["invalid_pointer"]; issue.NodeID != "billing" {
+		t.Fatalf("unexpected pointer issue: %+v", issue)
+	}
+	if _, ok := issues["unknown_edge_target"]; !ok || len(issues) != 3 {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+
+	fanout, _ := json.Marshal(map[string]any{"items": map[string]any{"from_input": "items"}})
+	spec = workflow.SpecV1{
+		Kind:    workflow.KindV1,
+		Inputs:  []workflow.InputDeclV1{{Name: "items"}},
+		Nodes:   []workflow.NodeV1{{ID: "fan", Type: workflow.NodeTypeV1MapFanout, Input: fanout}},
+		Outputs: []workflow.OutputRefV1{{Name: "all", From: "fan"}},
+	}
+	if issue, ok := validationIssues(t, ValidateWorkflowV1(spec))["missing_subnode"]; !ok || issue.Path != "$.nodes[0].input.subnode" {
+		t.Fatalf("expected missing_subnode issue, got %+v", issue)
+	}
+}
+
+func TestWorkflowsLint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workflows/lint" || r.URL.Query().Get("compile") != "false" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"workflow","lint_issues":[{"code":"unused_input","path":"$.inputs[0]","message":"input topic is unused"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	client := newTestClient(t, srv, "mr_sk_test")
+
+	resp, err := client.Workflows.Lint(context.Background(), WorkflowSpec{Kind: workflowintent.KindWorkflow}, WithWorkflowsLintCompile(false))
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if resp.Kind == nil || *resp.Kind != "workflow" || resp.LintIssues == nil || len(*resp.LintIssues) != 1 || (*resp.LintIssues)[0].Code != "unused_input" {
+		t.Fatalf("unexpected lint response: %+v", resp)
+	}
+	var verr WorkflowValidationError
+	if err := CheckWorkflowsLint(resp); !errors.As(err, &verr) || len(verr.Issues) != 1 || verr.Issues[0].Path != "$.inputs[0]" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if err := CheckWorkflowsLint(&WorkflowsLintResponse{}); err != nil {
+		t.Fatalf("expected clean lint response, got %v", err)
+	}
+}
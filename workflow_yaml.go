@@ -0,0 +1,484 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+)
+
+// WorkflowYAMLError reports a problem in a YAML workflow document. Line and
+// Column are 1-based and zero when the position is unknown; File is set when
+// the problem is inside an included file.
+type WorkflowYAMLError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e WorkflowYAMLError) Error() string {
+	var loc []string
+	if e.File != "" {
+		loc = append(loc, e.File)
+	}
+	if e.Line > 0 {
+		loc = append(loc, "line "+strconv.Itoa(e.Line))
+	}
+	if e.Column > 0 {
+		loc = append(loc, "column "+strconv.Itoa(e.Column))
+	}
+	if len(loc) == 0 {
+		return "workflow yaml: " + e.Err.Error()
+	}
+	return "workflow yaml: " + strings.Join(loc, ", ") + ": " + e.Err.Error()
+}
+
+func (e WorkflowYAMLError) Unwrap() error { return e.Err }
+
+type workflowYAMLOptions struct {
+	includes fs.FS
+}
+
+// WorkflowYAMLOption configures YAML workflow parsing.
+type WorkflowYAMLOption func(*workflowYAMLOptions)
+
+// WithWorkflowYAMLIncludes resolves $ref includes against fsys. Without it,
+// documents containing $ref are rejected.
+func WithWorkflowYAMLIncludes(fsys fs.FS) WorkflowYAMLOption {
+	return func(o *workflowYAMLOptions) { o.includes = fsys }
+}
+
+// ParseWorkflowYAML decodes an intent spec from YAML.
+//
+// The document uses the same field names as the JSON form, so multi-line
+// prompts can be written as block scalars. A mapping whose only key is $ref
+// is replaced by the named file: .yaml, .yml and .json files are inlined as
+// data and any other file as a string, which keeps long prompts in their own
+// files:
+//
+//	kind: workflow
+//	model: claude-sonnet-4-5
+//	nodes:
+//	  - id: draft
+//	    type: llm
+//	    system: {$ref: prompts/draft.md}
+//	    user: |
+//	      Write about {{topic}}.
+//	outputs:
+//	  - {name: draft, from: draft}
+//
+// Unknown fields are rejected. Errors are WorkflowYAMLError values carrying
+// the line and column of the offending value.
+func ParseWorkflowYAML(data []byte, opts ...WorkflowYAMLOption) (WorkflowSpec, error) {
+	var spec WorkflowSpec
+	err := decodeWorkflowYAML(data, buildWorkflowYAMLOptions(opts), &spec)
+	return spec, err
+}
+
+// ParseWorkflowV1YAML decodes a workflow.v1 spec from YAML. Node inputs are
+// written as YAML and stored as the equivalent JSON.
+func ParseWorkflowV1YAML(data []byte, opts ...WorkflowYAMLOption) (workflow.SpecV1, error) {
+	var spec workflow.SpecV1
+	err := decodeWorkflowYAML(data, buildWorkflowYAMLOptions(opts), &spec)
+	return spec, err
+}
+
+// LoadWorkflowYAML reads an intent spec from a YAML file, resolving $ref
+// includes relative to the file's directory.
+func LoadWorkflowYAML(file string) (WorkflowSpec, error) {
+	var spec WorkflowSpec
+	err := loadWorkflowYAML(file, &spec)
+	return spec, err
+}
+
+// LoadWorkflowV1YAML is LoadWorkflowYAML for workflow.v1 specs.
+func LoadWorkflowV1YAML(file string) (workflow.SpecV1, error) {
+	var spec workflow.SpecV1
+	err := loadWorkflowYAML(file, &spec)
+	return spec, err
+}
+
+// MarshalWorkflowYAML encodes an intent spec as YAML. Multi-line strings are
+// written as literal block scalars. ParseWorkflowYAML restores an equal spec.
+func MarshalWorkflowYAML(spec WorkflowSpec) ([]byte, error) {
+	return marshalWorkflowYAML(spec)
+}
+
+// MarshalWorkflowV1YAML encodes a workflow.v1 spec as YAML, including node
+// inputs. ParseWorkflowV1YAML restores an equal spec.
+func MarshalWorkflowV1YAML(spec workflow.SpecV1) ([]byte, error) {
+	return marshalWorkflowYAML(spec)
+}
+
+func buildWorkflowYAMLOptions(opts []WorkflowYAMLOption) workflowYAMLOptions {
+	var out workflowYAMLOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&out)
+		}
+	}
+	return out
+}
+
+func loadWorkflowYAML(file string, out any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	opts := workflowYAMLOptions{includes: os.DirFS(filepath.Dir(file))}
+	return decodeWorkflowYAML(data, opts, out)
+}
+
+// workflowYAMLKind returns the top-level kind of a YAML workflow document.
+func workflowYAMLKind(data []byte) (string, error) {
+	var doc struct {
+		Kind string `yaml:"kind"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", workflowYAMLSyntaxError("", err)
+	}
+	return doc.Kind, nil
+}
+
+func decodeWorkflowYAML(data []byte, opts workflowYAMLOptions, out any) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return workflowYAMLSyntaxError("", err)
+	}
+	if root.Kind == 0 {
+		return WorkflowYAMLError{Err: errors.New("empty document")}
+	}
+	c := &yamlToJSON{fsys: opts.includes, dir: "."}
+	if err := c.value(&root); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(c.buf.Bytes()))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return c.locate(err, &root)
+	}
+	return nil
+}
+
+var yamlErrorLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func workflowYAMLSyntaxError(file string, err error) error {
+	if m := yamlErrorLineRe.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return WorkflowYAMLError{File: file, Line: line, Err: errors.New(m[2])}
+	}
+	return WorkflowYAMLError{File: file, Err: err}
+}
+
+// workflowYAMLMaxJSONBytes caps the JSON produced from one document, so
+// alias fan-out cannot exhaust memory.
+const workflowYAMLMaxJSONBytes = 8 << 20
+
+// yamlToJSON converts a YAML node tree to JSON, resolving $ref includes and
+// remembering where each JSON value came from. expanding holds the anchors
+// of aliases currently being expanded.
+type yamlToJSON struct {
+	fsys      fs.FS
+	file      string
+	dir       string
+	stack     []string
+	expanding map[*yaml.Node]bool
+	buf       bytes.Buffer
+	spans     []yamlSpan
+}
+
+type yamlSpan struct {
+	start, end   int
+	file         string
+	line, column int
+}
+
+var jsonNumberRe = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func (c *yamlToJSON) errorf(n *yaml.Node, format string, args ...any) error {
+	return WorkflowYAMLError{File: c.file, Line: n.Line, Column: n.Column, Err: fmt.Errorf(format, args...)}
+}
+
+func (c *yamlToJSON) value(n *yaml.Node) error {
+	start := c.buf.Len()
+	if start > workflowYAMLMaxJSONBytes {
+		return c.errorf(n, "document expands to more than %d bytes", workflowYAMLMaxJSONBytes)
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			c.buf.WriteString("null")
+			return nil
+		}
+		return c.value(n.Content[0])
+	case yaml.AliasNode:
+		if c.expanding[n.Alias] {
+			return c.errorf(n, "alias *%s refers to itself", n.Value)
+		}
+		if c.expanding == nil {
+			c.expanding = make(map[*yaml.Node]bool)
+		}
+		c.expanding[n.Alias] = true
+		defer delete(c.expanding, n.Alias)
+		return c.value(n.Alias)
+	case yaml.MappingNode:
+		if ref, ok := yamlRef(n); ok {
+			if err := c.include(n, ref); err != nil {
+				return err
+			}
+			break
+		}
+		c.buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if key.Kind != yaml.ScalarNode || key.Tag == "!!merge" {
+				return c.errorf(key, "mapping keys must be strings")
+			}
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			raw, _ := json.Marshal(key.Value)
+			c.buf.Write(raw)
+			c.buf.WriteByte(':')
+			if err := c.value(n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte('}')
+	case yaml.SequenceNode:
+		c.buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			if err := c.value(item); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte(']')
+	case yaml.ScalarNode:
+		if err := c.scalar(n); err != nil {
+			return err
+		}
+	default:
+		return c.errorf(n, "unsupported YAML node")
+	}
+	c.spans = append(c.spans, yamlSpan{start: start, end: c.buf.Len(), file: c.file, line: n.Line, column: n.Column})
+	return nil
+}
+
+func (c *yamlToJSON) scalar(n *yaml.Node) error {
+	switch n.ShortTag() {
+	case "!!null":
+		c.buf.WriteString("null")
+		return nil
+	case "!!bool", "!!int", "!!float":
+		// Keep numbers that are already valid JSON verbatim so that values
+		// such as 1.0 survive a round trip.
+		if n.ShortTag() != "!!bool" && jsonNumberRe.MatchString(n.Value) {
+			c.buf.WriteString(n.Value)
+			return nil
+		}
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return c.errorf(n, "%v", err)
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return c.errorf(n, "%q is not representable in JSON", n.Value)
+		}
+		c.buf.Write(raw)
+		return nil
+	default:
+		raw, _ := json.Marshal(n.Value)
+		c.buf.Write(raw)
+		return nil
+	}
+}
+
+// yamlRef reports whether n is a {$ref: file} include.
+func yamlRef(n *yaml.Node) (string, bool) {
+	if len(n.Content) != 2 || n.Content[0].Value != "$ref" || n.Content[1].Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return n.Content[1].Value, true
+}
+
+func (c *yamlToJSON) include(n *yaml.Node, ref string) error {
+	if c.fsys == nil {
+		return c.errorf(n, "$ref %q requires an include directory (use LoadWorkflowYAML or WithWorkflowYAMLIncludes)", ref)
+	}
+	if path.IsAbs(ref) {
+		return c.errorf(n, "$ref %q must be relative", ref)
+	}
+	name := path.Join(c.dir, ref)
+	if !fs.ValidPath(name) {
+		return c.errorf(n, "$ref %q escapes the include directory", ref)
+	}
+	for _, open := range c.stack {
+		if open == name {
+			return c.errorf(n, "$ref cycle through %q", name)
+		}
+	}
+	data, err := fs.ReadFile(c.fsys, name)
+	if err != nil {
+		return c.errorf(n, "$ref %q: %v", ref, err)
+	}
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json":
+	default:
+		raw, _ := json.Marshal(string(data))
+		c.buf.Write(raw)
+		return nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return workflowYAMLSyntaxError(name, err)
+	}
+	file, dir := c.file, c.dir
+	c.file, c.dir, c.stack = name, path.Dir(name), append(c.stack, name)
+	defer func() { c.file, c.dir, c.stack = file, dir, c.stack[:len(c.stack)-1] }()
+	return c.value(&doc)
+}
+
+// locate attaches a YAML position to a JSON decoding error.
+func (c *yamlToJSON) locate(err error, root *yaml.Node) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// Offset points just past the offending value; prefer the span that
+		// ends there, otherwise the last one started before it.
+		var best *yamlSpan
+		for i := range c.spans {
+			s := &c.spans[i]
+			if s.end == int(typeErr.Offset) && (best == nil || best.end != s.end || s.start > best.start) {
+				best = s
+			}
+		}
+		if best == nil {
+			for i := range c.spans {
+				s := &c.spans[i]
+				if s.start <= int(typeErr.Offset) && (best == nil || s.start > best.start) {
+					best = s
+				}
+			}
+		}
+		msg := fmt.Errorf("cannot use %s as %s", typeErr.Value, typeErr.Type)
+		if typeErr.Field != "" {
+			msg = fmt.Errorf("%s: cannot use %s as %s", typeErr.Field, typeErr.Value, typeErr.Type)
+		}
+		if best != nil {
+			return WorkflowYAMLError{File: best.file, Line: best.line, Column: best.column, Err: msg}
+		}
+		return WorkflowYAMLError{Err: msg}
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name, _ := strconv.Unquote(field)
+		if key := findYAMLKey(root, name); key != nil {
+			return WorkflowYAMLError{Line: key.Line, Column: key.Column, Err: fmt.Errorf("unknown field %q", name)}
+		}
+		return WorkflowYAMLError{Err: fmt.Errorf("unknown field %q", name)}
+	}
+	return WorkflowYAMLError{Err: err}
+}
+
+func findYAMLKey(n *yaml.Node, name string) *yaml.Node {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == name {
+				return n.Content[i]
+			}
+		}
+	}
+	for _, child := range n.Content {
+		if key := findYAMLKey(child, name); key != nil {
+			return key
+		}
+	}
+	return nil
+}
+
+func marshalWorkflowYAML(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	node, err := yamlNodeFromJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNodeFromJSON builds a YAML node from the next JSON value, keeping
+// object key order.
+func yamlNodeFromJSON(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := yamlNodeFromJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}
+		if strings.Contains(strings.TrimSuffix(t, "\n"), "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
@@ -0,0 +1,193 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/modelrelay/modelrelay/sdk/go/workflow"
+	"github.com/modelrelay/modelrelay/sdk/go/workflowintent"
+)
+
+func TestWorkflowYAMLRoundTrip(t *testing.T) {
+	parallelism := int64(2)
+	maxTokens := int64(0)
+	spec := WorkflowSpec{
+		Kind:           workflowintent.KindWorkflow,
+		Name:           "triage",
+		Model:          "claude-sonnet-4-5",
+		MaxParallelism: &parallelism,
+		Inputs:         []workflowintent.InputDecl{{Name: "ticket", Type: "string", Default: json.RawMessage(`{"id":1.0,"tags":["a"]}`)}},
+		Nodes: []workflowintent.Node{
+			{
+				ID:              "classify",
+				Type:            workflowintent.NodeTypeLLM,
+				System:          "You are a triage bot.\n\n  Indented rule.\nTrailing line\n",
+				User:            "true",
+				MaxOutputTokens: &maxTokens,
+			},
+			{ID: "reply", Type: workflowintent.NodeTypeLLM, User: "123", DependsOn: []string{"classify"}},
+		},
+		Outputs: []workflowintent.OutputRef{{Name: "reply", From: "reply", Pointer: "/output/0/content/0/text"}},
+	}
+	data, err := MarshalWorkflowYAML(spec)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), "system: |") {
+		t.Fatalf("expected a literal block for the system prompt:\n%s", data)
+	}
+	got, err := ParseWorkflowYAML(data)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, spec) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v\n%s", got, spec, data)
+	}
+
+	input, _ := json.Marshal(map[string]any{"request": map[string]any{"model": "demo", "temperature": 1.0}})
+	v1 := workflow.SpecV1{
+		Kind:  workflow.KindV1,
+		Nodes: []workflow.NodeV1{{ID: "a", Type: workflow.NodeTypeV1LLMResponses, Input: input}},
+		Edges: []workflow.EdgeV1{{From: "a", To: "a", When: &workflow.ConditionV1{
+			Source: workflow.ConditionSourceNodeOutput, Op: workflow.ConditionOpEquals, Path: "$.ok", Value: json.RawMessage(`true`),
+		}}},
+		Outputs: []workflow.OutputRefV1{{Name: "out", From: "a"}},
+	}
+	data, err = MarshalWorkflowV1YAML(v1)
+	if err != nil {
+		t.Fatalf("marshal v1: %v", err)
+	}
+	gotV1, err := ParseWorkflowV1YAML(data)
+	if err != nil {
+		t.Fatalf("parse v1: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(gotV1, v1) {
+		t.Fatalf("v1 round trip mismatch:\n got %+v\nwant %+v\n%s", gotV1, v1, data)
+	}
+}
+
+func TestParseWorkflowYAMLIncludesAndErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"prompts/system.md":  {Data: []byte("Be concise.\nCite sources.\n")},
+		"prompts/shared.yml": {Data: []byte("- {$ref: system.md}\n")},
+		"prompts/loop.yaml":  {Data: []byte("{$ref: loop.yaml}\n")},
+	}
+	doc := `kind: workflow
+model: demo
+nodes:
+  - id: draft
+    type: llm
+    system: {$ref: prompts/system.md}
+    user: |
+      Write about {{topic}}.
+      Keep it short.
+    depends_on: {$ref: prompts/shared.yml}
+outputs:
+  - {name: draft, from: draft}
+`
+	spec, err := ParseWorkflowYAML([]byte(doc), WithWorkflowYAMLIncludes(fsys))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	node := spec.Nodes[0]
+	if node.System != "Be concise.\nCite sources.\n" || node.User != "Write about {{topic}}.\nKeep it short.\n" {
+		t.Fatalf("unexpected prompts: %q %q", node.System, node.User)
+	}
+	if !reflect.DeepEqual(node.DependsOn, []string{"Be concise.\nCite sources.\n"}) {
+		t.Fatalf("nested include not resolved relative to its file: %q", node.DependsOn)
+	}
+
+	cases := []struct {
+		name       string
+		doc        string
+		line, col  int
+		file, want string
+	}{
+		{name: "type", doc: "kind: workflow\nmax_parallelism: lots\n", line: 2, col: 18, want: "cannot use string"},
+		{name: "unknown field", doc: "kind: workflow\nnodes:\n  - id: a\n    dependson: [b]\n", line: 4, col: 5, want: `unknown field "dependson"`},
+		{name: "syntax", doc: "kind: workflow\nnodes: [\n", line: 2, want: "did not find expected node content"},
+		{name: "missing include", doc: "kind: workflow\nname: {$ref: nope.md}\n", line: 2, col: 7, want: "nope.md"},
+		{name: "escape", doc: "kind: workflow\nname: {$ref: ../secret}\n", line: 2, col: 7, want: "escapes"},
+		{name: "cycle", doc: "kind: workflow\nname: {$ref: prompts/loop.yaml}\n", line: 1, col: 1, file: "prompts/loop.yaml", want: "cycle"},
+	}
+	for _, tc := range cases {
+		_, err := ParseWorkflowYAML([]byte(tc.doc), WithWorkflowYAMLIncludes(fsys))
+		var yerr WorkflowYAMLError
+		if !errors.As(err, &yerr) {
+			t.Fatalf("%s: expected WorkflowYAMLError, got %v", tc.name, err)
+		}
+		if yerr.Line != tc.line || yerr.Column != tc.col || yerr.File != tc.file || !strings.Contains(yerr.Error(), tc.want) {
+			t.Fatalf("%s: unexpected error %+v (%v)", tc.name, yerr, yerr)
+		}
+	}
+
+	if _, err := ParseWorkflowYAML([]byte("kind: workflow\nname: {$ref: a.md}\n")); err == nil || !strings.Contains(err.Error(), "include directory") {
+		t.Fatalf("expected include directory error, got %v", err)
+	}
+}
+
+func TestParseWorkflowYAMLAliases(t *testing.T) {
+	spec, err := ParseWorkflowYAML([]byte("kind: workflow\nname: &n triage\nmodel: *n\n"))
+	if err != nil || spec.Model != "triage" {
+		t.Fatalf("expected alias to expand, got %+v %v", spec, err)
+	}
+
+	var yerr WorkflowYAMLError
+	_, err = ParseWorkflowYAML([]byte("a: &a\n  b: *a\n"))
+	if !errors.As(err, &yerr) || yerr.Line != 2 || !strings.Contains(err.Error(), "refers to itself") {
+		t.Fatalf("expected alias cycle error, got %v", err)
+	}
+
+	laughs := "a: &a [\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\",\"lol\"]\n"
+	for i, prev := 0, "a"; i < 8; i++ {
+		next := string(rune('b' + i))
+		laughs += next + ": &" + next + " [*" + prev + ",*" + prev + ",*" + prev + ",*" + prev + ",*" + prev + ",*" + prev + ",*" + prev + ",*" + prev + ",*" + prev + "]\n"
+		prev = next
+	}
+	_, err = ParseWorkflowYAML([]byte(laughs))
+	if !errors.As(err, &yerr) || !strings.Contains(err.Error(), "expands to more than") {
+		t.Fatalf("expected expansion limit error, got %v", err)
+	}
+}
+
+func TestRunsCreateFromYAMLFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "system.md"), []byte("Be brief."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "spec.yaml")
+	doc := "kind: workflow\nmodel: demo\nnodes:\n  - id: a\n    type: llm\n    system: {$ref: system.md}\n    user: hi\noutputs:\n  - {name: a, from: a}\n"
+	if err := os.WriteFile(file, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got runsCreateRequestLite
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"run_id":"11111111-1111-1111-1111-111111111111","status":"running","plan_hash":"` + strings.Repeat("a", 64) + `"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClientWithKey(mustSecretKey(t, "mr_sk_test"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	if _, err := client.Runs.CreateFromYAMLFile(context.Background(), file); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(got.Spec.Nodes) != 1 || got.Spec.Nodes[0].System != "Be brief." {
+		t.Fatalf("unexpected spec sent: %+v", got.Spec)
+	}
+}
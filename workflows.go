@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/modelrelay/modelrelay/sdk/go/generated"
 	"github.com/modelrelay/modelrelay/sdk/go/routes"
 )
 
@@ -84,3 +87,102 @@ func (c *WorkflowsClient) Compile(ctx context.Context, spec WorkflowSpec, opts .
 	}
 	return &out, nil
 }
+
+// WorkflowsLintResponse is the result of linting a workflow spec server-side.
+// CompileIssues, CompileError, PlanHash and PlanJson are only set when the
+// spec was also compiled.
+type WorkflowsLintResponse = generated.WorkflowsLintResponse
+
+// CheckWorkflowsLint returns a WorkflowValidationError holding the lint and
+// compile issues of resp, or nil when the spec is clean. A non-validation
+// compile failure is reported as an issue with code "compile_error".
+func CheckWorkflowsLint(resp *WorkflowsLintResponse) error {
+	if resp == nil {
+		return nil
+	}
+	var issues []WorkflowIssue
+	for _, list := range []*[]generated.WorkflowIssue{resp.LintIssues, resp.CompileIssues} {
+		if list == nil {
+			continue
+		}
+		for _, issue := range *list {
+			issues = append(issues, WorkflowIssue{Code: issue.Code, Path: issue.Path, Message: issue.Message})
+		}
+	}
+	if resp.CompileError != nil && *resp.CompileError != "" {
+		issues = append(issues, WorkflowIssue{Code: "compile_error", Path: "$", Message: *resp.CompileError})
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	return WorkflowValidationError{Issues: issues}
+}
+
+type workflowsLintOptions struct {
+	compile *bool
+	timeout *time.Duration
+	retry   *RetryConfig
+}
+
+// WorkflowsLintOption configures WorkflowsClient.Lint.
+type WorkflowsLintOption func(*workflowsLintOptions)
+
+func buildWorkflowsLintOptions(opts []WorkflowsLintOption) workflowsLintOptions {
+	var out workflowsLintOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&out)
+		}
+	}
+	return out
+}
+
+// WithWorkflowsLintCompile controls whether the server also compiles the spec
+// after linting. The server compiles by default.
+func WithWorkflowsLintCompile(compile bool) WorkflowsLintOption {
+	return func(o *workflowsLintOptions) { o.compile = &compile }
+}
+
+// WithWorkflowsLintTimeout overrides the client request timeout for Lint.
+func WithWorkflowsLintTimeout(d time.Duration) WorkflowsLintOption {
+	return func(o *workflowsLintOptions) { o.timeout = &d }
+}
+
+// WithWorkflowsLintRetry overrides the client RetryConfig for Lint.
+func WithWorkflowsLintRetry(cfg RetryConfig) WorkflowsLintOption {
+	return func(o *workflowsLintOptions) { o.retry = &cfg }
+}
+
+// Lint runs the server-side linter (and, by default, the compiler) on a
+// workflow spec. Unlike Compile, issues are returned in the response rather
+// than as an error; use CheckWorkflowsLint to treat them as one.
+// ValidateWorkflow performs the structural subset of these checks offline.
+func (c *WorkflowsClient) Lint(ctx context.Context, spec WorkflowSpec, opts ...WorkflowsLintOption) (*WorkflowsLintResponse, error) {
+	options := buildWorkflowsLintOptions(opts)
+
+	path := routes.WorkflowsLint
+	if options.compile != nil {
+		path += "?" + url.Values{"compile": []string{strconv.FormatBool(*options.compile)}}.Encode()
+	}
+	req, err := c.client.newJSONRequest(ctx, http.MethodPost, path, spec)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, _, err := c.client.send(req, options.timeout, options.retry)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck // best-effort cleanup on return
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 400 {
+		return nil, decodeAPIError(resp, nil)
+	}
+
+	var out WorkflowsLintResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}